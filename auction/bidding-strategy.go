@@ -0,0 +1,225 @@
+package auction
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/network"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+)
+
+// An action the bidding strategy wants to take on a lot
+type BidActionType string
+
+const (
+	BidActionPlaceBid   BidActionType = "placeBid"
+	BidActionClaimBid   BidActionType = "claimBid"
+	BidActionRecoverRPL BidActionType = "recoverUnclaimedRpl"
+)
+
+// Gets an RPL price in ETH from outside of the network, such as an exchange, where 1e18 = 1 ETH
+type PriceSource func(ctx context.Context) (*big.Int, error)
+
+// The settings for a bidding strategy
+type BiddingStrategyConfig struct {
+	Bidder          common.Address `json:"bidder"`
+	MinimumDiscount *big.Int       `json:"minimumDiscount"` // The discount to the reference price required to bid, where 1e18 = 100%
+	MaxBidPerLot    *big.Int       `json:"maxBidPerLot"`    // The most ETH to bid on a single lot, including earlier bids
+	MaxBidPerRound  *big.Int       `json:"maxBidPerRound"`  // The most ETH to bid across all lots in one evaluation; nil for no limit
+	ClaimBids       bool           `json:"claimBids"`
+	RecoverRPL      bool           `json:"recoverRpl"`
+}
+
+// An action the bidding strategy wants to take, and why
+type BidAction struct {
+	Type           BidActionType `json:"type"`
+	LotIndex       uint64        `json:"lotIndex"`
+	Amount         *big.Int      `json:"amount"` // The ETH to bid; nil for other actions
+	LotPrice       *big.Int      `json:"lotPrice"`
+	ReferencePrice *big.Int      `json:"referencePrice"`
+	Discount       *big.Int      `json:"discount"` // Where 1e18 = 100%
+}
+
+// Places bids on lots when they reach a configured discount, and claims or recovers RPL once they clear
+type BiddingStrategy struct {
+	Config        BiddingStrategyConfig
+	ExternalPrice PriceSource
+}
+
+// Create a bidding strategy; the external price source is optional
+func NewBiddingStrategy(config BiddingStrategyConfig, externalPrice PriceSource) *BiddingStrategy {
+	return &BiddingStrategy{
+		Config:        config,
+		ExternalPrice: externalPrice,
+	}
+}
+
+// Get the actions the strategy wants to take at the current block
+func (s *BiddingStrategy) Plan(ctx context.Context, rp *rocketpool.RocketPool, opts *bind.CallOpts) ([]BidAction, error) {
+
+	// Data
+	var wg errgroup.Group
+	var lots []LotDetails
+	var oraclePrice *big.Int
+	var externalPrice *big.Int
+	var currentBlock uint64
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		lots, err = GetLotsWithBids(rp, s.Config.Bidder, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		oraclePrice, err = network.GetRPLPrice(rp, opts)
+		return err
+	})
+	if s.ExternalPrice != nil {
+		wg.Go(func() error {
+			var err error
+			externalPrice, err = s.ExternalPrice(ctx)
+			if err != nil {
+				return fmt.Errorf("Could not get external RPL price: %w", err)
+			}
+			return nil
+		})
+	}
+	wg.Go(func() error {
+		if opts != nil && opts.BlockNumber != nil {
+			currentBlock = opts.BlockNumber.Uint64()
+			return nil
+		}
+		var err error
+		currentBlock, err = rp.Client.BlockNumber(ctx)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return nil, fmt.Errorf("Could not get auction bidding details: %w", err)
+	}
+	return s.Evaluate(lots, oraclePrice, externalPrice, currentBlock), nil
+
+}
+
+// Get the actions the strategy wants to take for the provided lots.
+// The reference price is the lower of the oracle and external prices, so bids are only placed when the lot is discounted against both.
+func (s *BiddingStrategy) Evaluate(lots []LotDetails, oraclePrice *big.Int, externalPrice *big.Int, currentBlock uint64) []BidAction {
+
+	referencePrice := oraclePrice
+	if externalPrice != nil && externalPrice.Cmp(referencePrice) < 0 {
+		referencePrice = externalPrice
+	}
+	var budget *big.Int
+	if s.Config.MaxBidPerRound != nil {
+		budget = new(big.Int).Set(s.Config.MaxBidPerRound)
+	}
+
+	actions := []BidAction{}
+	for _, lot := range lots {
+		if !lot.Exists {
+			continue
+		}
+		addressBidAmount := lot.AddressBidAmount
+		if addressBidAmount == nil {
+			addressBidAmount = big.NewInt(0)
+		}
+
+		// Settle cleared lots
+		if lot.Cleared {
+			if s.Config.ClaimBids && addressBidAmount.Sign() > 0 {
+				actions = append(actions, BidAction{
+					Type:     BidActionClaimBid,
+					LotIndex: lot.Index,
+					LotPrice: lot.CurrentPrice,
+				})
+			}
+			if s.Config.RecoverRPL && !lot.RPLRecovered && lot.RemainingRPLAmount.Sign() > 0 {
+				actions = append(actions, BidAction{
+					Type:     BidActionRecoverRPL,
+					LotIndex: lot.Index,
+					LotPrice: lot.CurrentPrice,
+				})
+			}
+			continue
+		}
+
+		// Check the discount on open lots
+		discount := getDiscount(lot.CurrentPrice, referencePrice)
+		if discount == nil || discount.Cmp(s.Config.MinimumDiscount) < 0 {
+			continue
+		}
+
+		// Get the bid amount; the bid lands in the next block, and anything over the lot's capacity is refunded
+		amount := new(big.Int).Sub(s.Config.MaxBidPerLot, addressBidAmount)
+		if maxBid := NewLotPriceCurve(lot).GetMaxBidAmount(currentBlock + 1); maxBid.Cmp(amount) < 0 {
+			amount = maxBid
+		}
+		if budget != nil && budget.Cmp(amount) < 0 {
+			amount = new(big.Int).Set(budget)
+		}
+		if amount.Sign() <= 0 {
+			continue
+		}
+		if budget != nil {
+			budget.Sub(budget, amount)
+		}
+		actions = append(actions, BidAction{
+			Type:           BidActionPlaceBid,
+			LotIndex:       lot.Index,
+			Amount:         amount,
+			LotPrice:       lot.CurrentPrice,
+			ReferencePrice: referencePrice,
+			Discount:       discount,
+		})
+	}
+	return actions
+
+}
+
+// Run the provided actions, returning the hashes of the transactions that were submitted.
+// The gas limit is estimated for each transaction; if opts has a nonce, it's used for the first transaction and incremented for each one after it.
+func (s *BiddingStrategy) Execute(rp *rocketpool.RocketPool, actions []BidAction, opts *bind.TransactOpts) ([]common.Hash, error) {
+	hashes := make([]common.Hash, 0, len(actions))
+	for _, action := range actions {
+		actionOpts := *opts
+		actionOpts.GasLimit = 0
+		if opts.Nonce != nil {
+			actionOpts.Nonce = new(big.Int).Add(opts.Nonce, big.NewInt(int64(len(hashes))))
+		}
+		var hash common.Hash
+		var err error
+		switch action.Type {
+		case BidActionPlaceBid:
+			actionOpts.Value = action.Amount
+			hash, err = PlaceBid(rp, action.LotIndex, &actionOpts)
+		case BidActionClaimBid:
+			hash, err = ClaimBid(rp, action.LotIndex, &actionOpts)
+		case BidActionRecoverRPL:
+			hash, err = RecoverUnclaimedRPL(rp, action.LotIndex, &actionOpts)
+		default:
+			err = fmt.Errorf("Unknown bid action %s", action.Type)
+		}
+		if err != nil {
+			return hashes, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// Get the discount of a price against a reference price, where 1e18 = 100%
+func getDiscount(price *big.Int, referencePrice *big.Int) *big.Int {
+	if referencePrice == nil || referencePrice.Sign() == 0 || price == nil {
+		return nil
+	}
+	discount := new(big.Int).Sub(referencePrice, price)
+	discount.Mul(discount, priceCalcBase)
+	return discount.Div(discount, referencePrice)
+}
@@ -0,0 +1,301 @@
+package auction
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+)
+
+// The type of an auction lot event
+type LotEventType string
+
+const (
+	LotEventCreated      LotEventType = "LotCreated"
+	LotEventBidPlaced    LotEventType = "BidPlaced"
+	LotEventBidClaimed   LotEventType = "BidClaimed"
+	LotEventRPLRecovered LotEventType = "RPLRecovered"
+)
+
+// The auction manager events that make up a lot's history
+var lotEventTypes = []LotEventType{LotEventCreated, LotEventBidPlaced, LotEventBidClaimed, LotEventRPLRecovered}
+
+// An event emitted by the auction manager for a lot
+type LotEvent struct {
+	Type        LotEventType   `json:"type"`
+	LotIndex    uint64         `json:"lotIndex"`
+	Address     common.Address `json:"address"`
+	BidAmount   *big.Int       `json:"bidAmount"`
+	RPLAmount   *big.Int       `json:"rplAmount"`
+	Time        time.Time      `json:"time"`
+	BlockNumber uint64         `json:"blockNumber"`
+	TxHash      common.Hash    `json:"txHash"`
+}
+
+// The events for a lot, in the order they were emitted
+type LotHistory struct {
+	LotIndex uint64     `json:"lotIndex"`
+	Events   []LotEvent `json:"events"`
+}
+
+// A bidder's settlement for a lot
+type BidderSettlement struct {
+	Bidder         common.Address `json:"bidder"`
+	BidCount       int            `json:"bidCount"`
+	TotalBidAmount *big.Int       `json:"totalBidAmount"`
+	RPLAmount      *big.Int       `json:"rplAmount"` // The RPL claimed, or the RPL the bidder can claim if it hasn't yet
+	Claimed        bool           `json:"claimed"`
+	EffectivePrice *big.Int       `json:"effectivePrice"` // ETH paid per RPL received, where 1e18 = 1 ETH
+}
+
+// The settlement of a lot across all of its bidders
+type LotSettlementReport struct {
+	LotIndex       uint64             `json:"lotIndex"`
+	Cleared        bool               `json:"cleared"`
+	ClearingPrice  *big.Int           `json:"clearingPrice"`
+	TotalRPLAmount *big.Int           `json:"totalRplAmount"`
+	TotalBidAmount *big.Int           `json:"totalBidAmount"`
+	RPLSold        *big.Int           `json:"rplSold"`
+	RPLClaimed     *big.Int           `json:"rplClaimed"`
+	RPLRecovered   *big.Int           `json:"rplRecovered"`
+	Bidders        []BidderSettlement `json:"bidders"`
+}
+
+// Get the history of every lot with events since fromBlock, ordered by lot index
+func GetLotHistories(rp *rocketpool.RocketPool, fromBlock *big.Int, intervalSize *big.Int, opts *bind.CallOpts) ([]LotHistory, error) {
+	events, err := getLotEvents(rp, nil, fromBlock, intervalSize, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the events by lot
+	histories := map[uint64]*LotHistory{}
+	indices := []uint64{}
+	for _, event := range events {
+		history, exists := histories[event.LotIndex]
+		if !exists {
+			history = &LotHistory{
+				LotIndex: event.LotIndex,
+				Events:   []LotEvent{},
+			}
+			histories[event.LotIndex] = history
+			indices = append(indices, event.LotIndex)
+		}
+		history.Events = append(history.Events, event)
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return indices[i] < indices[j]
+	})
+
+	result := make([]LotHistory, len(indices))
+	for i, index := range indices {
+		result[i] = *histories[index]
+	}
+	return result, nil
+}
+
+// Get the history of a lot
+func GetLotHistory(rp *rocketpool.RocketPool, lotIndex uint64, fromBlock *big.Int, intervalSize *big.Int, opts *bind.CallOpts) (LotHistory, error) {
+	indexTopic := common.BigToHash(new(big.Int).SetUint64(lotIndex))
+	events, err := getLotEvents(rp, []common.Hash{indexTopic}, fromBlock, intervalSize, opts)
+	if err != nil {
+		return LotHistory{}, err
+	}
+	return LotHistory{
+		LotIndex: lotIndex,
+		Events:   events,
+	}, nil
+}
+
+// Get the settlement report for a lot
+func GetLotSettlementReport(rp *rocketpool.RocketPool, lotIndex uint64, fromBlock *big.Int, intervalSize *big.Int, opts *bind.CallOpts) (LotSettlementReport, error) {
+	lot, err := GetLotDetails(rp, lotIndex, opts)
+	if err != nil {
+		return LotSettlementReport{}, err
+	}
+	history, err := GetLotHistory(rp, lotIndex, fromBlock, intervalSize, opts)
+	if err != nil {
+		return LotSettlementReport{}, err
+	}
+	return BuildLotSettlementReport(lot, history), nil
+}
+
+// Build the settlement report for a lot from its details and history.
+// Bidders that haven't claimed yet are credited with the RPL claimBid() would give them at the lot's current price.
+func BuildLotSettlementReport(lot LotDetails, history LotHistory) LotSettlementReport {
+
+	report := LotSettlementReport{
+		LotIndex:       lot.Index,
+		Cleared:        lot.Cleared,
+		ClearingPrice:  lot.CurrentPrice,
+		TotalRPLAmount: lot.TotalRPLAmount,
+		TotalBidAmount: lot.TotalBidAmount,
+		RPLSold:        big.NewInt(0),
+		RPLClaimed:     big.NewInt(0),
+		RPLRecovered:   big.NewInt(0),
+		Bidders:        []BidderSettlement{},
+	}
+
+	// Tally the events by bidder
+	bidders := map[common.Address]*BidderSettlement{}
+	getBidder := func(address common.Address) *BidderSettlement {
+		settlement, exists := bidders[address]
+		if !exists {
+			settlement = &BidderSettlement{
+				Bidder:         address,
+				TotalBidAmount: big.NewInt(0),
+				RPLAmount:      big.NewInt(0),
+			}
+			bidders[address] = settlement
+			report.Bidders = append(report.Bidders, BidderSettlement{Bidder: address})
+		}
+		return settlement
+	}
+	for _, event := range history.Events {
+		switch event.Type {
+		case LotEventBidPlaced:
+			settlement := getBidder(event.Address)
+			settlement.BidCount++
+			settlement.TotalBidAmount.Add(settlement.TotalBidAmount, event.BidAmount)
+		case LotEventBidClaimed:
+			settlement := getBidder(event.Address)
+			settlement.Claimed = true
+			settlement.RPLAmount.Add(settlement.RPLAmount, event.RPLAmount)
+			report.RPLClaimed.Add(report.RPLClaimed, event.RPLAmount)
+		case LotEventRPLRecovered:
+			report.RPLRecovered.Add(report.RPLRecovered, event.RPLAmount)
+		}
+	}
+
+	// Get the RPL for unclaimed bids and each bidder's effective price
+	for i, bidder := range report.Bidders {
+		settlement := bidders[bidder.Bidder]
+		if !settlement.Claimed && lot.CurrentPrice != nil && lot.CurrentPrice.Sign() > 0 {
+			settlement.RPLAmount.Mul(priceCalcBase, settlement.TotalBidAmount)
+			settlement.RPLAmount.Div(settlement.RPLAmount, lot.CurrentPrice)
+		}
+		if settlement.RPLAmount.Sign() > 0 {
+			settlement.EffectivePrice = new(big.Int).Mul(settlement.TotalBidAmount, priceCalcBase)
+			settlement.EffectivePrice.Div(settlement.EffectivePrice, settlement.RPLAmount)
+		}
+		report.RPLSold.Add(report.RPLSold, settlement.RPLAmount)
+		report.Bidders[i] = *settlement
+	}
+	return report
+
+}
+
+// Get the auction manager events, optionally filtered by lot index topic
+func getLotEvents(rp *rocketpool.RocketPool, indexTopics []common.Hash, fromBlock *big.Int, intervalSize *big.Int, opts *bind.CallOpts) ([]LotEvent, error) {
+	rocketAuctionManager, err := getRocketAuctionManager(rp, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Construct a filter query for the lot events, including their IDs on every auction manager deployment
+	eventIds := make([]common.Hash, 0, len(lotEventTypes))
+	for _, eventType := range lotEventTypes {
+		abiEvent, exists := rocketAuctionManager.ABI.Events[string(eventType)]
+		if !exists {
+			return nil, fmt.Errorf("Event %s does not exist on the auction manager", eventType)
+		}
+		eventIds = append(eventIds, abiEvent.ID)
+	}
+	ranges, err := rp.UpgradeHistory.GetContractAddressRanges("poolseaAuctionManager", intervalSize, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not get auction manager deployments: %w", err)
+	}
+	for _, addressRange := range ranges {
+		deploymentAbi, err := rp.GetABIAtBlock("poolseaAuctionManager", addressRange.StartBlock)
+		if err != nil {
+			return nil, fmt.Errorf("Could not get auction manager ABI on block %d: %w", addressRange.StartBlock, err)
+		}
+		for _, eventType := range lotEventTypes {
+			if abiEvent, exists := deploymentAbi.Events[string(eventType)]; exists && !containsHash(eventIds, abiEvent.ID) {
+				eventIds = append(eventIds, abiEvent.ID)
+			}
+		}
+	}
+	topicFilter := [][]common.Hash{eventIds}
+	if indexTopics != nil {
+		topicFilter = append(topicFilter, indexTopics)
+	}
+
+	// Get the event logs from every auction manager deployment
+	logs, err := eth.FilterContractLogs(rp, "poolseaAuctionManager", eth.FilterQuery{
+		FromBlock: fromBlock,
+		Topics:    topicFilter,
+	}, intervalSize, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not get auction lot events: %w", err)
+	}
+
+	// Decode them
+	events := make([]LotEvent, 0, len(logs))
+	for _, log := range logs {
+		event, err := decodeLotEvent(rp, log)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Decode an auction manager event log with the ABI the auction manager had on the log's block
+func decodeLotEvent(rp *rocketpool.RocketPool, log types.Log) (LotEvent, error) {
+	if len(log.Topics) < 3 {
+		return LotEvent{}, fmt.Errorf("Auction event in transaction %s is missing topics", log.TxHash.Hex())
+	}
+	auctionManagerAbi, err := rp.GetABIAtBlock("poolseaAuctionManager", log.BlockNumber)
+	if err != nil {
+		return LotEvent{}, fmt.Errorf("Could not get auction manager ABI on block %d: %w", log.BlockNumber, err)
+	}
+	abiEvent, err := auctionManagerAbi.EventByID(log.Topics[0])
+	if err != nil {
+		return LotEvent{}, fmt.Errorf("Could not get auction event in transaction %s: %w", log.TxHash.Hex(), err)
+	}
+	values := make(map[string]interface{})
+	if err := abiEvent.Inputs.UnpackIntoMap(values, log.Data); err != nil {
+		return LotEvent{}, fmt.Errorf("Could not decode %s event in transaction %s: %w", abiEvent.Name, log.TxHash.Hex(), err)
+	}
+
+	event := LotEvent{
+		Type:        LotEventType(abiEvent.Name),
+		LotIndex:    log.Topics[1].Big().Uint64(),
+		Address:     common.BytesToAddress(log.Topics[2].Bytes()),
+		BidAmount:   getBigValue(values, "bidAmount"),
+		RPLAmount:   getBigValue(values, "rplAmount"),
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash,
+	}
+	if eventTime := getBigValue(values, "time"); eventTime != nil {
+		event.Time = time.Unix(eventTime.Int64(), 0)
+	}
+	return event, nil
+}
+
+// Check if a hash is in a list
+func containsHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, candidate := range hashes {
+		if candidate == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Get a numeric event value, or nil if the event doesn't have it
+func getBigValue(values map[string]interface{}, name string) *big.Int {
+	if value, ok := values[name].(*big.Int); ok {
+		return value
+	}
+	return nil
+}
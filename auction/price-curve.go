@@ -0,0 +1,169 @@
+package auction
+
+import (
+	"math/big"
+)
+
+// The fixed-point base used by the auction manager's price calculations
+var priceCalcBase = big.NewInt(1e18)
+
+// A lot's price at a block
+type LotPricePoint struct {
+	Block uint64   `json:"block"`
+	Price *big.Int `json:"price"`
+}
+
+// An offline model of a lot's price, replicating the auction manager's price calculations.
+// The price decays quadratically from the start price to the reserve price between the start and end blocks.
+type LotPriceCurve struct {
+	StartBlock     uint64   `json:"startBlock"`
+	EndBlock       uint64   `json:"endBlock"`
+	StartPrice     *big.Int `json:"startPrice"`
+	ReservePrice   *big.Int `json:"reservePrice"`
+	TotalRPLAmount *big.Int `json:"totalRplAmount"`
+	TotalBidAmount *big.Int `json:"totalBidAmount"`
+}
+
+// Create a price curve from a lot's details
+func NewLotPriceCurve(lot LotDetails) LotPriceCurve {
+	return LotPriceCurve{
+		StartBlock:     lot.StartBlock,
+		EndBlock:       lot.EndBlock,
+		StartPrice:     lot.StartPrice,
+		ReservePrice:   lot.ReservePrice,
+		TotalRPLAmount: lot.TotalRPLAmount,
+		TotalBidAmount: lot.TotalBidAmount,
+	}
+}
+
+// Get the lot price at a block; this matches getLotPriceAtBlock()
+func (c LotPriceCurve) GetPriceAtBlock(block uint64) *big.Int {
+	if block <= c.StartBlock {
+		return new(big.Int).Set(c.StartPrice)
+	}
+	if block >= c.EndBlock {
+		return new(big.Int).Set(c.ReservePrice)
+	}
+	tn := new(big.Int).SetUint64(block - c.StartBlock)
+	tN := new(big.Int).SetUint64(c.EndBlock - c.StartBlock)
+	pc := new(big.Int).Sub(c.StartPrice, c.ReservePrice)
+	x := tn.Mul(tn, priceCalcBase)
+	x.Div(x, tN)
+	y := new(big.Int).Mul(x, x)
+	y.Div(y, priceCalcBase)
+	decay := pc.Mul(pc, y)
+	decay.Div(decay, priceCalcBase)
+	return decay.Sub(c.StartPrice, decay)
+}
+
+// Get the lot price implied by its total bids; this matches getLotPriceByTotalBids()
+func (c LotPriceCurve) GetPriceByTotalBids() *big.Int {
+	if c.TotalRPLAmount.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	price := new(big.Int).Mul(priceCalcBase, c.TotalBidAmount)
+	return price.Div(price, c.TotalRPLAmount)
+}
+
+// Get the lot price at a block, accounting for its bids; this matches getLotCurrentPrice() at that block
+func (c LotPriceCurve) GetCurrentPrice(block uint64) *big.Int {
+	blockPrice := c.GetPriceAtBlock(block)
+	bidPrice := c.GetPriceByTotalBids()
+	if bidPrice.Cmp(blockPrice) > 0 {
+		return bidPrice
+	}
+	return blockPrice
+}
+
+// Check if the lot is cleared at a block; this matches getLotIsCleared() at that block
+func (c LotPriceCurve) GetIsCleared(block uint64) bool {
+	if block >= c.EndBlock {
+		return true
+	}
+	return c.GetCurrentPrice(block).Cmp(c.GetPriceByTotalBids()) <= 0
+}
+
+// Get the amount of RPL claimed by bids at a block; this matches getLotClaimedRPLAmount() at that block
+func (c LotPriceCurve) GetClaimedRPLAmount(block uint64) *big.Int {
+	claimed := c.GetRPLAmountForBid(c.TotalBidAmount, block)
+	if claimed.Cmp(c.TotalRPLAmount) > 0 {
+		return new(big.Int).Set(c.TotalRPLAmount)
+	}
+	return claimed
+}
+
+// Get the amount of RPL not yet claimed by bids at a block; this matches getLotRemainingRPLAmount() at that block
+func (c LotPriceCurve) GetRemainingRPLAmount(block uint64) *big.Int {
+	return new(big.Int).Sub(c.TotalRPLAmount, c.GetClaimedRPLAmount(block))
+}
+
+// Get the amount of RPL a bid receives if the lot settles at its price at a block
+func (c LotPriceCurve) GetRPLAmountForBid(bidAmount *big.Int, block uint64) *big.Int {
+	price := c.GetCurrentPrice(block)
+	if price.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	amount := new(big.Int).Mul(priceCalcBase, bidAmount)
+	return amount.Div(amount, price)
+}
+
+// Get the largest bid the lot accepts at a block; any ETH above this is refunded by placeBid()
+func (c LotPriceCurve) GetMaxBidAmount(block uint64) *big.Int {
+	blockPrice := c.GetPriceAtBlock(block)
+	if blockPrice.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	claimed := new(big.Int).Mul(priceCalcBase, c.TotalBidAmount)
+	claimed.Div(claimed, blockPrice)
+	remaining := new(big.Int).Sub(c.TotalRPLAmount, claimed)
+	if remaining.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	remaining.Mul(remaining, blockPrice)
+	return remaining.Div(remaining, priceCalcBase)
+}
+
+// Get the first block at which the lot price is at or below a target price.
+// Returns false if the price never reaches the target.
+func (c LotPriceCurve) GetBlockAtPrice(price *big.Int) (uint64, bool) {
+	if c.GetPriceAtBlock(c.EndBlock).Cmp(price) > 0 {
+		return 0, false
+	}
+	low, high := c.StartBlock, c.EndBlock
+	for low < high {
+		mid := low + (high-low)/2
+		if c.GetPriceAtBlock(mid).Cmp(price) <= 0 {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low, true
+}
+
+// Get the first block at which the lot clears with its current bids, and the price it clears at
+func (c LotPriceCurve) GetClearingBlock() (uint64, *big.Int) {
+	block, reached := c.GetBlockAtPrice(c.GetPriceByTotalBids())
+	if !reached {
+		return c.EndBlock, c.GetCurrentPrice(c.EndBlock)
+	}
+	return block, c.GetCurrentPrice(block)
+}
+
+// Get the lot price at regular block intervals from the start block to the end block, for charting
+func (c LotPriceCurve) GetCurve(interval uint64) []LotPricePoint {
+	if interval == 0 {
+		interval = 1
+	}
+	points := []LotPricePoint{}
+	for block := c.StartBlock; block < c.EndBlock; block += interval {
+		points = append(points, LotPricePoint{
+			Block: block,
+			Price: c.GetPriceAtBlock(block),
+		})
+	}
+	return append(points, LotPricePoint{
+		Block: c.EndBlock,
+		Price: c.GetPriceAtBlock(c.EndBlock),
+	})
+}
@@ -0,0 +1,144 @@
+package dao
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+)
+
+// The settings contract getters for each setting proposal method
+var settingProposalGetters = map[string]string{
+	"proposalSettingUint":    "getSettingUint",
+	"proposalSettingBool":    "getSettingBool",
+	"proposalSettingAddress": "getSettingAddress",
+}
+
+// A named, typed argument of a proposal payload
+type ProposalPayloadArgument struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// A proposal payload decoded against its DAO contract ABI
+type DecodedProposalPayload struct {
+	Method        string                    `json:"method"`
+	Arguments     []ProposalPayloadArgument `json:"arguments"`
+	ContractName  string                    `json:"contractName,omitempty"` // The contract changed by setting and upgrade proposals
+	SettingPath   string                    `json:"settingPath,omitempty"`
+	CurrentValue  *SettingValue             `json:"currentValue,omitempty"`
+	ProposedValue *SettingValue             `json:"proposedValue,omitempty"`
+}
+
+// Decode a proposal payload, including the current on-chain value of the setting it changes
+func GetDecodedProposalPayload(rp *rocketpool.RocketPool, daoName string, payload []byte, opts *bind.CallOpts) (DecodedProposalPayload, error) {
+
+	// Get proposal DAO contract ABI
+	daoContractAbi, err := rp.GetABI(daoName, opts)
+	if err != nil {
+		return DecodedProposalPayload{}, fmt.Errorf("Could not get '%s' DAO contract ABI: %w", daoName, err)
+	}
+
+	// Decode the payload
+	decoded, err := DecodeProposalPayload(daoContractAbi, payload)
+	if err != nil {
+		return DecodedProposalPayload{}, err
+	}
+
+	// Get the current setting value
+	getter, isSetting := settingProposalGetters[decoded.Method]
+	if !isSetting || decoded.ProposedValue == nil {
+		return decoded, nil
+	}
+	settingsContract, err := rp.GetContract(decoded.ContractName, opts)
+	if err != nil {
+		return DecodedProposalPayload{}, fmt.Errorf("Could not get settings contract %s: %w", decoded.ContractName, err)
+	}
+	currentValue, err := getSettingValue(settingsContract, getter, decoded.SettingPath, opts)
+	if err != nil {
+		return DecodedProposalPayload{}, fmt.Errorf("Could not get current value of setting %s: %w", decoded.SettingPath, err)
+	}
+	current := NewSettingValue(currentValue, decoded.ProposedValue.Unit)
+	decoded.CurrentValue = &current
+	return decoded, nil
+
+}
+
+// Decode a proposal payload against its DAO contract ABI; setting values are formatted with the units the settings packages register
+func DecodeProposalPayload(daoContractAbi *abi.ABI, payload []byte) (DecodedProposalPayload, error) {
+
+	// Get proposal payload method
+	method, err := daoContractAbi.MethodById(payload)
+	if err != nil {
+		return DecodedProposalPayload{}, fmt.Errorf("Could not get proposal payload method: %w", err)
+	}
+
+	// Get proposal payload argument values
+	args, err := method.Inputs.UnpackValues(payload[4:])
+	if err != nil {
+		return DecodedProposalPayload{}, fmt.Errorf("Could not get proposal payload arguments: %w", err)
+	}
+
+	// Build the named arguments
+	decoded := DecodedProposalPayload{
+		Method:    method.RawName,
+		Arguments: make([]ProposalPayloadArgument, len(args)),
+	}
+	for ai, arg := range args {
+		input := method.Inputs[ai]
+		if input.Type.T == abi.BytesTy {
+			arg = hexutil.Bytes(arg.([]byte))
+		}
+		decoded.Arguments[ai] = ProposalPayloadArgument{
+			Name:  strings.TrimPrefix(input.Name, "_"),
+			Type:  input.Type.String(),
+			Value: arg,
+		}
+	}
+
+	// Get the affected contract and setting
+	switch {
+	case settingProposalGetters[decoded.Method] != "" && len(args) == 3:
+		contractName, ok1 := args[0].(string)
+		settingPath, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			return DecodedProposalPayload{}, fmt.Errorf("Invalid setting arguments for proposal payload method %s", decoded.Method)
+		}
+		proposed := NewSettingValue(args[2], GetSettingUnit(contractName, settingPath))
+		decoded.ContractName = contractName
+		decoded.SettingPath = settingPath
+		decoded.ProposedValue = &proposed
+	case decoded.Method == "proposalUpgrade" && len(args) == 4:
+		if contractName, ok := args[1].(string); ok {
+			decoded.ContractName = contractName
+		}
+	}
+	return decoded, nil
+
+}
+
+// Get a setting value from its settings contract
+func getSettingValue(settingsContract *rocketpool.Contract, getter string, settingPath string, opts *bind.CallOpts) (interface{}, error) {
+	switch getter {
+	case "getSettingUint":
+		value := new(*big.Int)
+		err := settingsContract.Call(opts, value, getter, settingPath)
+		return *value, err
+	case "getSettingBool":
+		value := new(bool)
+		err := settingsContract.Call(opts, value, getter, settingPath)
+		return *value, err
+	case "getSettingAddress":
+		value := new(common.Address)
+		err := settingsContract.Call(opts, value, getter, settingPath)
+		return *value, err
+	}
+	return nil, fmt.Errorf("Unknown setting getter %s", getter)
+}
@@ -0,0 +1,95 @@
+package dao
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+)
+
+// A vote cast on a proposal
+type ProposalVote struct {
+	ProposalID  uint64         `json:"proposalId"`
+	Voter       common.Address `json:"voter"`
+	Supported   bool           `json:"supported"`
+	Time        time.Time      `json:"time"`
+	BlockNumber uint64         `json:"blockNumber"`
+	TxHash      common.Hash    `json:"txHash"`
+}
+
+// Get the votes cast on every proposal, from every DAO, since fromBlock, in the order they were cast
+func GetProposalVotes(rp *rocketpool.RocketPool, fromBlock *big.Int, intervalSize *big.Int, opts *bind.CallOpts) ([]ProposalVote, error) {
+	rocketDAOProposal, err := getRocketDAOProposal(rp, opts)
+	if err != nil {
+		return nil, err
+	}
+	abiEvent, exists := rocketDAOProposal.ABI.Events["ProposalVoted"]
+	if !exists {
+		return nil, fmt.Errorf("Event ProposalVoted does not exist on the proposal contract")
+	}
+
+	// Get the event logs from every proposal contract deployment
+	logs, err := eth.FilterContractLogs(rp, "poolseaDAOProposal", eth.FilterQuery{
+		FromBlock: fromBlock,
+		Topics:    [][]common.Hash{{abiEvent.ID}},
+	}, intervalSize, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not get proposal vote events: %w", err)
+	}
+
+	// Decode them
+	votes := make([]ProposalVote, 0, len(logs))
+	for _, log := range logs {
+		vote, err := decodeProposalVote(abiEvent, log)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, vote)
+	}
+	return votes, nil
+}
+
+// Decode a proposal vote event log
+func decodeProposalVote(abiEvent abi.Event, log types.Log) (ProposalVote, error) {
+	values := make(map[string]interface{})
+	if err := abiEvent.Inputs.UnpackIntoMap(values, log.Data); err != nil {
+		return ProposalVote{}, fmt.Errorf("Could not decode proposal vote in transaction %s: %w", log.TxHash.Hex(), err)
+	}
+	indexed := abi.Arguments{}
+	for _, input := range abiEvent.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if len(log.Topics) < len(indexed)+1 {
+		return ProposalVote{}, fmt.Errorf("Proposal vote in transaction %s is missing topics", log.TxHash.Hex())
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+		return ProposalVote{}, fmt.Errorf("Could not decode proposal vote topics in transaction %s: %w", log.TxHash.Hex(), err)
+	}
+
+	vote := ProposalVote{
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash,
+	}
+	if proposalId, ok := values["proposalID"].(*big.Int); ok {
+		vote.ProposalID = proposalId.Uint64()
+	}
+	if voter, ok := values["voter"].(common.Address); ok {
+		vote.Voter = voter
+	}
+	if supported, ok := values["supported"].(bool); ok {
+		vote.Supported = supported
+	}
+	if voteTime, ok := values["time"].(*big.Int); ok {
+		vote.Time = time.Unix(voteTime.Int64(), 0)
+	}
+	return vote, nil
+}
@@ -0,0 +1,104 @@
+package dao
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+// A DAO setting, identified by its settings contract and path
+type settingKey struct {
+	contractName string
+	settingPath  string
+}
+
+// The units of the settings registered by the settings packages
+var registeredSettingUnits = map[settingKey]rptypes.SettingUnit{}
+var registeredSettingUnitsLock sync.RWMutex
+
+// A setting value and its human-readable form
+type SettingValue struct {
+	Raw       interface{}         `json:"raw"` // *big.Int, bool or common.Address
+	Unit      rptypes.SettingUnit `json:"unit"`
+	Formatted string              `json:"formatted"`
+}
+
+// Register the unit of a setting; the settings packages register each of their settings when they're loaded
+func RegisterSettingUnit(contractName, settingPath string, unit rptypes.SettingUnit) {
+	registeredSettingUnitsLock.Lock()
+	defer registeredSettingUnitsLock.Unlock()
+	registeredSettingUnits[settingKey{contractName, settingPath}] = unit
+}
+
+// Get the unit of a registered setting; unregistered settings are treated as plain numbers, flags or addresses
+func GetSettingUnit(contractName, settingPath string) rptypes.SettingUnit {
+	registeredSettingUnitsLock.RLock()
+	defer registeredSettingUnitsLock.RUnlock()
+	return registeredSettingUnits[settingKey{contractName, settingPath}]
+}
+
+// Create a setting value, formatting it with its unit
+func NewSettingValue(raw interface{}, unit rptypes.SettingUnit) SettingValue {
+	value := SettingValue{
+		Raw:  raw,
+		Unit: unit,
+	}
+	switch v := raw.(type) {
+	case bool:
+		value.Formatted = strconv.FormatBool(v)
+	case common.Address:
+		value.Formatted = v.Hex()
+	case *big.Int:
+		if v != nil {
+			value.Formatted = formatUintSetting(v, unit)
+		}
+	default:
+		value.Formatted = fmt.Sprintf("%v", v)
+	}
+	return value
+}
+
+// Format a uint setting value with its unit
+func formatUintSetting(value *big.Int, unit rptypes.SettingUnit) string {
+	switch unit {
+	case rptypes.SettingUnitPercent:
+		return formatFixedPoint(value, 16) + "%"
+	case rptypes.SettingUnitEth, rptypes.SettingUnitRpl:
+		return fmt.Sprintf("%s %s", formatFixedPoint(value, 18), unit)
+	case rptypes.SettingUnitSeconds:
+		if value.IsInt64() && value.Int64() <= int64(time.Duration(1<<63-1)/time.Second) {
+			return fmt.Sprintf("%s seconds (%s)", value.String(), time.Duration(value.Int64())*time.Second)
+		}
+		return fmt.Sprintf("%s seconds", value.String())
+	case rptypes.SettingUnitBlocks:
+		return fmt.Sprintf("%s blocks", value.String())
+	case rptypes.SettingUnitTimestamp:
+		if value.IsInt64() {
+			return time.Unix(value.Int64(), 0).UTC().Format(time.RFC3339)
+		}
+	}
+	return value.String()
+}
+
+// Format a fixed-point value exactly, without trailing zeros
+func formatFixedPoint(value *big.Int, decimals int) string {
+	base := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, fraction := new(big.Int).QuoRem(new(big.Int).Abs(value), base, new(big.Int))
+	str := whole.String()
+	if fraction.Sign() > 0 {
+		fractionStr := fraction.String()
+		fractionStr = strings.Repeat("0", decimals-len(fractionStr)) + fractionStr
+		str += "." + strings.TrimRight(fractionStr, "0")
+	}
+	if value.Sign() < 0 {
+		str = "-" + str
+	}
+	return str
+}
@@ -0,0 +1,290 @@
+package trustednode
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+)
+
+// Config
+const membersSettingsContractName = "poolseaDAONodeTrustedSettingsMembers"
+
+// The type of a challenge event
+type ChallengeEventType string
+
+const (
+	ChallengeEventMade    ChallengeEventType = "ActionChallengeMade"
+	ChallengeEventDecided ChallengeEventType = "ActionChallengeDecided"
+)
+
+// The type of a challenge monitor notification
+type ChallengeNotificationType string
+
+const (
+	ChallengeNotificationChallenged     ChallengeNotificationType = "challenged"
+	ChallengeNotificationResponded      ChallengeNotificationType = "responded"
+	ChallengeNotificationResponseFailed ChallengeNotificationType = "responseFailed"
+	ChallengeNotificationDecided        ChallengeNotificationType = "decided"
+)
+
+// An event emitted when a member is challenged, or a challenge is decided
+type ChallengeEvent struct {
+	Type        ChallengeEventType `json:"type"`
+	Challenged  common.Address     `json:"challenged"`
+	Sender      common.Address     `json:"sender"`  // The challenger, or the node that decided the challenge
+	Success     bool               `json:"success"` // Whether the member was removed; only set on decided events
+	Time        time.Time          `json:"time"`
+	BlockNumber uint64             `json:"blockNumber"`
+	TxHash      common.Hash        `json:"txHash"`
+}
+
+// The state of a member's current challenge
+type ChallengeStatus struct {
+	Member          common.Address `json:"member"`
+	IsChallenged    bool           `json:"isChallenged"`
+	ChallengedTime  time.Time      `json:"challengedTime"`
+	ChallengeWindow time.Duration  `json:"challengeWindow"`
+	Deadline        time.Time      `json:"deadline"` // After this, any member can decide the challenge and remove the challenged member
+	TimeRemaining   time.Duration  `json:"timeRemaining"`
+}
+
+// A notification from the challenge monitor
+type ChallengeNotification struct {
+	Type   ChallengeNotificationType `json:"type"`
+	Status ChallengeStatus           `json:"status"`
+	Event  *ChallengeEvent           `json:"event,omitempty"`
+	TxHash common.Hash               `json:"txHash"`
+	Error  error                     `json:"-"`
+}
+
+// Receives challenge monitor notifications
+type ChallengeNotifier func(notification ChallengeNotification)
+
+// Watches for challenges against a member, and defeats them by deciding the challenge from the member's own node
+type ChallengeMonitor struct {
+	Member       common.Address
+	IntervalSize *big.Int
+	Transactor   *bind.TransactOpts // The member's transactor; the monitor won't respond to challenges without one
+	Notify       ChallengeNotifier
+
+	fromBlock     *big.Int
+	respondedTime time.Time
+}
+
+// Create a challenge monitor for a member that scans for events from fromBlock, or from the deploy block if it is nil.
+// The transactor and notifier are optional.
+func NewChallengeMonitor(member common.Address, fromBlock *big.Int, intervalSize *big.Int, transactor *bind.TransactOpts, notify ChallengeNotifier) *ChallengeMonitor {
+	return &ChallengeMonitor{
+		Member:       member,
+		IntervalSize: intervalSize,
+		Transactor:   transactor,
+		Notify:       notify,
+		fromBlock:    fromBlock,
+	}
+}
+
+// Check the member for new challenge events and an open challenge, responding to and notifying about it as configured.
+// Events are scanned from the monitor's starting block on the first check, and from the block after the previous check after that.
+func (m *ChallengeMonitor) Check(rp *rocketpool.RocketPool, now time.Time) (ChallengeStatus, []ChallengeEvent, error) {
+
+	// Get the block range to scan
+	latestBlock, err := rp.Client.BlockNumber(context.Background())
+	if err != nil {
+		return ChallengeStatus{}, nil, fmt.Errorf("Could not get latest block: %w", err)
+	}
+	toBlock := new(big.Int).SetUint64(latestBlock)
+	fromBlock := m.fromBlock
+	opts := &bind.CallOpts{BlockNumber: toBlock}
+
+	// Data
+	var wg errgroup.Group
+	var status ChallengeStatus
+	events := []ChallengeEvent{}
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		status, err = GetChallengeStatus(rp, m.Member, now, opts)
+		return err
+	})
+	if fromBlock == nil || fromBlock.Cmp(toBlock) <= 0 {
+		wg.Go(func() error {
+			var err error
+			events, err = GetChallengeEvents(rp, m.Member, fromBlock, toBlock, m.IntervalSize, opts)
+			return err
+		})
+	}
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return ChallengeStatus{}, nil, err
+	}
+	m.fromBlock = new(big.Int).Add(toBlock, big.NewInt(1))
+
+	// Notify about decided challenges
+	for i := range events {
+		if events[i].Type == ChallengeEventDecided {
+			m.notify(ChallengeNotification{
+				Type:   ChallengeNotificationDecided,
+				Status: status,
+				Event:  &events[i],
+			})
+		}
+	}
+
+	// Handle an open challenge once
+	if !status.IsChallenged || status.ChallengedTime.Equal(m.respondedTime) {
+		return status, events, nil
+	}
+	m.respondedTime = status.ChallengedTime
+	m.notify(ChallengeNotification{
+		Type:   ChallengeNotificationChallenged,
+		Status: status,
+	})
+	if m.Transactor == nil {
+		return status, events, nil
+	}
+	hash, err := DecideChallenge(rp, m.Member, m.Transactor)
+	if err != nil {
+		m.respondedTime = time.Time{}
+		m.notify(ChallengeNotification{
+			Type:   ChallengeNotificationResponseFailed,
+			Status: status,
+			Error:  err,
+		})
+		return status, events, err
+	}
+	m.notify(ChallengeNotification{
+		Type:   ChallengeNotificationResponded,
+		Status: status,
+		TxHash: hash,
+	})
+	return status, events, nil
+
+}
+
+// Send a notification if there is a notifier
+func (m *ChallengeMonitor) notify(notification ChallengeNotification) {
+	if m.Notify != nil {
+		m.Notify(notification)
+	}
+}
+
+// Get the state of a member's current challenge
+func GetChallengeStatus(rp *rocketpool.RocketPool, memberAddress common.Address, now time.Time, opts *bind.CallOpts) (ChallengeStatus, error) {
+
+	// Data
+	var wg errgroup.Group
+	status := ChallengeStatus{Member: memberAddress}
+	var challengedTime uint64
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		status.IsChallenged, err = GetMemberIsChallenged(rp, memberAddress, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		challengedTime, err = GetMemberChallengedTime(rp, memberAddress, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		status.ChallengeWindow, err = getTrustedNodeDurationSetting(rp, membersSettingsContractName, "getChallengeWindow", opts)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return ChallengeStatus{}, fmt.Errorf("Could not get challenge status for member %s: %w", memberAddress.Hex(), err)
+	}
+
+	if status.IsChallenged {
+		status.ChallengedTime = time.Unix(int64(challengedTime), 0)
+		status.Deadline = status.ChallengedTime.Add(status.ChallengeWindow)
+		if now.Before(status.Deadline) {
+			status.TimeRemaining = status.Deadline.Sub(now)
+		}
+	}
+	return status, nil
+
+}
+
+// Get the challenges made and decided against a member between two blocks
+func GetChallengeEvents(rp *rocketpool.RocketPool, memberAddress common.Address, fromBlock *big.Int, toBlock *big.Int, intervalSize *big.Int, opts *bind.CallOpts) ([]ChallengeEvent, error) {
+	rocketDAONodeTrustedActions, err := getRocketDAONodeTrustedActions(rp, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Construct a filter query for the challenge events
+	eventIds := []common.Hash{}
+	for _, eventType := range []ChallengeEventType{ChallengeEventMade, ChallengeEventDecided} {
+		abiEvent, exists := rocketDAONodeTrustedActions.ABI.Events[string(eventType)]
+		if !exists {
+			return nil, fmt.Errorf("Event %s does not exist on the trusted node DAO actions contract", eventType)
+		}
+		eventIds = append(eventIds, abiEvent.ID)
+	}
+	logs, err := eth.FilterContractLogs(rp, "poolseaDAONodeTrustedActions", eth.FilterQuery{
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Topics:    [][]common.Hash{eventIds, {common.BytesToHash(memberAddress.Bytes())}},
+	}, intervalSize, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not get challenge events for member %s: %w", memberAddress.Hex(), err)
+	}
+
+	// Decode them
+	events := make([]ChallengeEvent, 0, len(logs))
+	for _, log := range logs {
+		event, err := decodeChallengeEvent(rocketDAONodeTrustedActions, log)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Decode a challenge event log
+func decodeChallengeEvent(rocketDAONodeTrustedActions *rocketpool.Contract, log types.Log) (ChallengeEvent, error) {
+	if len(log.Topics) < 3 {
+		return ChallengeEvent{}, fmt.Errorf("Challenge event in transaction %s is missing topics", log.TxHash.Hex())
+	}
+	abiEvent, err := rocketDAONodeTrustedActions.ABI.EventByID(log.Topics[0])
+	if err != nil {
+		return ChallengeEvent{}, fmt.Errorf("Could not get challenge event in transaction %s: %w", log.TxHash.Hex(), err)
+	}
+	values, err := abiEvent.Inputs.NonIndexed().UnpackValues(log.Data)
+	if err != nil {
+		return ChallengeEvent{}, fmt.Errorf("Could not decode %s event in transaction %s: %w", abiEvent.Name, log.TxHash.Hex(), err)
+	}
+
+	event := ChallengeEvent{
+		Type:        ChallengeEventType(abiEvent.Name),
+		Challenged:  common.BytesToAddress(log.Topics[1].Bytes()),
+		Sender:      common.BytesToAddress(log.Topics[2].Bytes()),
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash,
+	}
+	for _, value := range values {
+		switch value := value.(type) {
+		case bool:
+			event.Success = value
+		case *big.Int:
+			event.Time = time.Unix(value.Int64(), 0)
+		}
+	}
+	return event, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/Seb369888/poolsea-go/rocketpool"
@@ -353,6 +354,15 @@ func GetMemberIsChallenged(rp *rocketpool.RocketPool, memberAddress common.Addre
 	return *isChallenged, nil
 }
 
+// Get the time a member was last challenged at
+func GetMemberChallengedTime(rp *rocketpool.RocketPool, memberAddress common.Address, opts *bind.CallOpts) (uint64, error) {
+	challengedTime, err := rp.RocketStorage.GetUint(opts, crypto.Keccak256Hash([]byte("dao.trustednodes."), []byte("member.challenged.time"), memberAddress.Bytes()))
+	if err != nil {
+		return 0, fmt.Errorf("Could not get trusted node DAO member %s challenged time: %w", memberAddress.Hex(), err)
+	}
+	return challengedTime.Uint64(), nil
+}
+
 // Estimate the gas of BootstrapBool
 func EstimateBootstrapBoolGas(rp *rocketpool.RocketPool, contractName, settingPath string, value bool, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
 	rocketDAONodeTrusted, err := getRocketDAONodeTrusted(rp, nil)
@@ -0,0 +1,294 @@
+package trustednode
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/dao"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/tokens"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+// Joining or leaving the oracle DAO
+type MembershipWorkflowType string
+
+const (
+	MembershipWorkflowJoin  MembershipWorkflowType = "join"
+	MembershipWorkflowLeave MembershipWorkflowType = "leave"
+)
+
+// A step in joining or leaving the oracle DAO
+type MembershipStep string
+
+const (
+	MembershipStepPropose    MembershipStep = "propose"    // An existing member proposes the invite or leave
+	MembershipStepAwaitVotes MembershipStep = "awaitVotes" // The members vote on the proposal
+	MembershipStepExecute    MembershipStep = "execute"    // A member executes the passed proposal
+	MembershipStepApproveRPL MembershipStep = "approveRpl" // The candidate approves the RPL bond for transfer
+	MembershipStepJoin       MembershipStep = "join"       // The candidate joins within the action window
+	MembershipStepLeave      MembershipStep = "leave"      // The member leaves within the action window
+	MembershipStepComplete   MembershipStep = "complete"
+)
+
+// The proposal payload methods for each workflow
+var membershipProposalMethods = map[MembershipWorkflowType]string{
+	MembershipWorkflowJoin:  "proposalInvite",
+	MembershipWorkflowLeave: "proposalLeave",
+}
+
+// The on-chain state of a candidate or departing member
+type MembershipState struct {
+	Address              common.Address       `json:"address"`
+	IsMember             bool                 `json:"isMember"`
+	Proposal             *dao.ProposalDetails `json:"proposal,omitempty"` // The latest invite or leave proposal for the address
+	ProposalExecutedTime time.Time            `json:"proposalExecutedTime"`
+	ActionTime           time.Duration        `json:"actionTime"`
+	MemberCount          uint64               `json:"memberCount"`
+	MinimumMemberCount   uint64               `json:"minimumMemberCount"`
+	RPLBond              *big.Int             `json:"rplBond"`
+	RPLBalance           *big.Int             `json:"rplBalance"`
+	RPLAllowance         *big.Int             `json:"rplAllowance"`
+}
+
+// Where a candidate or departing member is in joining or leaving the oracle DAO
+type MembershipWorkflow struct {
+	Type            MembershipWorkflowType `json:"type"`
+	State           MembershipState        `json:"state"`
+	Step            MembershipStep         `json:"step"`
+	Blocked         bool                   `json:"blocked"`
+	BlockedReason   string                 `json:"blockedReason,omitempty"`
+	ActionWindowEnd time.Time              `json:"actionWindowEnd"` // Zero until the proposal has been executed
+}
+
+// The details needed to run a workflow's proposal and leave steps
+type MembershipStepParams struct {
+	Message       string         `json:"message"`
+	ID            string         `json:"id"`            // The candidate's member ID, for invites
+	Url           string         `json:"url"`           // The candidate's URL, for invites
+	RefundAddress common.Address `json:"refundAddress"` // The address to refund the RPL bond to, for leaving
+}
+
+// Get the workflow for a candidate joining the oracle DAO
+func GetJoinWorkflow(rp *rocketpool.RocketPool, candidate common.Address, now time.Time, opts *bind.CallOpts) (MembershipWorkflow, error) {
+	state, err := GetMembershipState(rp, MembershipWorkflowJoin, candidate, opts)
+	if err != nil {
+		return MembershipWorkflow{}, err
+	}
+	return EvaluateMembershipWorkflow(MembershipWorkflowJoin, state, now), nil
+}
+
+// Get the workflow for a member leaving the oracle DAO
+func GetLeaveWorkflow(rp *rocketpool.RocketPool, member common.Address, now time.Time, opts *bind.CallOpts) (MembershipWorkflow, error) {
+	state, err := GetMembershipState(rp, MembershipWorkflowLeave, member, opts)
+	if err != nil {
+		return MembershipWorkflow{}, err
+	}
+	return EvaluateMembershipWorkflow(MembershipWorkflowLeave, state, now), nil
+}
+
+// Get the on-chain state of a candidate or departing member
+func GetMembershipState(rp *rocketpool.RocketPool, workflowType MembershipWorkflowType, address common.Address, opts *bind.CallOpts) (MembershipState, error) {
+	method, exists := membershipProposalMethods[workflowType]
+	if !exists {
+		return MembershipState{}, fmt.Errorf("Unknown membership workflow %s", workflowType)
+	}
+	rocketDAONodeTrustedProposals, err := getRocketDAONodeTrustedProposals(rp, opts)
+	if err != nil {
+		return MembershipState{}, err
+	}
+	rocketDAONodeTrustedActions, err := getRocketDAONodeTrustedActions(rp, opts)
+	if err != nil {
+		return MembershipState{}, err
+	}
+
+	// Data
+	var wg errgroup.Group
+	state := MembershipState{Address: address}
+	var proposals []dao.ProposalDetails
+	var executedTime uint64
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		state.IsMember, err = GetMemberExists(rp, address, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		proposals, err = dao.GetDAOProposals(rp, "poolseaDAONodeTrustedProposals", opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		if workflowType == MembershipWorkflowJoin {
+			executedTime, err = GetMemberInviteProposalExecutedTime(rp, address, opts)
+		} else {
+			executedTime, err = GetMemberLeaveProposalExecutedTime(rp, address, opts)
+		}
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.ActionTime, err = getTrustedNodeDurationSetting(rp, proposalsSettingsContractName, "getActionTime", opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.MemberCount, err = GetMemberCount(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.MinimumMemberCount, err = GetMinimumMemberCount(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.RPLBond, err = getTrustedNodeUintSetting(rp, membersSettingsContractName, "getRPLBond", opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.RPLBalance, err = tokens.GetRPLBalance(rp, address, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.RPLAllowance, err = tokens.GetRPLAllowance(rp, address, *rocketDAONodeTrustedActions.Address, opts)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return MembershipState{}, fmt.Errorf("Could not get oracle DAO membership state for %s: %w", address.Hex(), err)
+	}
+	if executedTime > 0 {
+		state.ProposalExecutedTime = time.Unix(int64(executedTime), 0)
+	}
+
+	// Find the latest proposal for the address
+	for i := len(proposals) - 1; i >= 0; i-- {
+		if len(proposals[i].Payload) < 4 {
+			continue
+		}
+		decoded, err := dao.DecodeProposalPayload(rocketDAONodeTrustedProposals.ABI, proposals[i].Payload)
+		if err != nil || decoded.Method != method {
+			continue
+		}
+		for _, arg := range decoded.Arguments {
+			if proposalAddress, ok := arg.Value.(common.Address); ok && proposalAddress == address {
+				state.Proposal = &proposals[i]
+				break
+			}
+		}
+		if state.Proposal != nil {
+			break
+		}
+	}
+	return state, nil
+
+}
+
+// Get where a candidate or departing member is in joining or leaving the oracle DAO
+func EvaluateMembershipWorkflow(workflowType MembershipWorkflowType, state MembershipState, now time.Time) MembershipWorkflow {
+
+	workflow := MembershipWorkflow{
+		Type:  workflowType,
+		State: state,
+	}
+
+	// Check whether the workflow is complete
+	if (workflowType == MembershipWorkflowJoin && state.IsMember) || (workflowType == MembershipWorkflowLeave && !state.IsMember) {
+		workflow.Step = MembershipStepComplete
+		return workflow
+	}
+	if workflowType == MembershipWorkflowLeave && state.MemberCount <= state.MinimumMemberCount {
+		workflow.block(fmt.Sprintf("The oracle DAO has %d members and can't fall below its minimum of %d", state.MemberCount, state.MinimumMemberCount))
+	}
+
+	// Get the step from the proposal
+	proposalState := rptypes.Cancelled
+	if state.Proposal != nil {
+		proposalState = state.Proposal.State
+	}
+	switch proposalState {
+	case rptypes.Pending, rptypes.Active:
+		workflow.Step = MembershipStepAwaitVotes
+		return workflow
+	case rptypes.Succeeded:
+		workflow.Step = MembershipStepExecute
+		return workflow
+	case rptypes.Executed:
+		workflow.ActionWindowEnd = state.ProposalExecutedTime.Add(state.ActionTime)
+		if state.ProposalExecutedTime.IsZero() || now.After(workflow.ActionWindowEnd) {
+			workflow.Step = MembershipStepPropose
+			return workflow
+		}
+	default:
+		workflow.Step = MembershipStepPropose
+		return workflow
+	}
+
+	// The proposal has been executed and the action window is open
+	if workflowType == MembershipWorkflowLeave {
+		workflow.Step = MembershipStepLeave
+		return workflow
+	}
+	if state.RPLBond != nil && state.RPLAllowance != nil && state.RPLAllowance.Cmp(state.RPLBond) < 0 {
+		workflow.Step = MembershipStepApproveRPL
+	} else {
+		workflow.Step = MembershipStepJoin
+	}
+	if state.RPLBond != nil && state.RPLBalance != nil && state.RPLBalance.Cmp(state.RPLBond) < 0 {
+		workflow.block(fmt.Sprintf("%s has %s RPL but the bond is %s RPL", state.Address.Hex(), state.RPLBalance.String(), state.RPLBond.String()))
+	}
+	return workflow
+
+}
+
+// Run the workflow's next step.
+// Proposal and execute steps must be run by an existing member; the other steps by the candidate or departing member.
+func (w MembershipWorkflow) RunNextStep(rp *rocketpool.RocketPool, params MembershipStepParams, opts *bind.TransactOpts) (common.Hash, error) {
+	if w.Blocked {
+		return common.Hash{}, fmt.Errorf("Could not run %s step: %s", w.Step, w.BlockedReason)
+	}
+	switch w.Step {
+	case MembershipStepPropose:
+		var err error
+		var hash common.Hash
+		if w.Type == MembershipWorkflowJoin {
+			_, hash, err = ProposeInviteMember(rp, params.Message, w.State.Address, params.ID, params.Url, opts)
+		} else {
+			_, hash, err = ProposeMemberLeave(rp, params.Message, w.State.Address, opts)
+		}
+		return hash, err
+	case MembershipStepExecute:
+		return ExecuteProposal(rp, w.State.Proposal.ID, opts)
+	case MembershipStepApproveRPL:
+		rocketDAONodeTrustedActions, err := getRocketDAONodeTrustedActions(rp, nil)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		return tokens.ApproveRPL(rp, *rocketDAONodeTrustedActions.Address, w.State.RPLBond, opts)
+	case MembershipStepJoin:
+		return Join(rp, opts)
+	case MembershipStepLeave:
+		if params.RefundAddress == (common.Address{}) {
+			return common.Hash{}, fmt.Errorf("Could not run %s step: no RPL bond refund address was provided", w.Step)
+		}
+		return Leave(rp, params.RefundAddress, opts)
+	}
+	return common.Hash{}, fmt.Errorf("The %s step can't be run", w.Step)
+}
+
+// Mark the workflow as blocked
+func (w *MembershipWorkflow) block(reason string) {
+	w.Blocked = true
+	w.BlockedReason = reason
+}
@@ -0,0 +1,278 @@
+package trustednode
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/dao"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+// Config
+const proposalsSettingsContractName = "poolseaDAONodeTrustedSettingsProposals"
+
+// The next thing a member needs to do, or wait for, on an open proposal
+type ProposalActionType string
+
+const (
+	ProposalActionWaitForVoting ProposalActionType = "waitForVoting"
+	ProposalActionVote          ProposalActionType = "vote"
+	ProposalActionWaitForQuorum ProposalActionType = "waitForQuorum"
+	ProposalActionExecute       ProposalActionType = "execute"
+)
+
+// The oracle DAO proposal timing settings
+type ProposalTimingSettings struct {
+	VoteDelayTime time.Duration `json:"voteDelayTime"`
+	VoteTime      time.Duration `json:"voteTime"`
+	ExecuteTime   time.Duration `json:"executeTime"`
+}
+
+// Decides whether a member votes on a proposal, and which way
+type VotePolicy func(proposal dao.ProposalDetails) (vote bool, support bool)
+
+// The settings for a proposal scheduler
+type ProposalSchedulerConfig struct {
+	Member           common.Address `json:"member"`
+	AutoExecute      bool           `json:"autoExecute"`
+	ExecutionWarning time.Duration  `json:"executionWarning"` // How long before expiry a passed proposal's execution window is considered to be closing
+}
+
+// An open proposal and the next action on it
+type ScheduledProposal struct {
+	Proposal         dao.ProposalDetails `json:"proposal"`
+	NextAction       ProposalActionType  `json:"nextAction"`
+	NextActionTime   time.Time           `json:"nextActionTime"`
+	Deadline         time.Time           `json:"deadline"`
+	QuorumReached    bool                `json:"quorumReached"`
+	ExecutionClosing bool                `json:"executionClosing"`
+}
+
+// The open proposals for a member, in the order their next actions are due
+type ProposalSchedule struct {
+	Member    common.Address         `json:"member"`
+	Settings  ProposalTimingSettings `json:"settings"`
+	Time      time.Time              `json:"time"`
+	Proposals []ScheduledProposal    `json:"proposals"`
+}
+
+// A transaction submitted by the scheduler
+type ProposalTransaction struct {
+	ProposalID uint64             `json:"proposalId"`
+	Action     ProposalActionType `json:"action"`
+	Support    bool               `json:"support"`
+	Hash       common.Hash        `json:"hash"`
+}
+
+// Tracks a member's open proposals, voting on them according to a policy and executing them once passed
+type ProposalScheduler struct {
+	Config     ProposalSchedulerConfig
+	VotePolicy VotePolicy
+}
+
+// Create a proposal scheduler; the vote policy is optional, and the scheduler won't vote without one
+func NewProposalScheduler(config ProposalSchedulerConfig, votePolicy VotePolicy) *ProposalScheduler {
+	return &ProposalScheduler{
+		Config:     config,
+		VotePolicy: votePolicy,
+	}
+}
+
+// Get the oracle DAO proposal timing settings
+func GetProposalTimingSettings(rp *rocketpool.RocketPool, opts *bind.CallOpts) (ProposalTimingSettings, error) {
+
+	// Data
+	var wg errgroup.Group
+	var settings ProposalTimingSettings
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		settings.VoteDelayTime, err = getTrustedNodeDurationSetting(rp, proposalsSettingsContractName, "getVoteDelayTime", opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		settings.VoteTime, err = getTrustedNodeDurationSetting(rp, proposalsSettingsContractName, "getVoteTime", opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		settings.ExecuteTime, err = getTrustedNodeDurationSetting(rp, proposalsSettingsContractName, "getExecuteTime", opts)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return ProposalTimingSettings{}, err
+	}
+	return settings, nil
+
+}
+
+// Get the schedule of the member's open proposals at the provided time
+func (s *ProposalScheduler) Plan(rp *rocketpool.RocketPool, now time.Time, opts *bind.CallOpts) (ProposalSchedule, error) {
+
+	// Data
+	var wg errgroup.Group
+	var proposals []dao.ProposalDetails
+	var settings ProposalTimingSettings
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		proposals, err = dao.GetDAOProposalsWithMember(rp, "poolseaDAONodeTrustedProposals", s.Config.Member, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		settings, err = GetProposalTimingSettings(rp, opts)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return ProposalSchedule{}, fmt.Errorf("Could not get proposal schedule for member %s: %w", s.Config.Member.Hex(), err)
+	}
+	return s.Evaluate(proposals, settings, now), nil
+
+}
+
+// Get the schedule of the provided proposals at the provided time; closed proposals are left out
+func (s *ProposalScheduler) Evaluate(proposals []dao.ProposalDetails, settings ProposalTimingSettings, now time.Time) ProposalSchedule {
+
+	schedule := ProposalSchedule{
+		Member:    s.Config.Member,
+		Settings:  settings,
+		Time:      now,
+		Proposals: []ScheduledProposal{},
+	}
+	for _, proposal := range proposals {
+		scheduled := ScheduledProposal{
+			Proposal:      proposal,
+			QuorumReached: proposal.VotesRequired > 0 && proposal.VotesFor >= proposal.VotesRequired,
+		}
+		switch proposal.State {
+		case rptypes.Pending:
+			scheduled.NextAction = ProposalActionWaitForVoting
+			scheduled.NextActionTime = time.Unix(int64(proposal.StartTime), 0)
+			scheduled.Deadline = time.Unix(int64(proposal.EndTime), 0)
+		case rptypes.Active:
+			scheduled.Deadline = time.Unix(int64(proposal.EndTime), 0)
+			if proposal.MemberVoted {
+				scheduled.NextAction = ProposalActionWaitForQuorum
+				scheduled.NextActionTime = scheduled.Deadline
+			} else {
+				scheduled.NextAction = ProposalActionVote
+				scheduled.NextActionTime = now
+			}
+		case rptypes.Succeeded:
+			scheduled.NextAction = ProposalActionExecute
+			scheduled.NextActionTime = now
+			scheduled.Deadline = time.Unix(int64(proposal.ExpiryTime), 0)
+			scheduled.ExecutionClosing = !now.Before(scheduled.Deadline.Add(-s.Config.ExecutionWarning))
+		default:
+			continue
+		}
+		schedule.Proposals = append(schedule.Proposals, scheduled)
+	}
+
+	// Order by the time the next action is due, then by deadline
+	sort.SliceStable(schedule.Proposals, func(i, j int) bool {
+		a, b := schedule.Proposals[i], schedule.Proposals[j]
+		if !a.NextActionTime.Equal(b.NextActionTime) {
+			return a.NextActionTime.Before(b.NextActionTime)
+		}
+		return a.Deadline.Before(b.Deadline)
+	})
+	return schedule
+
+}
+
+// Vote on and execute the proposals in the schedule that are due, returning the transactions that were submitted.
+// The gas limit is estimated for each transaction; if opts has a nonce, it's used for the first transaction and incremented for each one after it.
+func (s *ProposalScheduler) Execute(rp *rocketpool.RocketPool, schedule ProposalSchedule, opts *bind.TransactOpts) ([]ProposalTransaction, error) {
+	transactions := []ProposalTransaction{}
+	for _, scheduled := range schedule.Proposals {
+		proposalId := scheduled.Proposal.ID
+		actionOpts := *opts
+		actionOpts.GasLimit = 0
+		if opts.Nonce != nil {
+			actionOpts.Nonce = new(big.Int).Add(opts.Nonce, big.NewInt(int64(len(transactions))))
+		}
+		switch scheduled.NextAction {
+		case ProposalActionVote:
+			if s.VotePolicy == nil {
+				continue
+			}
+			vote, support := s.VotePolicy(scheduled.Proposal)
+			if !vote {
+				continue
+			}
+			hash, err := VoteOnProposal(rp, proposalId, support, &actionOpts)
+			if err != nil {
+				return transactions, err
+			}
+			transactions = append(transactions, ProposalTransaction{
+				ProposalID: proposalId,
+				Action:     ProposalActionVote,
+				Support:    support,
+				Hash:       hash,
+			})
+		case ProposalActionExecute:
+			if !s.Config.AutoExecute {
+				continue
+			}
+			hash, err := ExecuteProposal(rp, proposalId, &actionOpts)
+			if err != nil {
+				return transactions, err
+			}
+			transactions = append(transactions, ProposalTransaction{
+				ProposalID: proposalId,
+				Action:     ProposalActionExecute,
+				Hash:       hash,
+			})
+		}
+	}
+	return transactions, nil
+}
+
+// Get an oracle DAO duration setting in seconds.
+// The settings/trustednode getters can't be used here as that package depends on this one.
+func getTrustedNodeDurationSetting(rp *rocketpool.RocketPool, contractName, method string, opts *bind.CallOpts) (time.Duration, error) {
+	value, err := getTrustedNodeUintSetting(rp, contractName, method, opts)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(value.Uint64()) * time.Second, nil
+}
+
+// Get an oracle DAO uint setting
+func getTrustedNodeUintSetting(rp *rocketpool.RocketPool, contractName, method string, opts *bind.CallOpts) (*big.Int, error) {
+	settingsContract, err := getTrustedNodeSettingsContract(rp, contractName, opts)
+	if err != nil {
+		return nil, err
+	}
+	value := new(*big.Int)
+	if err := settingsContract.Call(opts, value, method); err != nil {
+		return nil, fmt.Errorf("Could not get oracle DAO setting %s: %w", method, err)
+	}
+	return *value, nil
+}
+
+// Get contracts
+var trustedNodeSettingsContractLock sync.Mutex
+
+func getTrustedNodeSettingsContract(rp *rocketpool.RocketPool, contractName string, opts *bind.CallOpts) (*rocketpool.Contract, error) {
+	trustedNodeSettingsContractLock.Lock()
+	defer trustedNodeSettingsContractLock.Unlock()
+	return rp.GetContract(contractName, opts)
+}
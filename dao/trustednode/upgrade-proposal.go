@@ -0,0 +1,190 @@
+package trustednode
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+)
+
+// A contract upgrade checked against the currently registered contract
+type UpgradeReport struct {
+	UpgradeType     string                 `json:"upgradeType"`
+	ContractName    string                 `json:"contractName"`
+	ContractAddress common.Address         `json:"contractAddress"`
+	CurrentAddress  common.Address         `json:"currentAddress"` // Zero if the contract isn't registered
+	CodeSize        int                    `json:"codeSize"`
+	CompressedAbi   string                 `json:"compressedAbi"`
+	AbiDiff         rocketpool.AbiDiff     `json:"abiDiff"` // Empty if the new ABI is invalid
+	BreakingChanges []rocketpool.AbiChange `json:"breakingChanges"`
+	CallsKnown      bool                   `json:"callsKnown"` // False if there were no called methods to check against, so every removed or changed method is breaking
+	Errors          []string               `json:"errors"`
+}
+
+// Check a contract upgrade, comparing its ABI with the registered one.
+// Breaking changes are limited to calledMethods if it is provided; see rocketpool.CallRecorder.
+// If calledMethods is nil, such as when the recorder saw no calls to the contract, every removed or changed method is treated as breaking.
+// Upgrades with errors would be rejected by the upgrade contract when the proposal is executed.
+func GetUpgradeReport(rp *rocketpool.RocketPool, upgradeType, contractName, contractAbi string, contractAddress common.Address, calledMethods []string, opts *bind.CallOpts) (UpgradeReport, error) {
+
+	report := UpgradeReport{
+		UpgradeType:     upgradeType,
+		ContractName:    contractName,
+		ContractAddress: contractAddress,
+		CallsKnown:      calledMethods != nil,
+		AbiDiff:         rocketpool.AbiDiff{Methods: []rocketpool.AbiChange{}, Events: []rocketpool.AbiChange{}},
+		BreakingChanges: []rocketpool.AbiChange{},
+		Errors:          []string{},
+	}
+	isAdd := upgradeType == "addContract" || upgradeType == "addABI"
+	isContract := upgradeType == "upgradeContract" || upgradeType == "addContract"
+	if !isAdd && !isContract && upgradeType != "upgradeABI" {
+		report.Errors = append(report.Errors, fmt.Sprintf("Unknown upgrade type %s", upgradeType))
+	}
+
+	// Parse and compress the new ABI
+	newAbi, err := abi.JSON(strings.NewReader(contractAbi))
+	abiValid := err == nil
+	if !abiValid {
+		report.Errors = append(report.Errors, fmt.Sprintf("Invalid contract ABI: %s", err.Error()))
+	}
+	report.CompressedAbi, err = rocketpool.EncodeAbiStr(contractAbi)
+	if err != nil {
+		return UpgradeReport{}, err
+	}
+
+	// Check the new contract's code
+	if isContract {
+		var blockNumber *big.Int
+		if opts != nil {
+			blockNumber = opts.BlockNumber
+		}
+		code, err := rp.Client.CodeAt(context.Background(), contractAddress, blockNumber)
+		if err != nil {
+			return UpgradeReport{}, fmt.Errorf("Could not get code at %s: %w", contractAddress.Hex(), err)
+		}
+		report.CodeSize = len(code)
+		if report.CodeSize == 0 {
+			report.Errors = append(report.Errors, fmt.Sprintf("There is no contract deployed at %s", contractAddress.Hex()))
+		}
+	}
+
+	// Get the registered contract
+	currentAddress, err := rp.GetAddress(contractName, opts)
+	if err != nil {
+		return UpgradeReport{}, err
+	}
+	report.CurrentAddress = *currentAddress
+	var currentAbi *abi.ABI
+	switch {
+	case isAdd && report.CurrentAddress != (common.Address{}):
+		report.Errors = append(report.Errors, fmt.Sprintf("Contract %s already exists at %s", contractName, report.CurrentAddress.Hex()))
+	case !isAdd && report.CurrentAddress == (common.Address{}):
+		report.Errors = append(report.Errors, fmt.Sprintf("Contract %s does not exist", contractName))
+	case !isAdd:
+		currentAbi, err = rp.GetABI(contractName, opts)
+		if err != nil {
+			return UpgradeReport{}, err
+		}
+	}
+	if isContract && contractAddress == report.CurrentAddress {
+		report.Errors = append(report.Errors, fmt.Sprintf("Contract %s is already at %s", contractName, contractAddress.Hex()))
+	}
+
+	// Compare the ABIs
+	if abiValid {
+		report.AbiDiff = rocketpool.DiffABIs(currentAbi, &newAbi)
+		report.BreakingChanges = report.AbiDiff.GetBreakingChanges(calledMethods)
+	}
+	return report, nil
+
+}
+
+// Check whether the upgrade can be proposed
+func (r UpgradeReport) IsValid() bool {
+	return len(r.Errors) == 0
+}
+
+// Get a one-line summary of the upgrade; this is the first line of the full report from String
+func (r UpgradeReport) GetProposalMessage() string {
+	message := fmt.Sprintf("%s %s", r.UpgradeType, r.ContractName)
+	if r.UpgradeType == "upgradeContract" || r.UpgradeType == "addContract" {
+		message += fmt.Sprintf(" at %s", r.ContractAddress.Hex())
+	}
+	message += fmt.Sprintf(" (%s", r.AbiDiff.Summary())
+	if len(r.BreakingChanges) > 0 {
+		message += fmt.Sprintf(", %d breaking", len(r.BreakingChanges))
+	}
+	return message + ")"
+}
+
+// Get the full human-readable report
+func (r UpgradeReport) String() string {
+	var report strings.Builder
+	fmt.Fprintf(&report, "%s\n", r.GetProposalMessage())
+	if r.CurrentAddress != (common.Address{}) {
+		fmt.Fprintf(&report, "Current address: %s\n", r.CurrentAddress.Hex())
+	}
+	if r.CodeSize > 0 {
+		fmt.Fprintf(&report, "Code size: %d bytes\n", r.CodeSize)
+	}
+	for _, section := range []struct {
+		name    string
+		changes []rocketpool.AbiChange
+	}{{"Breaking changes", r.BreakingChanges}, {"Methods", r.AbiDiff.Methods}, {"Events", r.AbiDiff.Events}} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&report, "%s:\n", section.name)
+		if section.name == "Breaking changes" && !r.CallsKnown {
+			fmt.Fprintf(&report, "  (no calls to %s were recorded, so every removed or changed method is listed)\n", r.ContractName)
+		}
+		for _, change := range section.changes {
+			switch change.Kind {
+			case rocketpool.AbiChangeAdded:
+				fmt.Fprintf(&report, "  + %s\n", change.NewSignature)
+			case rocketpool.AbiChangeRemoved:
+				fmt.Fprintf(&report, "  - %s\n", change.OldSignature)
+			case rocketpool.AbiChangeChanged:
+				fmt.Fprintf(&report, "  ~ %s -> %s\n", change.OldSignature, change.NewSignature)
+			}
+		}
+	}
+	if len(r.Errors) > 0 {
+		fmt.Fprintf(&report, "Errors:\n")
+		for _, err := range r.Errors {
+			fmt.Fprintf(&report, "  %s\n", err)
+		}
+	}
+	return report.String()
+}
+
+// Estimate the gas of ProposeCheckedUpgrade
+func EstimateProposeCheckedUpgradeGas(rp *rocketpool.RocketPool, report UpgradeReport, contractAbi string, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
+	if !report.IsValid() {
+		return rocketpool.GasInfo{}, fmt.Errorf("Could not propose %s upgrade: %s", report.ContractName, strings.Join(report.Errors, "; "))
+	}
+	return EstimateProposeUpgradeContractGas(rp, report.String(), report.UpgradeType, report.ContractName, contractAbi, report.ContractAddress, opts)
+}
+
+// Submit a proposal for a checked upgrade, with the full report from String as its message so members can review the ABI changes before voting
+func ProposeCheckedUpgrade(rp *rocketpool.RocketPool, report UpgradeReport, contractAbi string, opts *bind.TransactOpts) (uint64, common.Hash, error) {
+	if !report.IsValid() {
+		return 0, common.Hash{}, fmt.Errorf("Could not propose %s upgrade: %s", report.ContractName, strings.Join(report.Errors, "; "))
+	}
+	return ProposeUpgradeContract(rp, report.String(), report.UpgradeType, report.ContractName, contractAbi, report.ContractAddress, opts)
+}
+
+// Bootstrap a checked upgrade
+func BootstrapCheckedUpgrade(rp *rocketpool.RocketPool, report UpgradeReport, contractAbi string, opts *bind.TransactOpts) (common.Hash, error) {
+	if !report.IsValid() {
+		return common.Hash{}, fmt.Errorf("Could not bootstrap %s upgrade: %s", report.ContractName, strings.Join(report.Errors, "; "))
+	}
+	return BootstrapUpgrade(rp, report.UpgradeType, report.ContractName, contractAbi, report.ContractAddress, opts)
+}
@@ -0,0 +1,179 @@
+package trustednode
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/dao"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+// A member's voting record
+type MemberVotingStats struct {
+	Address           common.Address `json:"address"`
+	ID                string         `json:"id"`
+	JoinedTime        time.Time      `json:"joinedTime"`
+	EligibleProposals int            `json:"eligibleProposals"` // Proposals created after the member joined that have opened for voting
+	VotesCast         int            `json:"votesCast"`
+	VotesFor          int            `json:"votesFor"`
+	VotesAgainst      int            `json:"votesAgainst"`
+	MissedProposals   []uint64       `json:"missedProposals"` // Eligible proposals whose voting closed without a vote from the member
+	ParticipationRate float64        `json:"participationRate"`
+	LastVoteTime      time.Time      `json:"lastVoteTime"` // Zero if the member has never voted
+	Inactive          bool           `json:"inactive"`
+}
+
+// The voting history of the oracle DAO
+type VotingReport struct {
+	Time             time.Time             `json:"time"`
+	Since            time.Time             `json:"since"` // Proposals created before this time are left out, since their votes may not have been loaded
+	InactivityPeriod time.Duration         `json:"inactivityPeriod"`
+	Votes            []dao.ProposalVote    `json:"votes"`
+	Members          []MemberVotingStats   `json:"members"`
+	ExpiredProposals []dao.ProposalDetails `json:"expiredProposals"` // Proposals that passed but were never executed
+	InactiveMembers  []common.Address      `json:"inactiveMembers"`
+}
+
+// Get the voting history of the oracle DAO from vote events since fromBlock; proposals created before fromBlock are left out.
+// Members are inactive if they have missed a vote and haven't voted, or joined, within the inactivity period.
+func GetVotingReport(rp *rocketpool.RocketPool, inactivityPeriod time.Duration, now time.Time, fromBlock *big.Int, intervalSize *big.Int, opts *bind.CallOpts) (VotingReport, error) {
+
+	// Data
+	var wg errgroup.Group
+	var members []MemberDetails
+	var proposals []dao.ProposalDetails
+	var votes []dao.ProposalVote
+	var since time.Time
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		members, err = GetMembers(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		proposals, err = dao.GetDAOProposals(rp, "poolseaDAONodeTrustedProposals", opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		votes, err = dao.GetProposalVotes(rp, fromBlock, intervalSize, opts)
+		return err
+	})
+	if fromBlock != nil {
+		wg.Go(func() error {
+			header, err := rp.Client.HeaderByNumber(context.Background(), fromBlock)
+			if err == nil {
+				since = time.Unix(int64(header.Time), 0)
+			}
+			return err
+		})
+	}
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return VotingReport{}, fmt.Errorf("Could not get oracle DAO voting history: %w", err)
+	}
+	return BuildVotingReport(members, proposals, votes, since, inactivityPeriod, now), nil
+
+}
+
+// Build the voting report for the provided members and oracle DAO proposals created at or after since.
+// Votes on proposals from other DAOs or from before since are ignored; since can be zero to include every proposal.
+func BuildVotingReport(members []MemberDetails, proposals []dao.ProposalDetails, votes []dao.ProposalVote, since time.Time, inactivityPeriod time.Duration, now time.Time) VotingReport {
+
+	report := VotingReport{
+		Time:             now,
+		Since:            since,
+		InactivityPeriod: inactivityPeriod,
+		Votes:            []dao.ProposalVote{},
+		Members:          make([]MemberVotingStats, len(members)),
+		ExpiredProposals: []dao.ProposalDetails{},
+		InactiveMembers:  []common.Address{},
+	}
+
+	// Index the proposals and votes
+	proposalMap := make(map[uint64]dao.ProposalDetails, len(proposals))
+	includedProposals := make([]dao.ProposalDetails, 0, len(proposals))
+	for _, proposal := range proposals {
+		if time.Unix(int64(proposal.CreatedTime), 0).Before(since) {
+			continue
+		}
+		proposalMap[proposal.ID] = proposal
+		includedProposals = append(includedProposals, proposal)
+		if proposal.State == rptypes.Expired {
+			report.ExpiredProposals = append(report.ExpiredProposals, proposal)
+		}
+	}
+	memberVotes := map[common.Address]map[uint64]dao.ProposalVote{}
+	for _, vote := range votes {
+		if _, exists := proposalMap[vote.ProposalID]; !exists {
+			continue
+		}
+		report.Votes = append(report.Votes, vote)
+		if memberVotes[vote.Voter] == nil {
+			memberVotes[vote.Voter] = map[uint64]dao.ProposalVote{}
+		}
+		memberVotes[vote.Voter][vote.ProposalID] = vote
+	}
+
+	// Get each member's stats
+	for i, member := range members {
+		stats := MemberVotingStats{
+			Address:         member.Address,
+			ID:              member.ID,
+			JoinedTime:      time.Unix(int64(member.JoinedTime), 0),
+			MissedProposals: []uint64{},
+		}
+		for _, proposal := range includedProposals {
+
+			// Members can only vote on proposals created after they joined
+			if proposal.IsCancelled || member.JoinedTime >= proposal.CreatedTime || now.Before(time.Unix(int64(proposal.StartTime), 0)) {
+				continue
+			}
+			stats.EligibleProposals++
+
+			vote, voted := memberVotes[member.Address][proposal.ID]
+			if !voted {
+				if proposal.State != rptypes.Pending && proposal.State != rptypes.Active {
+					stats.MissedProposals = append(stats.MissedProposals, proposal.ID)
+				}
+				continue
+			}
+			stats.VotesCast++
+			if vote.Supported {
+				stats.VotesFor++
+			} else {
+				stats.VotesAgainst++
+			}
+			if vote.Time.After(stats.LastVoteTime) {
+				stats.LastVoteTime = vote.Time
+			}
+
+		}
+		if stats.EligibleProposals > 0 {
+			stats.ParticipationRate = float64(stats.VotesCast) / float64(stats.EligibleProposals)
+		}
+
+		// Check for inactivity
+		lastActivity := stats.JoinedTime
+		if stats.LastVoteTime.After(lastActivity) {
+			lastActivity = stats.LastVoteTime
+		}
+		stats.Inactive = len(stats.MissedProposals) > 0 && now.Sub(lastActivity) >= inactivityPeriod
+		if stats.Inactive {
+			report.InactiveMembers = append(report.InactiveMembers, member.Address)
+		}
+		report.Members[i] = stats
+	}
+	return report
+
+}
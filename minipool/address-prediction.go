@@ -0,0 +1,225 @@
+package minipool
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/utils"
+)
+
+// Settings
+const (
+	AddressPredictionBatchSize   = 100
+	AddressPredictionThreadLimit = 10
+)
+
+// The EIP-1167 minimal proxy creation code that the minipool factory deploys, around the delegate address
+var (
+	cloneInitCodePrefix = common.FromHex("0x3d602d80600a3d3981f3363d3d373d3d3d363d73")
+	cloneInitCodeSuffix = common.FromHex("0x5af43d82803e903d91602b57fd5bf3")
+)
+
+// A predicted minipool address and the salt that produces it
+type PredictedMinipoolAddress struct {
+	Salt    *big.Int       `json:"salt"`
+	Address common.Address `json:"address"`
+}
+
+// Computes minipool addresses without calling the minipool factory
+type AddressPredictor struct {
+	FactoryAddress      common.Address
+	MinipoolBaseAddress common.Address
+	InitCodeHash        common.Hash
+}
+
+// Create an address predictor from the current minipool factory and minipool base contracts
+func NewAddressPredictor(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*AddressPredictor, error) {
+	factoryAddress, err := rp.GetAddress("poolseaMinipoolFactory", opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not get minipool factory address: %w", err)
+	}
+	minipoolBaseAddress, err := rp.GetAddress("poolseaMinipoolBase", opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not get minipool base address: %w", err)
+	}
+	return &AddressPredictor{
+		FactoryAddress:      *factoryAddress,
+		MinipoolBaseAddress: *minipoolBaseAddress,
+		InitCodeHash:        GetMinipoolInitCodeHash(*minipoolBaseAddress),
+	}, nil
+}
+
+// Get the hash of the creation code for a minipool clone of the provided minipool base
+func GetMinipoolInitCodeHash(minipoolBaseAddress common.Address) common.Hash {
+	return crypto.Keccak256Hash(cloneInitCodePrefix, minipoolBaseAddress.Bytes(), cloneInitCodeSuffix)
+}
+
+// Get the address of a minipool based on the node address and a salt
+func (p *AddressPredictor) GetExpectedAddress(nodeAddress common.Address, salt *big.Int) common.Address {
+	return crypto.CreateAddress2(p.FactoryAddress, utils.GetNodeSalt(nodeAddress, salt), p.InitCodeHash.Bytes())
+}
+
+// Get the addresses of the minipools created with consecutive salts, starting from startSalt
+func (p *AddressPredictor) GetExpectedAddresses(nodeAddress common.Address, startSalt *big.Int, count uint64) []PredictedMinipoolAddress {
+	addresses := make([]PredictedMinipoolAddress, count)
+	salt := new(big.Int).Set(startSalt)
+	for i := range addresses {
+		addresses[i] = PredictedMinipoolAddress{
+			Salt:    new(big.Int).Set(salt),
+			Address: p.GetExpectedAddress(nodeAddress, salt),
+		}
+		salt.Add(salt, big.NewInt(1))
+	}
+	return addresses
+}
+
+// Get the next available minipool addresses for a node, starting from startSalt.
+// Salts whose minipool has already been created are skipped.
+func (p *AddressPredictor) GetNextAvailableAddresses(rp *rocketpool.RocketPool, nodeAddress common.Address, startSalt *big.Int, count uint64, opts *bind.CallOpts) ([]PredictedMinipoolAddress, error) {
+
+	available := make([]PredictedMinipoolAddress, 0, count)
+	salt := new(big.Int).Set(startSalt)
+	for uint64(len(available)) < count {
+
+		// Check a batch of candidates
+		candidates := p.GetExpectedAddresses(nodeAddress, salt, AddressPredictionBatchSize)
+		exists := make([]bool, len(candidates))
+		var wg errgroup.Group
+		wg.SetLimit(AddressPredictionThreadLimit)
+		for i := range candidates {
+			i := i
+			wg.Go(func() error {
+				var err error
+				exists[i], err = GetMinipoolExists(rp, candidates[i].Address, opts)
+				return err
+			})
+		}
+		if err := wg.Wait(); err != nil {
+			return nil, fmt.Errorf("Could not check if predicted minipools exist: %w", err)
+		}
+
+		// Add the available ones
+		for i, candidate := range candidates {
+			if !exists[i] && uint64(len(available)) < count {
+				available = append(available, candidate)
+			}
+		}
+		salt.Add(salt, big.NewInt(AddressPredictionBatchSize))
+
+	}
+	return available, nil
+
+}
+
+// Check the offline prediction against the minipool factory's getExpectedAddress for a node and salt
+func (p *AddressPredictor) Verify(rp *rocketpool.RocketPool, nodeAddress common.Address, salt *big.Int, opts *bind.CallOpts) error {
+	expectedAddress, err := GetExpectedAddress(rp, nodeAddress, salt, opts)
+	if err != nil {
+		return err
+	}
+	if predictedAddress := p.GetExpectedAddress(nodeAddress, salt); predictedAddress != expectedAddress {
+		return fmt.Errorf("Predicted minipool address %s for salt %s does not match the minipool factory's address %s", predictedAddress.Hex(), salt.String(), expectedAddress.Hex())
+	}
+	return nil
+}
+
+// Search for a salt that gives a minipool address starting with the provided hex prefix, e.g. "0xbeef".
+// The search runs on the provided number of threads (or one per CPU if threads is 0), starting from startSalt, until a match is found or the context is cancelled.
+func (p *AddressPredictor) SearchVanitySalt(ctx context.Context, nodeAddress common.Address, prefix string, startSalt *big.Int, threads int) (PredictedMinipoolAddress, error) {
+
+	// Parse the prefix
+	nibbles, err := parseAddressPrefix(prefix)
+	if err != nil {
+		return PredictedMinipoolAddress{}, err
+	}
+	if threads <= 0 {
+		threads = runtime.NumCPU()
+	}
+
+	// Search in parallel, with each thread checking every nth salt
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var result PredictedMinipoolAddress
+	var resultLock sync.Mutex
+	var wg sync.WaitGroup
+	for t := 0; t < threads; t++ {
+		salt := new(big.Int).Add(startSalt, big.NewInt(int64(t)))
+		step := big.NewInt(int64(threads))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				address := p.GetExpectedAddress(nodeAddress, salt)
+				if hasAddressPrefix(address, nibbles) {
+					resultLock.Lock()
+					if result.Salt == nil || salt.Cmp(result.Salt) < 0 {
+						result = PredictedMinipoolAddress{
+							Salt:    new(big.Int).Set(salt),
+							Address: address,
+						}
+					}
+					resultLock.Unlock()
+					cancel()
+					return
+				}
+				salt.Add(salt, step)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if result.Salt == nil {
+		return PredictedMinipoolAddress{}, fmt.Errorf("Could not find a salt for prefix %s: %w", prefix, ctx.Err())
+	}
+	return result, nil
+
+}
+
+// Parse a hex address prefix into nibbles
+func parseAddressPrefix(prefix string) ([]byte, error) {
+	prefix = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(prefix, "0x"), "0X"))
+	if len(prefix) > common.AddressLength*2 {
+		return nil, fmt.Errorf("Prefix %s is longer than an address", prefix)
+	}
+	nibbles := make([]byte, len(prefix))
+	for i := range prefix {
+		value, err := hex.DecodeString("0" + prefix[i:i+1])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid address prefix %s: %w", prefix, err)
+		}
+		nibbles[i] = value[0]
+	}
+	return nibbles, nil
+}
+
+// Check if an address starts with the provided nibbles
+func hasAddressPrefix(address common.Address, nibbles []byte) bool {
+	for i, nibble := range nibbles {
+		b := address[i/2]
+		if i%2 == 0 {
+			b >>= 4
+		} else {
+			b &= 0x0f
+		}
+		if b != nibble {
+			return false
+		}
+	}
+	return true
+}
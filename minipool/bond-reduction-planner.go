@@ -0,0 +1,420 @@
+package minipool
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/network"
+	"github.com/Seb369888/poolsea-go/node"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/protocol"
+)
+
+// The node details that limit how much its minipools can reduce their bonds by
+type BondReductionNodeState struct {
+	NodeAddress             common.Address `json:"nodeAddress"`
+	RplStake                *big.Int       `json:"rplStake"`
+	RplPrice                *big.Int       `json:"rplPrice"`
+	MinimumPerMinipoolStake *big.Int       `json:"minimumPerMinipoolStake"` // Where 1e18 = 100%
+	EthMatched              *big.Int       `json:"ethMatched"`
+	DepositAmounts          []*big.Int     `json:"depositAmounts"` // The bond amounts the node deposit contract accepts
+}
+
+// The minipool details needed to plan a bond reduction
+type BondReductionMinipoolState struct {
+	Address            common.Address `json:"address"`
+	Lifecycle          LifecycleState `json:"lifecycle"`
+	NodeDepositBalance *big.Int       `json:"nodeDepositBalance"`
+	ReduceBondValue    *big.Int       `json:"reduceBondValue"`
+}
+
+// A transaction to run as part of a bond reduction, and the window it must be run in
+type BondReductionStep struct {
+	MinipoolAddress common.Address `json:"minipoolAddress"`
+	Action          MinipoolAction `json:"action"`
+	NewBondAmount   *big.Int       `json:"newBondAmount"`
+	NotBefore       time.Time      `json:"notBefore"`
+	NotAfter        time.Time      `json:"notAfter"` // Zero if the step doesn't expire
+}
+
+// The bond reduction plan for a single minipool
+type BondReductionPlan struct {
+	MinipoolAddress  common.Address      `json:"minipoolAddress"`
+	CurrentBond      *big.Int            `json:"currentBond"`
+	NewBond          *big.Int            `json:"newBond"`
+	DepositCredit    *big.Int            `json:"depositCredit"`
+	Eligible         bool                `json:"eligible"`
+	IneligibleReason string              `json:"ineligibleReason,omitempty"`
+	InProgress       bool                `json:"inProgress"`
+	BeginTime        time.Time           `json:"beginTime"`
+	WindowStart      time.Time           `json:"windowStart"`
+	WindowEnd        time.Time           `json:"windowEnd"`
+	Steps            []BondReductionStep `json:"steps"`
+}
+
+// The bond reduction plan for all of a node's minipools
+type NodeBondReductionPlan struct {
+	Node               BondReductionNodeState `json:"node"`
+	Settings           LifecycleSettings      `json:"settings"`
+	Minipools          []BondReductionPlan    `json:"minipools"`
+	Steps              []BondReductionStep    `json:"steps"`
+	EthMatchedAfter    *big.Int               `json:"ethMatchedAfter"`
+	EthMatchedLimit    *big.Int               `json:"ethMatchedLimit"`
+	RequiredRplStake   *big.Int               `json:"requiredRplStake"`
+	RplShortfall       *big.Int               `json:"rplShortfall"`
+	TotalDepositCredit *big.Int               `json:"totalDepositCredit"`
+}
+
+// Get the bond reduction plan for all of a node's minipools, reducing each of their bonds to newBondAmount
+func GetNodeBondReductionPlan(rp *rocketpool.RocketPool, nodeAddress common.Address, newBondAmount *big.Int, now time.Time, opts *bind.CallOpts) (NodeBondReductionPlan, error) {
+
+	// Get the node's minipools
+	addresses, err := GetNodeMinipoolAddresses(rp, nodeAddress, opts)
+	if err != nil {
+		return NodeBondReductionPlan{}, err
+	}
+
+	// Data
+	var wg errgroup.Group
+	var nodeState BondReductionNodeState
+	var settings LifecycleSettings
+	minipools := make([]BondReductionMinipoolState, len(addresses))
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		nodeState, err = GetBondReductionNodeState(rp, nodeAddress, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		settings, err = GetLifecycleSettings(rp, opts)
+		return err
+	})
+	for i, address := range addresses {
+		i, address := i, address
+		wg.Go(func() error {
+			mp, err := NewMinipool(rp, address, opts)
+			if err != nil {
+				return err
+			}
+			minipools[i], err = GetBondReductionMinipoolState(rp, mp, opts)
+			return err
+		})
+	}
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return NodeBondReductionPlan{}, fmt.Errorf("Could not get bond reduction details for node %s: %w", nodeAddress.Hex(), err)
+	}
+	return PlanBondReductions(nodeState, minipools, settings, newBondAmount, now), nil
+
+}
+
+// Get the node details that limit how much its minipools can reduce their bonds by
+func GetBondReductionNodeState(rp *rocketpool.RocketPool, nodeAddress common.Address, opts *bind.CallOpts) (BondReductionNodeState, error) {
+
+	// Data
+	var wg errgroup.Group
+	state := BondReductionNodeState{
+		NodeAddress: nodeAddress,
+	}
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		state.RplStake, err = node.GetNodeRPLStake(rp, nodeAddress, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.RplPrice, err = network.GetRPLPrice(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.MinimumPerMinipoolStake, err = protocol.GetMinimumPerMinipoolStakeRaw(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.EthMatched, err = node.GetNodeEthMatched(rp, nodeAddress, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.DepositAmounts, err = node.GetDepositAmounts(rp, opts)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return BondReductionNodeState{}, err
+	}
+	return state, nil
+
+}
+
+// Get the minipool details needed to plan a bond reduction
+func GetBondReductionMinipoolState(rp *rocketpool.RocketPool, mp Minipool, opts *bind.CallOpts) (BondReductionMinipoolState, error) {
+
+	// Data
+	var wg errgroup.Group
+	state := BondReductionMinipoolState{
+		Address: mp.GetAddress(),
+	}
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		state.Lifecycle, err = GetLifecycleState(rp, mp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.NodeDepositBalance, err = mp.GetNodeDepositBalance(opts)
+		return err
+	})
+	if mp.GetVersion() >= 3 {
+		wg.Go(func() error {
+			var err error
+			state.ReduceBondValue, err = GetReduceBondValue(rp, mp.GetAddress(), opts)
+			return err
+		})
+	}
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return BondReductionMinipoolState{}, err
+	}
+	return state, nil
+
+}
+
+// Plan the bond reductions for a node's minipools at the given time.
+// Reductions that are already in progress are planned first, then new reductions in the order the minipools are provided,
+// until the node's ETH matched limit is reached. Reductions in progress that exceed the limit are kept in the plan but blocked,
+// since completing them would revert until more RPL is staked.
+func PlanBondReductions(nodeState BondReductionNodeState, minipools []BondReductionMinipoolState, settings LifecycleSettings, newBondAmount *big.Int, now time.Time) NodeBondReductionPlan {
+
+	plan := NodeBondReductionPlan{
+		Node:               nodeState,
+		Settings:           settings,
+		Minipools:          make([]BondReductionPlan, len(minipools)),
+		Steps:              []BondReductionStep{},
+		EthMatchedAfter:    new(big.Int).Set(nodeState.EthMatched),
+		EthMatchedLimit:    node.GetEthMatchedLimit(nodeState.RplStake, nodeState.RplPrice, nodeState.MinimumPerMinipoolStake),
+		TotalDepositCredit: big.NewInt(0),
+	}
+
+	// Plan the reductions in progress before new ones, since they've already been approved
+	order := make([]int, 0, len(minipools))
+	for i, mp := range minipools {
+		if isReductionInProgress(mp, settings, now) {
+			order = append(order, i)
+		}
+	}
+	for i, mp := range minipools {
+		if !isReductionInProgress(mp, settings, now) {
+			order = append(order, i)
+		}
+	}
+
+	// Plan each minipool
+	requiredEthMatched := new(big.Int).Set(nodeState.EthMatched)
+	for _, i := range order {
+		mpPlan := planBondReduction(minipools[i], settings, newBondAmount, nodeState.DepositAmounts, now)
+		if mpPlan.Eligible {
+			requiredEthMatched.Add(requiredEthMatched, mpPlan.DepositCredit)
+			if requiredEthMatched.Cmp(plan.EthMatchedLimit) > 0 {
+				// Not enough RPL staked to cover the additional ETH matched
+				mpPlan.Eligible = false
+				if mpPlan.InProgress {
+					mpPlan.IneligibleReason = "insufficient RPL stake to complete the reduction in progress"
+				} else {
+					mpPlan.IneligibleReason = "insufficient RPL stake for the additional ETH matched"
+					mpPlan.Steps = []BondReductionStep{}
+				}
+			} else {
+				plan.EthMatchedAfter.Add(plan.EthMatchedAfter, mpPlan.DepositCredit)
+				plan.TotalDepositCredit.Add(plan.TotalDepositCredit, mpPlan.DepositCredit)
+				plan.Steps = append(plan.Steps, mpPlan.Steps...)
+			}
+		}
+		plan.Minipools[i] = mpPlan
+	}
+
+	// Get the RPL stake required to reduce every eligible minipool
+	plan.RequiredRplStake = node.GetRequiredRplStake(requiredEthMatched, nodeState.RplPrice, nodeState.MinimumPerMinipoolStake)
+	plan.RplShortfall = new(big.Int).Sub(plan.RequiredRplStake, nodeState.RplStake)
+	if plan.RplShortfall.Sign() < 0 {
+		plan.RplShortfall.SetUint64(0)
+	}
+
+	// Order the steps by when they can be run
+	sort.SliceStable(plan.Steps, func(i, j int) bool {
+		return plan.Steps[i].NotBefore.Before(plan.Steps[j].NotBefore)
+	})
+	return plan
+
+}
+
+// Run a bond reduction step
+func ExecuteBondReductionStep(rp *rocketpool.RocketPool, step BondReductionStep, opts *bind.TransactOpts) (common.Hash, error) {
+	switch step.Action {
+	case MinipoolActionBeginReduceBondAmount:
+		return BeginReduceBondAmount(rp, step.MinipoolAddress, step.NewBondAmount, opts)
+	case MinipoolActionReduceBondAmount:
+		mpv3, err := getBondReductionMinipool(rp, step.MinipoolAddress)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		return mpv3.ReduceBondAmount(opts)
+	}
+	return common.Hash{}, fmt.Errorf("Unknown bond reduction action %s", step.Action)
+}
+
+// Estimate the gas of a bond reduction step
+func EstimateBondReductionStepGas(rp *rocketpool.RocketPool, step BondReductionStep, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
+	switch step.Action {
+	case MinipoolActionBeginReduceBondAmount:
+		return EstimateBeginReduceBondAmountGas(rp, step.MinipoolAddress, step.NewBondAmount, opts)
+	case MinipoolActionReduceBondAmount:
+		mpv3, err := getBondReductionMinipool(rp, step.MinipoolAddress)
+		if err != nil {
+			return rocketpool.GasInfo{}, err
+		}
+		return mpv3.EstimateReduceBondAmountGas(opts)
+	}
+	return rocketpool.GasInfo{}, fmt.Errorf("Unknown bond reduction action %s", step.Action)
+}
+
+// Plan the bond reduction for a single minipool, ignoring the node's RPL stake
+func planBondReduction(mp BondReductionMinipoolState, settings LifecycleSettings, newBondAmount *big.Int, depositAmounts []*big.Int, now time.Time) BondReductionPlan {
+
+	plan := BondReductionPlan{
+		MinipoolAddress: mp.Address,
+		CurrentBond:     mp.NodeDepositBalance,
+		NewBond:         newBondAmount,
+		DepositCredit:   big.NewInt(0),
+		Steps:           []BondReductionStep{},
+	}
+	if mp.Lifecycle.ReduceBondCancelled {
+		plan.IneligibleReason = "bond reduction was cancelled by the Oracle DAO"
+		return plan
+	}
+	lifecycle := EvaluateLifecycle(mp.Lifecycle, settings, now)
+
+	// Finish a reduction that's already in progress
+	if isReductionInProgress(mp, settings, now) {
+		reduce, _ := lifecycle.GetAction(MinipoolActionReduceBondAmount)
+		plan.Eligible = true
+		plan.InProgress = true
+		plan.NewBond = mp.ReduceBondValue
+		plan.DepositCredit.Sub(mp.NodeDepositBalance, mp.ReduceBondValue)
+		plan.setWindow(mp.Lifecycle.ReduceBondTime, settings)
+		plan.Steps = append(plan.Steps, plan.getReduceStep(reduce, now))
+		return plan
+	}
+
+	// Check if a new reduction can be started
+	begin, _ := lifecycle.GetAction(MinipoolActionBeginReduceBondAmount)
+	if !begin.Allowed && begin.UnlockTime.IsZero() {
+		plan.IneligibleReason = begin.BlockedReason
+		return plan
+	}
+	if newBondAmount.Sign() <= 0 || newBondAmount.Cmp(mp.NodeDepositBalance) >= 0 {
+		plan.IneligibleReason = fmt.Sprintf("bond of %s is not above the new bond amount", mp.NodeDepositBalance.String())
+		return plan
+	}
+	if !isValidDepositAmount(newBondAmount, depositAmounts) {
+		plan.IneligibleReason = fmt.Sprintf("new bond amount %s is not a deposit amount the network accepts", newBondAmount.String())
+		return plan
+	}
+
+	// Schedule it
+	beginTime := now
+	if !begin.Allowed {
+		beginTime = begin.UnlockTime
+	}
+	plan.Eligible = true
+	plan.DepositCredit.Sub(mp.NodeDepositBalance, newBondAmount)
+	plan.setWindow(beginTime, settings)
+	plan.Steps = append(plan.Steps,
+		BondReductionStep{
+			MinipoolAddress: mp.Address,
+			Action:          MinipoolActionBeginReduceBondAmount,
+			NewBondAmount:   newBondAmount,
+			NotBefore:       beginTime,
+		},
+		BondReductionStep{
+			MinipoolAddress: mp.Address,
+			Action:          MinipoolActionReduceBondAmount,
+			NewBondAmount:   newBondAmount,
+			NotBefore:       plan.WindowStart,
+			NotAfter:        plan.WindowEnd,
+		},
+	)
+	return plan
+
+}
+
+// Set the reduction window for a reduction that begins at the given time
+func (p *BondReductionPlan) setWindow(beginTime time.Time, settings LifecycleSettings) {
+	p.BeginTime = beginTime
+	p.WindowStart = beginTime.Add(settings.BondReductionWindowStart)
+	p.WindowEnd = p.WindowStart.Add(settings.BondReductionWindowLength)
+}
+
+// Get the step that completes a reduction in progress
+func (p *BondReductionPlan) getReduceStep(reduce ActionStatus, now time.Time) BondReductionStep {
+	notBefore := now
+	if !reduce.Allowed {
+		notBefore = reduce.UnlockTime
+	}
+	return BondReductionStep{
+		MinipoolAddress: p.MinipoolAddress,
+		Action:          MinipoolActionReduceBondAmount,
+		NewBondAmount:   p.NewBond,
+		NotBefore:       notBefore,
+		NotAfter:        p.WindowEnd,
+	}
+}
+
+// Check if a minipool has a reduction in progress that can still be completed
+func isReductionInProgress(mp BondReductionMinipoolState, settings LifecycleSettings, now time.Time) bool {
+	if mp.ReduceBondValue == nil || mp.ReduceBondValue.Sign() == 0 {
+		return false
+	}
+	reduce := evaluateAction(MinipoolActionReduceBondAmount, mp.Lifecycle, settings, now)
+	return reduce.Allowed || !reduce.UnlockTime.IsZero()
+}
+
+// Check if a bond amount is one of the deposit amounts the node deposit contract accepts
+func isValidDepositAmount(amount *big.Int, depositAmounts []*big.Int) bool {
+	for _, depositAmount := range depositAmounts {
+		if depositAmount.Cmp(amount) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Get a minipool that supports bond reductions
+func getBondReductionMinipool(rp *rocketpool.RocketPool, minipoolAddress common.Address) (MinipoolV3, error) {
+	mp, err := NewMinipool(rp, minipoolAddress, nil)
+	if err != nil {
+		return nil, err
+	}
+	mpv3, ok := GetMinipoolAsV3(mp)
+	if !ok {
+		return nil, fmt.Errorf("Minipool %s is version %d and does not support bond reduction", minipoolAddress.Hex(), mp.GetVersion())
+	}
+	return mpv3, nil
+}
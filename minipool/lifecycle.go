@@ -0,0 +1,419 @@
+package minipool
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/protocol"
+	"github.com/Seb369888/poolsea-go/settings/trustednode"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+// An operation that can be performed on a minipool
+type MinipoolAction string
+
+const (
+	MinipoolActionStake                 MinipoolAction = "stake"
+	MinipoolActionPromote               MinipoolAction = "promote"
+	MinipoolActionDissolve              MinipoolAction = "dissolve"
+	MinipoolActionClose                 MinipoolAction = "close"
+	MinipoolActionFinalise              MinipoolAction = "finalise"
+	MinipoolActionDistributeBalance     MinipoolAction = "distributeBalance"
+	MinipoolActionBeginReduceBondAmount MinipoolAction = "beginReduceBondAmount"
+	MinipoolActionReduceBondAmount      MinipoolAction = "reduceBondAmount"
+)
+
+// All of the actions the lifecycle model evaluates
+var MinipoolActions = []MinipoolAction{
+	MinipoolActionStake,
+	MinipoolActionPromote,
+	MinipoolActionDissolve,
+	MinipoolActionClose,
+	MinipoolActionFinalise,
+	MinipoolActionDistributeBalance,
+	MinipoolActionBeginReduceBondAmount,
+	MinipoolActionReduceBondAmount,
+}
+
+// Whether or not an action can currently be performed on a minipool.
+// If it can't, BlockedReason explains why; UnlockTime is the time it becomes available if it only requires waiting.
+type ActionStatus struct {
+	Action        MinipoolAction `json:"action"`
+	Allowed       bool           `json:"allowed"`
+	BlockedReason string         `json:"blockedReason,omitempty"`
+	UnlockTime    time.Time      `json:"unlockTime"`
+}
+
+// The network settings that govern the minipool lifecycle
+type LifecycleSettings struct {
+	ScrubPeriod               time.Duration `json:"scrubPeriod"`
+	PromotionScrubPeriod      time.Duration `json:"promotionScrubPeriod"`
+	LaunchTimeout             time.Duration `json:"launchTimeout"`
+	BondReductionEnabled      bool          `json:"bondReductionEnabled"`
+	BondReductionWindowStart  time.Duration `json:"bondReductionWindowStart"`
+	BondReductionWindowLength time.Duration `json:"bondReductionWindowLength"`
+}
+
+// The minipool state that governs its lifecycle
+type LifecycleState struct {
+	Version             uint8                  `json:"version"`
+	Status              rptypes.MinipoolStatus `json:"status"`
+	StatusTime          time.Time              `json:"statusTime"`
+	Finalised           bool                   `json:"finalised"`
+	IsVacant            bool                   `json:"isVacant"`
+	UserDistributed     bool                   `json:"userDistributed"`
+	ReduceBondTime      time.Time              `json:"reduceBondTime"`
+	ReduceBondCancelled bool                   `json:"reduceBondCancelled"`
+}
+
+// A minipool's lifecycle state and the status of every action at a point in time
+type Lifecycle struct {
+	State    LifecycleState    `json:"state"`
+	Settings LifecycleSettings `json:"settings"`
+	Time     time.Time         `json:"time"`
+	Actions  []ActionStatus    `json:"actions"`
+}
+
+// Get the status of an action
+func (l Lifecycle) GetAction(action MinipoolAction) (ActionStatus, bool) {
+	for _, status := range l.Actions {
+		if status.Action == action {
+			return status, true
+		}
+	}
+	return ActionStatus{}, false
+}
+
+// Check if an action can currently be performed
+func (l Lifecycle) IsAllowed(action MinipoolAction) bool {
+	status, exists := l.GetAction(action)
+	return exists && status.Allowed
+}
+
+// Get the actions that can currently be performed
+func (l Lifecycle) GetAllowedActions() []MinipoolAction {
+	actions := []MinipoolAction{}
+	for _, status := range l.Actions {
+		if status.Allowed {
+			actions = append(actions, status.Action)
+		}
+	}
+	return actions
+}
+
+// Get the network settings that govern the minipool lifecycle
+func GetLifecycleSettings(rp *rocketpool.RocketPool, opts *bind.CallOpts) (LifecycleSettings, error) {
+
+	// Data
+	var wg errgroup.Group
+	var settings LifecycleSettings
+
+	// Load data
+	wg.Go(func() error {
+		scrubPeriod, err := trustednode.GetScrubPeriod(rp, opts)
+		settings.ScrubPeriod = time.Duration(scrubPeriod) * time.Second
+		return err
+	})
+	wg.Go(func() error {
+		promotionScrubPeriod, err := trustednode.GetPromotionScrubPeriod(rp, opts)
+		settings.PromotionScrubPeriod = time.Duration(promotionScrubPeriod) * time.Second
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		settings.LaunchTimeout, err = protocol.GetMinipoolLaunchTimeout(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		settings.BondReductionEnabled, err = protocol.GetBondReductionEnabled(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		windowStart, err := trustednode.GetBondReductionWindowStart(rp, opts)
+		settings.BondReductionWindowStart = time.Duration(windowStart) * time.Second
+		return err
+	})
+	wg.Go(func() error {
+		windowLength, err := trustednode.GetBondReductionWindowLength(rp, opts)
+		settings.BondReductionWindowLength = time.Duration(windowLength) * time.Second
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return LifecycleSettings{}, fmt.Errorf("Could not get minipool lifecycle settings: %w", err)
+	}
+	return settings, nil
+
+}
+
+// Get the state of a minipool that governs its lifecycle
+func GetLifecycleState(rp *rocketpool.RocketPool, mp Minipool, opts *bind.CallOpts) (LifecycleState, error) {
+
+	// Data
+	var wg errgroup.Group
+	var statusDetails StatusDetails
+	state := LifecycleState{
+		Version: mp.GetVersion(),
+	}
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		statusDetails, err = mp.GetStatusDetails(opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.Finalised, err = mp.GetFinalised(opts)
+		return err
+	})
+	if mpv3, ok := GetMinipoolAsV3(mp); ok {
+		wg.Go(func() error {
+			var err error
+			state.UserDistributed, err = mpv3.GetUserDistributed(opts)
+			return err
+		})
+		wg.Go(func() error {
+			var err error
+			state.ReduceBondTime, err = GetReduceBondTime(rp, mp.GetAddress(), opts)
+			return err
+		})
+		wg.Go(func() error {
+			var err error
+			state.ReduceBondCancelled, err = GetReduceBondCancelled(rp, mp.GetAddress(), opts)
+			return err
+		})
+	}
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return LifecycleState{}, fmt.Errorf("Could not get minipool %s lifecycle state: %w", mp.GetAddress().Hex(), err)
+	}
+	state.Status = statusDetails.Status
+	state.StatusTime = statusDetails.StatusTime
+	state.IsVacant = statusDetails.IsVacant
+	return state, nil
+
+}
+
+// Get the lifecycle of a minipool at the given time
+func GetLifecycle(rp *rocketpool.RocketPool, mp Minipool, now time.Time, opts *bind.CallOpts) (Lifecycle, error) {
+
+	// Data
+	var wg errgroup.Group
+	var state LifecycleState
+	var settings LifecycleSettings
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		state, err = GetLifecycleState(rp, mp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		settings, err = GetLifecycleSettings(rp, opts)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return Lifecycle{}, err
+	}
+	return EvaluateLifecycle(state, settings, now), nil
+
+}
+
+// Evaluate which actions can be performed on a minipool at the given time
+func EvaluateLifecycle(state LifecycleState, settings LifecycleSettings, now time.Time) Lifecycle {
+	actions := make([]ActionStatus, len(MinipoolActions))
+	for i, action := range MinipoolActions {
+		actions[i] = evaluateAction(action, state, settings, now)
+	}
+	return Lifecycle{
+		State:    state,
+		Settings: settings,
+		Time:     now,
+		Actions:  actions,
+	}
+}
+
+// Evaluate a single action
+func evaluateAction(action MinipoolAction, state LifecycleState, settings LifecycleSettings, now time.Time) ActionStatus {
+	switch action {
+
+	// Prelaunch minipools can stake once the scrub period has passed
+	case MinipoolActionStake:
+		if state.Status != rptypes.Prelaunch {
+			return blocked(action, fmt.Sprintf("minipool is in %s status", state.Status.String()))
+		}
+		if state.IsVacant {
+			return blocked(action, "vacant minipools must be promoted instead of staked")
+		}
+		return waitUntil(action, state.StatusTime.Add(settings.ScrubPeriod), now, "scrub period has not passed")
+
+	// Vacant minipools can be promoted once the promotion scrub period has passed
+	case MinipoolActionPromote:
+		if state.Version < 3 {
+			return blocked(action, "minipool does not support promotion")
+		}
+		if !state.IsVacant {
+			return blocked(action, "minipool is not vacant")
+		}
+		if state.Status != rptypes.Prelaunch {
+			return blocked(action, fmt.Sprintf("minipool is in %s status", state.Status.String()))
+		}
+		return waitUntil(action, state.StatusTime.Add(settings.PromotionScrubPeriod), now, "promotion scrub period has not passed")
+
+	// Prelaunch minipools can be dissolved once they have timed out
+	case MinipoolActionDissolve:
+		if state.Status != rptypes.Prelaunch {
+			return blocked(action, fmt.Sprintf("minipool is in %s status", state.Status.String()))
+		}
+		unlockTime := state.StatusTime.Add(settings.LaunchTimeout)
+		if now.Before(unlockTime) {
+			return ActionStatus{
+				Action:        action,
+				BlockedReason: "launch timeout has not passed",
+				UnlockTime:    unlockTime,
+			}
+		}
+		return allowed(action)
+
+	// Dissolved minipools can be closed
+	case MinipoolActionClose:
+		if state.Status != rptypes.Dissolved {
+			return blocked(action, "minipool is not dissolved")
+		}
+		return allowed(action)
+
+	// Minipools can be finalised once the user's share has been distributed
+	case MinipoolActionFinalise:
+		if state.Finalised {
+			return blocked(action, "minipool is already finalised")
+		}
+		if state.Version < 3 {
+			if state.Status != rptypes.Withdrawable {
+				return blocked(action, "minipool is not withdrawable")
+			}
+			return allowed(action)
+		}
+		if !state.UserDistributed {
+			return blocked(action, "user balance has not been distributed")
+		}
+		return allowed(action)
+
+	// Staking minipools can distribute their balance
+	case MinipoolActionDistributeBalance:
+		if state.Version < 3 {
+			return blocked(action, "minipool does not support balance distribution")
+		}
+		if state.Finalised {
+			return blocked(action, "minipool is already finalised")
+		}
+		if state.Status != rptypes.Staking {
+			return blocked(action, "minipool is not staking")
+		}
+		return allowed(action)
+
+	// Staking minipools can begin a bond reduction if one isn't already in progress
+	case MinipoolActionBeginReduceBondAmount:
+		if reason, ok := checkBondReduction(state, settings); !ok {
+			return blocked(action, reason)
+		}
+		if isBondReductionPending(state) {
+			windowEnd := state.ReduceBondTime.Add(settings.BondReductionWindowStart + settings.BondReductionWindowLength)
+			if now.Before(windowEnd) {
+				return ActionStatus{
+					Action:        action,
+					BlockedReason: "a bond reduction is already in progress",
+					UnlockTime:    windowEnd,
+				}
+			}
+		}
+		return allowed(action)
+
+	// Bond reductions can be completed during the reduction window
+	case MinipoolActionReduceBondAmount:
+		if reason, ok := checkBondReduction(state, settings); !ok {
+			return blocked(action, reason)
+		}
+		if !isBondReductionPending(state) {
+			return blocked(action, "bond reduction has not been started")
+		}
+		windowStart := state.ReduceBondTime.Add(settings.BondReductionWindowStart)
+		windowEnd := windowStart.Add(settings.BondReductionWindowLength)
+		if !now.Before(windowEnd) {
+			return blocked(action, "bond reduction window has passed")
+		}
+		if now.Before(windowStart) {
+			return ActionStatus{
+				Action:        action,
+				BlockedReason: "bond reduction window has not started",
+				UnlockTime:    windowStart,
+			}
+		}
+		return allowed(action)
+
+	}
+	return blocked(action, "unknown action")
+}
+
+// Check the requirements shared by both bond reduction actions
+func checkBondReduction(state LifecycleState, settings LifecycleSettings) (string, bool) {
+	if state.Version < 3 {
+		return "minipool does not support bond reduction", false
+	}
+	if !settings.BondReductionEnabled {
+		return "bond reduction is disabled", false
+	}
+	if state.Finalised {
+		return "minipool is already finalised", false
+	}
+	if state.Status != rptypes.Staking {
+		return "minipool is not staking", false
+	}
+	if state.ReduceBondCancelled {
+		return "bond reduction was cancelled by the Oracle DAO", false
+	}
+	return "", true
+}
+
+// Check if a bond reduction has been started
+func isBondReductionPending(state LifecycleState) bool {
+	return !state.ReduceBondTime.IsZero() && state.ReduceBondTime.Unix() != 0
+}
+
+// Create the status for an action that requires waiting until a given time; the contracts require the time to have strictly passed
+func waitUntil(action MinipoolAction, unlockTime time.Time, now time.Time, reason string) ActionStatus {
+	if !now.After(unlockTime) {
+		return ActionStatus{
+			Action:        action,
+			BlockedReason: reason,
+			UnlockTime:    unlockTime.Add(time.Second),
+		}
+	}
+	return allowed(action)
+}
+
+// Create the status for an action that can be performed
+func allowed(action MinipoolAction) ActionStatus {
+	return ActionStatus{
+		Action:  action,
+		Allowed: true,
+	}
+}
+
+// Create the status for an action that can't be performed by waiting
+func blocked(action MinipoolAction, reason string) ActionStatus {
+	return ActionStatus{
+		Action:        action,
+		BlockedReason: reason,
+	}
+}
@@ -64,6 +64,7 @@ func createMinipoolContractFromEncodedAbi(rp *rocketpool.RocketPool, address com
 		Address:  &address,
 		ABI:      abi,
 		Client:   rp.Client,
+		Name:     "poolseaMinipool",
 	}, nil
 }
 
@@ -75,6 +76,7 @@ func createMinipoolContractFromAbi(rp *rocketpool.RocketPool, address common.Add
 		Address:  &address,
 		ABI:      abi,
 		Client:   rp.Client,
+		Name:     "poolseaMinipool",
 	}, nil
 }
 
@@ -29,6 +29,7 @@ type MinipoolV3 interface {
 	ReduceBondAmount(opts *bind.TransactOpts) (common.Hash, error)
 	EstimatePromoteGas(opts *bind.TransactOpts) (rocketpool.GasInfo, error)
 	Promote(opts *bind.TransactOpts) (common.Hash, error)
+	GetVacant(opts *bind.CallOpts) (bool, error)
 	GetPreMigrationBalance(opts *bind.CallOpts) (*big.Int, error)
 	GetUserDistributed(opts *bind.CallOpts) (bool, error)
 	EstimateDistributeBalanceGas(rewardsOnly bool, opts *bind.TransactOpts) (rocketpool.GasInfo, error)
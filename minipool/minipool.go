@@ -554,6 +554,14 @@ func GetMinipoolWithdrawalCredentials(rp *rocketpool.RocketPool, minipoolAddress
 	return *withdrawalCredentials, nil
 }
 
+// Calculate the 0x01-based Beacon Chain withdrawal credentials for a minipool address: 0x01, 11 empty bytes, then the address
+func CalculateMinipoolWithdrawalCredentials(minipoolAddress common.Address) common.Hash {
+	var withdrawalCredentials common.Hash
+	withdrawalCredentials[0] = 0x01
+	copy(withdrawalCredentials[12:], minipoolAddress[:])
+	return withdrawalCredentials
+}
+
 // Get the number of penalties applied to a minipool
 func GetMinipoolPenaltyCount(rp *rocketpool.RocketPool, minipoolAddress common.Address, opts *bind.CallOpts) (uint64, error) {
 	key := crypto.Keccak256Hash([]byte("network.penalties.penalty"), minipoolAddress.Bytes())
@@ -564,6 +572,28 @@ func GetMinipoolPenaltyCount(rp *rocketpool.RocketPool, minipoolAddress common.A
 	return penalties.Uint64(), nil
 }
 
+// Get the penalty rate applied to a minipool, capped at the network's maximum rate
+func GetMinipoolPenaltyRate(rp *rocketpool.RocketPool, minipoolAddress common.Address, opts *bind.CallOpts) (*big.Int, error) {
+	penaltyRate, err := rp.RocketStorage.GetUint(opts, crypto.Keccak256Hash([]byte("minipool.penalty.rate"), minipoolAddress.Bytes()))
+	if err != nil {
+		return nil, fmt.Errorf("Could not get minipool penalty rate: %w", err)
+	}
+	maxPenaltyRate, err := GetMaxPenaltyRate(rp, opts)
+	if err != nil {
+		return nil, err
+	}
+	return CapPenaltyRate(penaltyRate, maxPenaltyRate), nil
+}
+
+// Get the maximum penalty rate that can be applied to a minipool
+func GetMaxPenaltyRate(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*big.Int, error) {
+	maxPenaltyRate, err := rp.RocketStorage.GetUint(opts, crypto.Keccak256Hash([]byte("minipool.penalty.rate.max")))
+	if err != nil {
+		return nil, fmt.Errorf("Could not get max minipool penalty rate: %w", err)
+	}
+	return maxPenaltyRate, nil
+}
+
 // Get the vacant minipool count
 func GetVacantMinipoolCount(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
 	rocketMinipoolManager, err := getRocketMinipoolManager(rp, opts)
@@ -0,0 +1,156 @@
+package minipool
+
+import (
+	"math/big"
+
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+// Balance thresholds used by the minipool delegates
+var (
+	calcBase            = big.NewInt(1e18)
+	legacyLaunchBalance = new(big.Int).Mul(big.NewInt(32), calcBase)
+	rewardsThreshold    = new(big.Int).Mul(big.NewInt(8), calcBase)
+	zeroBalance         = big.NewInt(0)
+)
+
+// The minipool details that determine how a balance is split between the node operator and the rETH users
+type ShareDetails struct {
+	Version            uint8
+	Status             rptypes.MinipoolStatus
+	DepositType        rptypes.MinipoolDeposit
+	NodeFee            *big.Int // The node commission, where 1e18 = 100%
+	NodeDepositBalance *big.Int
+	UserDepositBalance *big.Int // The value of getUserDepositBalance()
+	PenaltyRate        *big.Int // The value of getPenaltyRate() on the penalty contract (see CapPenaltyRate), where 1e18 = 100%
+	UserDistributed    bool
+}
+
+// Get the balance of a minipool that can be distributed, excluding the node operator's refund
+func GetDistributableBalance(contractBalance *big.Int, nodeRefundBalance *big.Int) *big.Int {
+	balance := new(big.Int).Sub(contractBalance, nodeRefundBalance)
+	if balance.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return balance
+}
+
+// Calculate the portion of a balance that belongs to the node operator.
+// This replicates calculateNodeShare() on the minipool delegate for the minipool's version without an eth_call.
+// The balance should exclude the node operator's refund balance.
+func CalculateNodeShareOffline(details ShareDetails, balance *big.Int) *big.Int {
+	if details.Version < 3 {
+		return calculateNodeShareV2(details, balance)
+	}
+	return calculateNodeShareV3(details, balance)
+}
+
+// Calculate the portion of a balance that belongs to the rETH users.
+// This replicates calculateUserShare() on the minipool delegate for the minipool's version without an eth_call.
+// The balance should exclude the node operator's refund balance.
+func CalculateUserShareOffline(details ShareDetails, balance *big.Int) *big.Int {
+	return new(big.Int).Sub(balance, CalculateNodeShareOffline(details, balance))
+}
+
+// Calculate how a distribution of the balance would be split between the node operator and the rETH users.
+// Unlike the share calculations, this accounts for minipools whose balance belongs entirely to the node operator:
+// dissolved minipools, and minipools whose user share has already been distributed.
+func CalculateDistributionOffline(details ShareDetails, balance *big.Int) (*big.Int, *big.Int) {
+	if details.Status == rptypes.Dissolved || details.UserDistributed {
+		return new(big.Int).Set(balance), big.NewInt(0)
+	}
+	nodeShare := CalculateNodeShareOffline(details, balance)
+	return nodeShare, new(big.Int).Sub(balance, nodeShare)
+}
+
+// calculateNodeShare() for v1 and v2 minipools
+func calculateNodeShareV2(details ShareDetails, balance *big.Int) *big.Int {
+
+	// Check if the node operator was slashed
+	userAmount := new(big.Int).Set(details.UserDepositBalance)
+	if balance.Cmp(userAmount) < 0 {
+		return big.NewInt(0)
+	}
+
+	// Check if there are rewards to pay out
+	if balance.Cmp(legacyLaunchBalance) > 0 {
+		totalRewards := new(big.Int).Sub(balance, legacyLaunchBalance)
+		halfRewards := new(big.Int).Div(totalRewards, big.NewInt(2))
+		nodeCommissionFee := new(big.Int).Mul(halfRewards, details.NodeFee)
+		nodeCommissionFee.Div(nodeCommissionFee, calcBase)
+		if details.DepositType == rptypes.Empty {
+			// Unbonded minipools give all of the rewards minus the commission to the users
+			userAmount.Add(userAmount, totalRewards.Sub(totalRewards, nodeCommissionFee))
+		} else {
+			// Bonded minipools give half of the rewards minus the commission to the users
+			userAmount.Add(userAmount, halfRewards.Sub(halfRewards, nodeCommissionFee))
+		}
+	}
+
+	// The node amount is whatever is left over
+	nodeAmount := new(big.Int).Sub(balance, userAmount)
+	return applyPenalty(nodeAmount, details.PenaltyRate)
+
+}
+
+// calculateNodeShare() for v3 minipools
+func calculateNodeShareV3(details ShareDetails, balance *big.Int) *big.Int {
+
+	// Balances under the threshold are treated as rewards
+	if balance.Cmp(rewardsThreshold) < 0 {
+		return calculateNodeRewards(details, details.NodeDepositBalance, details.UserDepositBalance, balance)
+	}
+
+	// Anything else is a full withdrawal
+	userCapital := details.UserDepositBalance
+	nodeCapital := details.NodeDepositBalance
+	capital := new(big.Int).Add(userCapital, nodeCapital)
+	nodeShare := big.NewInt(0)
+	if balance.Cmp(capital) > 0 {
+		rewards := new(big.Int).Sub(balance, capital)
+		nodeShare.Add(nodeCapital, calculateNodeRewards(details, nodeCapital, userCapital, rewards))
+	} else if balance.Cmp(userCapital) > 0 {
+		nodeShare.Sub(balance, userCapital)
+	}
+	return applyPenalty(nodeShare, details.PenaltyRate)
+
+}
+
+// Get the node operator's portion of the rewards, based on its share of the capital and its commission on the users' share
+func calculateNodeRewards(details ShareDetails, nodeCapital *big.Int, userCapital *big.Int, rewards *big.Int) *big.Int {
+	totalCapital := new(big.Int).Add(userCapital, nodeCapital)
+	if totalCapital.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	nodePortion := new(big.Int).Mul(rewards, nodeCapital)
+	nodePortion.Div(nodePortion, totalCapital)
+	userPortion := new(big.Int).Sub(rewards, nodePortion)
+	commission := userPortion.Mul(userPortion, details.NodeFee)
+	commission.Div(commission, calcBase)
+	return nodePortion.Add(nodePortion, commission)
+}
+
+// Get the penalty rate applied to a minipool from its stored rate and the network's maximum rate.
+// This replicates getPenaltyRate() on the penalty contract; a nil maximum leaves the rate uncapped.
+func CapPenaltyRate(penaltyRate *big.Int, maxPenaltyRate *big.Int) *big.Int {
+	if penaltyRate == nil {
+		return big.NewInt(0)
+	}
+	if maxPenaltyRate != nil && penaltyRate.Cmp(maxPenaltyRate) > 0 {
+		return new(big.Int).Set(maxPenaltyRate)
+	}
+	return new(big.Int).Set(penaltyRate)
+}
+
+// Reduce the node operator's share by its penalty rate
+func applyPenalty(nodeShare *big.Int, penaltyRate *big.Int) *big.Int {
+	if penaltyRate == nil || penaltyRate.Cmp(zeroBalance) <= 0 {
+		return nodeShare
+	}
+	penaltyAmount := new(big.Int).Mul(nodeShare, penaltyRate)
+	penaltyAmount.Div(penaltyAmount, calcBase)
+	if penaltyAmount.Cmp(nodeShare) > 0 {
+		penaltyAmount.Set(nodeShare)
+	}
+	return nodeShare.Sub(nodeShare, penaltyAmount)
+}
@@ -0,0 +1,264 @@
+package minipool
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/node"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/protocol"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+)
+
+// The stage of a solo validator's migration into a vacant minipool
+type SoloMigrationStage string
+
+const (
+	SoloMigrationStageScrubbing SoloMigrationStage = "scrubbing" // Waiting for the promotion scrub period to pass
+	SoloMigrationStageReady     SoloMigrationStage = "ready"     // The minipool can be promoted
+	SoloMigrationStagePromoted  SoloMigrationStage = "promoted"  // The minipool has been promoted and is staking
+	SoloMigrationStageScrubbed  SoloMigrationStage = "scrubbed"  // The Oracle DAO scrubbed the minipool during the promotion scrub period
+)
+
+// The Beacon Chain details of a solo validator being migrated, as reported by a Beacon client
+type MigrationValidator struct {
+	Pubkey                rptypes.ValidatorPubkey `json:"pubkey"`
+	WithdrawalCredentials common.Hash             `json:"withdrawalCredentials"`
+	Balance               uint64                  `json:"balance"` // Gwei
+	IsActive              bool                    `json:"isActive"`
+	IsExiting             bool                    `json:"isExiting"`
+	IsSlashed             bool                    `json:"isSlashed"`
+}
+
+// The result of checking whether a solo validator can be migrated into a vacant minipool
+type SoloMigrationValidation struct {
+	Validator                     MigrationValidator `json:"validator"`
+	ExpectedMinipoolAddress       common.Address     `json:"expectedMinipoolAddress"`
+	ExpectedWithdrawalCredentials common.Hash        `json:"expectedWithdrawalCredentials"`
+	CurrentBalance                *big.Int           `json:"currentBalance"` // Wei; the currentBalance argument for CreateVacantMinipool
+
+	// True if the validator still has BLS withdrawal credentials, which must be changed to the minipool's address before the promotion scrub period ends
+	RequiresCredentialsChange bool `json:"requiresCredentialsChange"`
+
+	CanMigrate bool     `json:"canMigrate"`
+	Errors     []string `json:"errors"`
+}
+
+// The progress of a vacant minipool through the promotion scrub period
+type SoloMigrationStatus struct {
+	MinipoolAddress     common.Address     `json:"minipoolAddress"`
+	Stage               SoloMigrationStage `json:"stage"`
+	IsVacant            bool               `json:"isVacant"`
+	PreMigrationBalance *big.Int           `json:"preMigrationBalance"`
+	Promote             ActionStatus       `json:"promote"`
+
+	// Problems with the validator that will cause the Oracle DAO to scrub the minipool; empty if the validator wasn't provided
+	ScrubRisks []string `json:"scrubRisks"`
+}
+
+// Check whether a solo validator can be migrated into a vacant minipool for the node with the provided salt
+func ValidateSoloMigration(rp *rocketpool.RocketPool, nodeAddress common.Address, salt *big.Int, validator MigrationValidator, opts *bind.CallOpts) (SoloMigrationValidation, error) {
+
+	// Data
+	var wg errgroup.Group
+	var expectedMinipoolAddress common.Address
+	var launchBalance *big.Int
+	var existingMinipool common.Address
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		expectedMinipoolAddress, err = GetExpectedAddress(rp, nodeAddress, salt, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		launchBalance, err = protocol.GetMinipoolLaunchBalance(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		existingMinipool, err = GetMinipoolByPubkey(rp, validator.Pubkey, opts)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return SoloMigrationValidation{}, fmt.Errorf("Could not get solo migration details for validator %s: %w", validator.Pubkey.Hex(), err)
+	}
+
+	validation := CheckSoloMigration(validator, expectedMinipoolAddress, launchBalance)
+	if existingMinipool != (common.Address{}) {
+		validation.Errors = append(validation.Errors, fmt.Sprintf("validator is already used by minipool %s", existingMinipool.Hex()))
+		validation.CanMigrate = false
+	}
+	return validation, nil
+
+}
+
+// Check whether a solo validator can be migrated into a vacant minipool at the provided address
+func CheckSoloMigration(validator MigrationValidator, expectedMinipoolAddress common.Address, launchBalance *big.Int) SoloMigrationValidation {
+
+	validation := SoloMigrationValidation{
+		Validator:                     validator,
+		ExpectedMinipoolAddress:       expectedMinipoolAddress,
+		ExpectedWithdrawalCredentials: CalculateMinipoolWithdrawalCredentials(expectedMinipoolAddress),
+		CurrentBalance:                eth.GweiToWei(float64(validator.Balance)),
+		Errors:                        []string{},
+	}
+
+	// Check the validator's status
+	if !validator.IsActive {
+		validation.Errors = append(validation.Errors, "validator is not active")
+	}
+	if validator.IsExiting {
+		validation.Errors = append(validation.Errors, "validator is exiting")
+	}
+	if validator.IsSlashed {
+		validation.Errors = append(validation.Errors, "validator has been slashed")
+	}
+
+	// Check the balance
+	if validation.CurrentBalance.Cmp(launchBalance) < 0 {
+		validation.Errors = append(validation.Errors, fmt.Sprintf("validator balance of %.6f ETH is below the minipool launch balance of %.6f ETH", eth.WeiToEth(validation.CurrentBalance), eth.WeiToEth(launchBalance)))
+	}
+
+	// Check the withdrawal credentials
+	switch validator.WithdrawalCredentials[0] {
+	case 0x00:
+		validation.RequiresCredentialsChange = true
+	case 0x01:
+		if validator.WithdrawalCredentials != validation.ExpectedWithdrawalCredentials {
+			validation.Errors = append(validation.Errors, fmt.Sprintf("validator withdrawal credentials %s do not point to the minipool address %s", validator.WithdrawalCredentials.Hex(), expectedMinipoolAddress.Hex()))
+		}
+	default:
+		validation.Errors = append(validation.Errors, fmt.Sprintf("validator has unsupported withdrawal credentials %s", validator.WithdrawalCredentials.Hex()))
+	}
+
+	validation.CanMigrate = len(validation.Errors) == 0
+	return validation
+
+}
+
+// Create a vacant minipool for a solo validator once it has been validated
+func CreateSoloMigrationMinipool(rp *rocketpool.RocketPool, validation SoloMigrationValidation, bondAmount *big.Int, minimumNodeFee float64, salt *big.Int, opts *bind.TransactOpts) (common.Hash, error) {
+	if !validation.CanMigrate {
+		return common.Hash{}, fmt.Errorf("Validator %s cannot be migrated: %v", validation.Validator.Pubkey.Hex(), validation.Errors)
+	}
+	tx, err := node.CreateVacantMinipool(rp, bondAmount, minimumNodeFee, validation.Validator.Pubkey, salt, validation.ExpectedMinipoolAddress, validation.CurrentBalance, opts)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+// Get the progress of a vacant minipool through the promotion scrub period.
+// If the validator is provided, its current state is checked for anything that will cause the Oracle DAO to scrub the minipool.
+func GetSoloMigrationStatus(rp *rocketpool.RocketPool, minipoolAddress common.Address, validator *MigrationValidator, now time.Time, opts *bind.CallOpts) (SoloMigrationStatus, error) {
+
+	// Get the minipool
+	mp, err := NewMinipool(rp, minipoolAddress, opts)
+	if err != nil {
+		return SoloMigrationStatus{}, err
+	}
+	mpv3, ok := GetMinipoolAsV3(mp)
+	if !ok {
+		return SoloMigrationStatus{}, fmt.Errorf("Minipool %s is version %d and does not support solo migration", minipoolAddress.Hex(), mp.GetVersion())
+	}
+
+	// Data
+	var wg errgroup.Group
+	var lifecycle Lifecycle
+	var preMigrationBalance *big.Int
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		lifecycle, err = GetLifecycle(rp, mp, now, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		preMigrationBalance, err = mpv3.GetPreMigrationBalance(opts)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return SoloMigrationStatus{}, fmt.Errorf("Could not get solo migration status for minipool %s: %w", minipoolAddress.Hex(), err)
+	}
+	return EvaluateSoloMigration(minipoolAddress, lifecycle, preMigrationBalance, validator), nil
+
+}
+
+// Evaluate the progress of a vacant minipool through the promotion scrub period
+func EvaluateSoloMigration(minipoolAddress common.Address, lifecycle Lifecycle, preMigrationBalance *big.Int, validator *MigrationValidator) SoloMigrationStatus {
+
+	promote, _ := lifecycle.GetAction(MinipoolActionPromote)
+	status := SoloMigrationStatus{
+		MinipoolAddress:     minipoolAddress,
+		IsVacant:            lifecycle.State.IsVacant,
+		PreMigrationBalance: preMigrationBalance,
+		Promote:             promote,
+		ScrubRisks:          []string{},
+	}
+
+	// Get the stage
+	switch {
+	case lifecycle.State.Status == rptypes.Dissolved:
+		status.Stage = SoloMigrationStageScrubbed
+	case !lifecycle.State.IsVacant:
+		status.Stage = SoloMigrationStagePromoted
+	case promote.Allowed:
+		status.Stage = SoloMigrationStageReady
+	default:
+		status.Stage = SoloMigrationStageScrubbing
+	}
+
+	// Check the validator for anything the Oracle DAO will scrub the minipool for
+	if validator != nil && lifecycle.State.IsVacant && status.Stage != SoloMigrationStageScrubbed {
+		expectedCredentials := CalculateMinipoolWithdrawalCredentials(minipoolAddress)
+		if validator.WithdrawalCredentials != expectedCredentials {
+			status.ScrubRisks = append(status.ScrubRisks, fmt.Sprintf("validator withdrawal credentials %s do not point to the minipool", validator.WithdrawalCredentials.Hex()))
+		}
+		if balance := eth.GweiToWei(float64(validator.Balance)); preMigrationBalance != nil && balance.Cmp(preMigrationBalance) < 0 {
+			status.ScrubRisks = append(status.ScrubRisks, fmt.Sprintf("validator balance of %.6f ETH is below the pre-migration balance of %.6f ETH", eth.WeiToEth(balance), eth.WeiToEth(preMigrationBalance)))
+		}
+		if validator.IsExiting || validator.IsSlashed {
+			status.ScrubRisks = append(status.ScrubRisks, "validator is exiting or has been slashed")
+		}
+	}
+	return status
+
+}
+
+// Promote a vacant minipool once its promotion scrub period has passed
+func PromoteSoloMigrationMinipool(rp *rocketpool.RocketPool, minipoolAddress common.Address, now time.Time, opts *bind.TransactOpts) (common.Hash, error) {
+
+	// Check that the minipool can be promoted
+	mp, err := NewMinipool(rp, minipoolAddress, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	mpv3, ok := GetMinipoolAsV3(mp)
+	if !ok {
+		return common.Hash{}, fmt.Errorf("Minipool %s is version %d and does not support solo migration", minipoolAddress.Hex(), mp.GetVersion())
+	}
+	lifecycle, err := GetLifecycle(rp, mp, now, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if promote, _ := lifecycle.GetAction(MinipoolActionPromote); !promote.Allowed {
+		return common.Hash{}, fmt.Errorf("Minipool %s cannot be promoted: %s", minipoolAddress.Hex(), promote.BlockedReason)
+	}
+
+	// Promote it
+	return mpv3.Promote(opts)
+
+}
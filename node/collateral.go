@@ -0,0 +1,241 @@
+package node
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/network"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/protocol"
+)
+
+// The node and network details that determine a node's RPL collateral
+type CollateralState struct {
+	RplStake                *big.Int `json:"rplStake"`
+	RplPrice                *big.Int `json:"rplPrice"`                // ETH per RPL, where 1e18 = 1 ETH
+	MinimumPerMinipoolStake *big.Int `json:"minimumPerMinipoolStake"` // Fraction of matched ETH, where 1e18 = 100%
+	MaximumPerMinipoolStake *big.Int `json:"maximumPerMinipoolStake"` // Fraction of provided ETH, where 1e18 = 100%
+	EthMatched              *big.Int `json:"ethMatched"`
+	EthProvided             *big.Int `json:"ethProvided"`
+	LaunchBalance           *big.Int `json:"launchBalance"`
+}
+
+// The result of planning new minipools for a node
+type StakePlan struct {
+	MinipoolCount          uint64          `json:"minipoolCount"`
+	BondAmount             *big.Int        `json:"bondAmount"`
+	Before                 CollateralState `json:"before"`
+	After                  CollateralState `json:"after"`
+	RequiredRplStake       *big.Int        `json:"requiredRplStake"`       // The total stake needed for the deposits to pass the ETH matched limit
+	AdditionalRplStake     *big.Int        `json:"additionalRplStake"`     // The amount that must be staked on top of the current stake
+	MaximumRplStake        *big.Int        `json:"maximumRplStake"`        // The stake above which effective stake is capped after the deposits
+	EffectiveRplStakeAfter *big.Int        `json:"effectiveRplStakeAfter"` // The effective stake after the deposits, at the current stake
+	CollateralRatioAfter   *big.Int        `json:"collateralRatioAfter"`   // RPL value as a fraction of matched ETH after the deposits, where 1e18 = 100%
+	IsEffectiveStakeCapped bool            `json:"isEffectiveStakeCapped"`
+}
+
+// Get the collateral details for a node
+func GetCollateralState(rp *rocketpool.RocketPool, nodeAddress common.Address, opts *bind.CallOpts) (CollateralState, error) {
+
+	// Data
+	var wg errgroup.Group
+	var state CollateralState
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		state.RplStake, err = GetNodeRPLStake(rp, nodeAddress, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.RplPrice, err = network.GetRPLPrice(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.MinimumPerMinipoolStake, err = protocol.GetMinimumPerMinipoolStakeRaw(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.MaximumPerMinipoolStake, err = protocol.GetMaximumPerMinipoolStakeRaw(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.EthMatched, err = GetNodeEthMatched(rp, nodeAddress, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.EthProvided, err = GetNodeEthProvided(rp, nodeAddress, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		state.LaunchBalance, err = protocol.GetMinipoolLaunchBalance(rp, opts)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return CollateralState{}, fmt.Errorf("Could not get collateral details for node %s: %w", nodeAddress.Hex(), err)
+	}
+	return state, nil
+
+}
+
+// Get the maximum amount of ETH a node can have matched for an RPL stake; this matches getNodeETHMatchedLimit()
+func GetEthMatchedLimit(rplStake *big.Int, rplPrice *big.Int, minimumPerMinipoolStake *big.Int) *big.Int {
+	if minimumPerMinipoolStake.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	limit := new(big.Int).Mul(rplStake, rplPrice)
+	return limit.Div(limit, minimumPerMinipoolStake)
+}
+
+// Get the smallest RPL stake whose ETH matched limit covers the provided amount of ETH matched.
+// This rounds up, so it can be 1 wei above getNodeMinimumRPLStake().
+func GetRequiredRplStake(ethMatched *big.Int, rplPrice *big.Int, minimumPerMinipoolStake *big.Int) *big.Int {
+	if rplPrice.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	required := new(big.Int).Mul(ethMatched, minimumPerMinipoolStake)
+	required.Add(required, new(big.Int).Sub(rplPrice, big.NewInt(1)))
+	return required.Div(required, rplPrice)
+}
+
+// Get the ETH matched limit; this matches getNodeETHMatchedLimit()
+func (s CollateralState) GetEthMatchedLimit() *big.Int {
+	return GetEthMatchedLimit(s.RplStake, s.RplPrice, s.MinimumPerMinipoolStake)
+}
+
+// Get the minimum RPL stake; this matches getNodeMinimumRPLStake()
+func (s CollateralState) GetMinimumRplStake() *big.Int {
+	if s.RplPrice.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	stake := new(big.Int).Mul(s.EthMatched, s.MinimumPerMinipoolStake)
+	return stake.Div(stake, s.RplPrice)
+}
+
+// Get the maximum RPL stake that earns rewards; this matches getNodeMaximumRPLStake()
+func (s CollateralState) GetMaximumRplStake() *big.Int {
+	if s.RplPrice.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	stake := new(big.Int).Mul(s.EthProvided, s.MaximumPerMinipoolStake)
+	return stake.Div(stake, s.RplPrice)
+}
+
+// Get the effective RPL stake; this matches getNodeEffectiveRPLStake()
+func (s CollateralState) GetEffectiveRplStake() *big.Int {
+	if s.RplStake.Cmp(s.GetMinimumRplStake()) < 0 {
+		return big.NewInt(0)
+	}
+	maximumStake := s.GetMaximumRplStake()
+	if s.RplStake.Cmp(maximumStake) > 0 {
+		return maximumStake
+	}
+	return new(big.Int).Set(s.RplStake)
+}
+
+// Get the value of the RPL stake as a fraction of the matched ETH, where 1e18 = 100%.
+// Returns nil if the node has no matched ETH.
+func (s CollateralState) GetCollateralRatio() *big.Int {
+	if s.EthMatched.Sign() == 0 {
+		return nil
+	}
+	ratio := new(big.Int).Mul(s.RplStake, s.RplPrice)
+	return ratio.Div(ratio, s.EthMatched)
+}
+
+// Get the RPL price above which the stake would be capped by the maximum RPL stake.
+// Returns nil if the stake is empty.
+func (s CollateralState) GetCapPrice() *big.Int {
+	if s.RplStake.Sign() == 0 {
+		return nil
+	}
+	price := new(big.Int).Mul(s.EthProvided, s.MaximumPerMinipoolStake)
+	return price.Div(price, s.RplStake)
+}
+
+// Get the RPL price below which the stake would fall under the minimum RPL stake and earn no rewards.
+// Returns nil if the stake is empty.
+func (s CollateralState) GetUndercollateralizedPrice() *big.Int {
+	if s.RplStake.Sign() == 0 {
+		return nil
+	}
+	price := new(big.Int).Mul(s.EthMatched, s.MinimumPerMinipoolStake)
+	return price.Div(price, s.RplStake)
+}
+
+// Get the state after creating new minipools with the provided bond
+func (s CollateralState) WithNewMinipools(count uint64, bondAmount *big.Int) CollateralState {
+	countBig := new(big.Int).SetUint64(count)
+	matched := new(big.Int).Sub(s.LaunchBalance, bondAmount)
+	s.EthMatched = new(big.Int).Add(s.EthMatched, matched.Mul(matched, countBig))
+	s.EthProvided = new(big.Int).Add(s.EthProvided, new(big.Int).Mul(bondAmount, countBig))
+	return s
+}
+
+// Get the state after reducing a minipool's bond
+func (s CollateralState) WithBondReduction(currentBond *big.Int, newBond *big.Int) CollateralState {
+	delta := new(big.Int).Sub(currentBond, newBond)
+	s.EthMatched = new(big.Int).Add(s.EthMatched, delta)
+	s.EthProvided = new(big.Int).Sub(s.EthProvided, delta)
+	return s
+}
+
+// Get the state with a different RPL stake
+func (s CollateralState) WithRplStake(rplStake *big.Int) CollateralState {
+	s.RplStake = rplStake
+	return s
+}
+
+// Get the state with a different RPL price
+func (s CollateralState) WithRplPrice(rplPrice *big.Int) CollateralState {
+	s.RplPrice = rplPrice
+	return s
+}
+
+// Plan the RPL stake needed to create new minipools with the provided bond
+func (s CollateralState) PlanMinipools(count uint64, bondAmount *big.Int) StakePlan {
+	after := s.WithNewMinipools(count, bondAmount)
+	required := GetRequiredRplStake(after.EthMatched, after.RplPrice, after.MinimumPerMinipoolStake)
+	additional := new(big.Int).Sub(required, s.RplStake)
+	if additional.Sign() < 0 {
+		additional.SetUint64(0)
+	}
+	maximumStake := after.GetMaximumRplStake()
+	return StakePlan{
+		MinipoolCount:          count,
+		BondAmount:             bondAmount,
+		Before:                 s,
+		After:                  after,
+		RequiredRplStake:       required,
+		AdditionalRplStake:     additional,
+		MaximumRplStake:        maximumStake,
+		EffectiveRplStakeAfter: after.GetEffectiveRplStake(),
+		CollateralRatioAfter:   after.GetCollateralRatio(),
+		IsEffectiveStakeCapped: after.RplStake.Cmp(maximumStake) > 0,
+	}
+}
+
+// Get the number of new minipools with the provided bond that the current RPL stake can support
+func (s CollateralState) GetMaxNewMinipools(bondAmount *big.Int) uint64 {
+	matchedPerMinipool := new(big.Int).Sub(s.LaunchBalance, bondAmount)
+	if matchedPerMinipool.Sign() <= 0 {
+		return 0
+	}
+	available := new(big.Int).Sub(s.GetEthMatchedLimit(), s.EthMatched)
+	if available.Sign() <= 0 {
+		return 0
+	}
+	return available.Div(available, matchedPerMinipool).Uint64()
+}
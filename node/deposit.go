@@ -94,6 +94,20 @@ func GetNodeDepositCredit(rp *rocketpool.RocketPool, nodeAddress common.Address,
 	return *creditBalance, nil
 }
 
+// Get the bond amounts that the node deposit contract accepts for new minipools and bond reductions
+func GetDepositAmounts(rp *rocketpool.RocketPool, opts *bind.CallOpts) ([]*big.Int, error) {
+	rocketNodeDeposit, err := getRocketNodeDeposit(rp, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := new([]*big.Int)
+	if err := rocketNodeDeposit.Call(opts, amounts, "getDepositAmounts"); err != nil {
+		return nil, fmt.Errorf("Could not get deposit amounts: %w", err)
+	}
+	return *amounts, nil
+}
+
 // Get contracts
 var rocketNodeDepositLock sync.Mutex
 
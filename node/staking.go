@@ -136,6 +136,19 @@ func GetNodeEthMatchedLimit(rp *rocketpool.RocketPool, nodeAddress common.Addres
 	return *nodeEthMatchedLimit, nil
 }
 
+// Get the amount of ETH the node has bonded to create its minipools
+func GetNodeEthProvided(rp *rocketpool.RocketPool, nodeAddress common.Address, opts *bind.CallOpts) (*big.Int, error) {
+	rocketNodeStaking, err := getRocketNodeStaking(rp, opts)
+	if err != nil {
+		return nil, err
+	}
+	nodeEthProvided := new(*big.Int)
+	if err := rocketNodeStaking.Call(opts, nodeEthProvided, "getNodeETHProvided", nodeAddress); err != nil {
+		return nil, fmt.Errorf("Could not get node ETH provided: %w", err)
+	}
+	return *nodeEthProvided, nil
+}
+
 // Estimate the gas of Stake
 func EstimateStakeGas(rp *rocketpool.RocketPool, rplAmount *big.Int, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
 	rocketNodeStaking, err := getRocketNodeStaking(rp, nil)
@@ -0,0 +1,264 @@
+package rewards
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/protocol"
+	"github.com/Seb369888/poolsea-go/tokens"
+)
+
+// The contracts that receive a share of RPL inflation
+var RewardsClaimers = []string{
+	protocol.NodeOperatorClaimerContractName,
+	protocol.TrustedNodeClaimerContractName,
+	protocol.ProtocolDaoClaimerContractName,
+}
+
+// Fixed point base for claimer percentages
+var claimerCalcBase = big.NewInt(1e18)
+
+// A claimer's share of inflation as of a block
+type ClaimerPercentage struct {
+	BlockNumber uint64    `json:"blockNumber"`
+	Perc        *big.Int  `json:"perc"` // Where 1e18 = 100%
+	TimeUpdated time.Time `json:"timeUpdated"`
+}
+
+// A claimer's current share of inflation, the shares it has had before, and its projected emissions
+type ClaimerDetails struct {
+	ContractName     string              `json:"contractName"`
+	Address          common.Address      `json:"address"`
+	Perc             *big.Int            `json:"perc"` // Where 1e18 = 100%
+	TimeUpdated      time.Time           `json:"timeUpdated"`
+	History          []ClaimerPercentage `json:"history"` // Each distinct share seen at the history blocks, oldest first
+	IntervalEmission *big.Int            `json:"intervalEmission"`
+}
+
+// The RPL minted for one rewards claim interval, and each claimer's share of it
+type ClaimerIntervalProjection struct {
+	Interval         int        `json:"interval"`
+	TotalSupply      *big.Int   `json:"totalSupply"` // The RPL supply at the start of the interval
+	Emission         *big.Int   `json:"emission"`
+	ClaimerEmissions []*big.Int `json:"claimerEmissions"` // In the same order as the report's claimers
+	Unallocated      *big.Int   `json:"unallocated"`
+}
+
+// The rewards claimers and their projected RPL emissions
+type ClaimerReport struct {
+	TotalSupply           *big.Int                    `json:"totalSupply"`
+	InflationIntervalRate *big.Int                    `json:"inflationIntervalRate"` // Where 1e18 = no inflation
+	InflationInterval     time.Duration               `json:"inflationInterval"`
+	ClaimInterval         time.Duration               `json:"claimInterval"`
+	ClaimersPercTotal     *big.Int                    `json:"claimersPercTotal"`
+	Claimers              []ClaimerDetails            `json:"claimers"`
+	Projections           []ClaimerIntervalProjection `json:"projections"`
+}
+
+// Get the rewards claimer report, projecting emissions over the given number of claim intervals.
+// The claimers default to RewardsClaimers; each claimer's history is read at the history blocks, which should be in ascending order.
+func GetClaimerReport(rp *rocketpool.RocketPool, claimerNames []string, historyBlocks []uint64, intervals int, opts *bind.CallOpts) (ClaimerReport, error) {
+	if claimerNames == nil {
+		claimerNames = RewardsClaimers
+	}
+
+	// Data
+	var wg errgroup.Group
+	report := ClaimerReport{
+		Claimers: make([]ClaimerDetails, len(claimerNames)),
+	}
+	var inflationIntervalTime uint64
+	var claimIntervalTime uint64
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		report.TotalSupply, err = tokens.GetRPLTotalSupply(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		report.InflationIntervalRate, err = tokens.GetRPLInflationIntervalRate(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		inflationIntervalTime, err = tokens.GetRPLInflationIntervalTime(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		claimIntervalTime, err = protocol.GetRewardsClaimIntervalTime(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		report.ClaimersPercTotal, err = protocol.GetRewardsClaimersPercTotalRaw(rp, opts)
+		return err
+	})
+	for ci, name := range claimerNames {
+		ci, name := ci, name
+		wg.Go(func() error {
+			var err error
+			report.Claimers[ci], err = getClaimerDetails(rp, name, historyBlocks, opts)
+			return err
+		})
+	}
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return ClaimerReport{}, fmt.Errorf("Could not get rewards claimer report: %w", err)
+	}
+	report.InflationInterval = time.Duration(inflationIntervalTime) * time.Second
+	report.ClaimInterval = time.Duration(claimIntervalTime) * time.Second
+
+	// Project the emissions
+	var inflationIntervalsPerClaim uint64
+	if inflationIntervalTime > 0 {
+		inflationIntervalsPerClaim = claimIntervalTime / inflationIntervalTime
+	}
+	percs := make([]*big.Int, len(report.Claimers))
+	for ci, claimer := range report.Claimers {
+		percs[ci] = claimer.Perc
+	}
+	report.Projections = ProjectClaimerEmissions(report.TotalSupply, report.InflationIntervalRate, inflationIntervalsPerClaim, percs, intervals)
+	if len(report.Projections) > 0 {
+		for ci := range report.Claimers {
+			report.Claimers[ci].IntervalEmission = report.Projections[0].ClaimerEmissions[ci]
+		}
+	}
+	return report, nil
+
+}
+
+// Project the RPL minted over a number of rewards claim intervals, and each claimer's share of it.
+// Inflation is minted at each claim, as the RPL token calculates it for the inflation intervals since the last one.
+func ProjectClaimerEmissions(totalSupply *big.Int, inflationIntervalRate *big.Int, inflationIntervalsPerClaim uint64, claimerPercs []*big.Int, intervals int) []ClaimerIntervalProjection {
+	projections := make([]ClaimerIntervalProjection, 0, intervals)
+	supply := new(big.Int).Set(totalSupply)
+	for interval := 0; interval < intervals; interval++ {
+		projection := ClaimerIntervalProjection{
+			Interval:         interval,
+			TotalSupply:      new(big.Int).Set(supply),
+			ClaimerEmissions: make([]*big.Int, len(claimerPercs)),
+		}
+		projection.Emission = tokens.CalculateRPLInflation(supply, inflationIntervalRate, inflationIntervalsPerClaim)
+		supply.Add(supply, projection.Emission)
+		projection.Unallocated = new(big.Int).Set(projection.Emission)
+		for ci, perc := range claimerPercs {
+			emission := new(big.Int).Mul(projection.Emission, perc)
+			emission.Div(emission, claimerCalcBase)
+			projection.ClaimerEmissions[ci] = emission
+			projection.Unallocated.Sub(projection.Unallocated, emission)
+		}
+		projections = append(projections, projection)
+	}
+	return projections
+}
+
+// Get the current split of inflation between node operators, the oracle DAO and the protocol DAO
+func GetInflationSplit(rp *rocketpool.RocketPool, opts *bind.CallOpts) (tokens.InflationSplit, error) {
+
+	// Data
+	var wg errgroup.Group
+	var split tokens.InflationSplit
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		split.NodeOperatorPerc, err = protocol.GetRewardsClaimerPercRaw(rp, protocol.NodeOperatorClaimerContractName, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		split.TrustedNodePerc, err = protocol.GetRewardsClaimerPercRaw(rp, protocol.TrustedNodeClaimerContractName, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		split.ProtocolDaoPerc, err = protocol.GetRewardsClaimerPercRaw(rp, protocol.ProtocolDaoClaimerContractName, opts)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return tokens.InflationSplit{}, fmt.Errorf("Could not get RPL inflation split: %w", err)
+	}
+	return split, nil
+
+}
+
+// Get a claimer's current share and its history
+func getClaimerDetails(rp *rocketpool.RocketPool, contractName string, historyBlocks []uint64, opts *bind.CallOpts) (ClaimerDetails, error) {
+
+	// Data
+	var wg errgroup.Group
+	details := ClaimerDetails{ContractName: contractName}
+	var timeUpdated uint64
+	history := make([]ClaimerPercentage, len(historyBlocks))
+
+	// Load data
+	wg.Go(func() error {
+		address, err := rp.GetAddress(contractName, opts)
+		if err == nil {
+			details.Address = *address
+		}
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		details.Perc, err = protocol.GetRewardsClaimerPercRaw(rp, contractName, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		timeUpdated, err = protocol.GetRewardsClaimerPercTimeUpdated(rp, contractName, opts)
+		return err
+	})
+	for bi, block := range historyBlocks {
+		bi, block := bi, block
+		wg.Go(func() error {
+			blockOpts := &bind.CallOpts{BlockNumber: new(big.Int).SetUint64(block)}
+			perc, err := protocol.GetRewardsClaimerPercRaw(rp, contractName, blockOpts)
+			if err != nil {
+				return err
+			}
+			percTimeUpdated, err := protocol.GetRewardsClaimerPercTimeUpdated(rp, contractName, blockOpts)
+			if err != nil {
+				return err
+			}
+			history[bi] = ClaimerPercentage{
+				BlockNumber: block,
+				Perc:        perc,
+				TimeUpdated: time.Unix(int64(percTimeUpdated), 0),
+			}
+			return nil
+		})
+	}
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return ClaimerDetails{}, fmt.Errorf("Could not get rewards claimer %s details: %w", contractName, err)
+	}
+	details.TimeUpdated = time.Unix(int64(timeUpdated), 0)
+
+	// Keep the first sample of each distinct share
+	details.History = []ClaimerPercentage{}
+	for _, sample := range history {
+		if count := len(details.History); count > 0 {
+			last := details.History[count-1]
+			if last.TimeUpdated.Equal(sample.TimeUpdated) && last.Perc.Cmp(sample.Perc) == 0 {
+				continue
+			}
+		}
+		details.History = append(details.History, sample)
+	}
+	return details, nil
+
+}
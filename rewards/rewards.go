@@ -336,6 +336,14 @@ func GetRewardSnapshotEventWithUpgrades(rp *rocketpool.RocketPool, index uint64,
 		return false, RewardsEvent{}, err
 	}
 
+	// Use the upgrade history if the previous addresses weren't provided
+	if rocketRewardsPoolAddresses == nil {
+		rocketRewardsPoolAddresses, err = rp.UpgradeHistory.GetContractAddresses("poolseaRewardsPool", intervalSize, opts)
+		if err != nil {
+			return false, RewardsEvent{}, fmt.Errorf("Could not get rewards pool address history: %w", err)
+		}
+	}
+
 	latestAddress := *rocketRewardsPool.Address
 	cleanedAddresses := []common.Address{latestAddress}
 	for _, address := range rocketRewardsPoolAddresses {
@@ -0,0 +1,146 @@
+package rocketpool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// The kind of change made to an ABI method or event
+type AbiChangeKind string
+
+const (
+	AbiChangeAdded   AbiChangeKind = "added"
+	AbiChangeRemoved AbiChangeKind = "removed"
+	AbiChangeChanged AbiChangeKind = "changed"
+)
+
+// A method or event that differs between two ABIs
+type AbiChange struct {
+	Kind         AbiChangeKind `json:"kind"`
+	Name         string        `json:"name"`
+	OldSignature string        `json:"oldSignature,omitempty"`
+	NewSignature string        `json:"newSignature,omitempty"`
+}
+
+// The differences between two versions of a contract ABI, ordered by name
+type AbiDiff struct {
+	Methods []AbiChange `json:"methods"`
+	Events  []AbiChange `json:"events"`
+}
+
+// Compare two ABIs; either can be nil
+func DiffABIs(oldAbi *abi.ABI, newAbi *abi.ABI) AbiDiff {
+	oldMethods, oldEvents := getAbiSignatures(oldAbi)
+	newMethods, newEvents := getAbiSignatures(newAbi)
+	return AbiDiff{
+		Methods: diffSignatures(oldMethods, newMethods),
+		Events:  diffSignatures(oldEvents, newEvents),
+	}
+}
+
+// Check whether the ABIs differ
+func (d AbiDiff) HasChanges() bool {
+	return len(d.Methods) > 0 || len(d.Events) > 0
+}
+
+// Get the methods that were removed or changed, so existing callers can no longer use them.
+// If calledMethods is provided, only those methods are included; if it is nil, the methods callers use are unknown and every removed or changed method is included.
+func (d AbiDiff) GetBreakingChanges(calledMethods []string) []AbiChange {
+	called := map[string]bool{}
+	for _, method := range calledMethods {
+		called[method] = true
+	}
+	breaking := []AbiChange{}
+	for _, change := range d.Methods {
+		if change.Kind == AbiChangeAdded || (calledMethods != nil && !called[change.Name]) {
+			continue
+		}
+		breaking = append(breaking, change)
+	}
+	return breaking
+}
+
+// Get a summary of the changes, such as "methods: 2 added, 1 changed; events: 1 removed"
+func (d AbiDiff) Summary() string {
+	if !d.HasChanges() {
+		return "no ABI changes"
+	}
+	parts := []string{}
+	for _, section := range []struct {
+		name    string
+		changes []AbiChange
+	}{{"methods", d.Methods}, {"events", d.Events}} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		counts := map[AbiChangeKind]int{}
+		for _, change := range section.changes {
+			counts[change.Kind]++
+		}
+		kinds := []string{}
+		for _, kind := range []AbiChangeKind{AbiChangeAdded, AbiChangeRemoved, AbiChangeChanged} {
+			if counts[kind] > 0 {
+				kinds = append(kinds, fmt.Sprintf("%d %s", counts[kind], kind))
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", section.name, strings.Join(kinds, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Get the signatures of an ABI's methods and events by name
+func getAbiSignatures(contractAbi *abi.ABI) (map[string]string, map[string]string) {
+	methods := map[string]string{}
+	events := map[string]string{}
+	if contractAbi == nil {
+		return methods, events
+	}
+	for name, method := range contractAbi.Methods {
+		signature := fmt.Sprintf("%s %s", method.Sig, method.StateMutability)
+		if len(method.Outputs) > 0 {
+			signature += fmt.Sprintf(" returns (%s)", getArgumentTypes(method.Outputs))
+		}
+		methods[name] = signature
+	}
+	for name, event := range contractAbi.Events {
+		events[name] = fmt.Sprintf("%s(%s)", event.RawName, getArgumentTypes(event.Inputs))
+	}
+	return methods, events
+}
+
+// Get the types of a set of arguments, marking indexed event arguments
+func getArgumentTypes(args abi.Arguments) string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = arg.Type.String()
+		if arg.Indexed {
+			types[i] += " indexed"
+		}
+	}
+	return strings.Join(types, ",")
+}
+
+// Compare two sets of signatures by name
+func diffSignatures(oldSignatures map[string]string, newSignatures map[string]string) []AbiChange {
+	changes := []AbiChange{}
+	for name, oldSignature := range oldSignatures {
+		newSignature, exists := newSignatures[name]
+		if !exists {
+			changes = append(changes, AbiChange{Kind: AbiChangeRemoved, Name: name, OldSignature: oldSignature})
+		} else if newSignature != oldSignature {
+			changes = append(changes, AbiChange{Kind: AbiChangeChanged, Name: name, OldSignature: oldSignature, NewSignature: newSignature})
+		}
+	}
+	for name, newSignature := range newSignatures {
+		if _, exists := oldSignatures[name]; !exists {
+			changes = append(changes, AbiChange{Kind: AbiChangeAdded, Name: name, NewSignature: newSignature})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
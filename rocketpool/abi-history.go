@@ -0,0 +1,136 @@
+package rocketpool
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Identifies a single ABI version of a network contract
+type abiVersionKey struct {
+	name       common.Hash
+	startBlock uint64
+}
+
+// Get the ABI that was registered for a network contract on the given block
+func (rp *RocketPool) GetABIAtBlock(contractName string, block uint64) (*abi.ABI, error) {
+	return rp.UpgradeHistory.GetABIAtBlock(contractName, block)
+}
+
+// Get a network contract with the address and ABI that were registered for it on the given block
+func (rp *RocketPool) GetContractAtBlock(contractName string, block uint64) (*Contract, error) {
+	address, err := rp.GetContractAddressAtBlock(contractName, block)
+	if err != nil {
+		return nil, err
+	}
+	abi, err := rp.GetABIAtBlock(contractName, block)
+	if err != nil {
+		return nil, err
+	}
+	return &Contract{
+		Contract: bind.NewBoundContract(address, *abi, rp.Client, rp.Client, rp.Client),
+		Address:  &address,
+		ABI:      abi,
+		Client:   rp.Client,
+		Name:     contractName,
+	}, nil
+}
+
+// Get the ABI that was registered for a network contract on the given block.
+// Previous versions are resolved from the legacy contract wrappers if possible, falling back to the value of
+// contract.abi in RocketStorage at that block (which requires an archive node for old blocks).
+func (h *UpgradeHistory) GetABIAtBlock(contractName string, block uint64) (*abi.ABI, error) {
+
+	// Get the address that was active on the block; this brings the history up to date
+	address, err := h.GetContractAddressAtBlock(contractName, block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the ABI version that was active on the block
+	name := crypto.Keccak256Hash([]byte(contractName))
+	h.lock.Lock()
+	key, isCurrent := h.getAbiVersion(name, block)
+	cached, isCached := h.abis[key]
+	h.lock.Unlock()
+
+	// Use the regular lookup for the current version
+	if isCurrent {
+		return h.rp.GetABI(contractName, nil)
+	}
+	if isCached {
+		return cached, nil
+	}
+
+	// Check the legacy wrappers for a contract that was registered at the same address
+	abi, err := h.getLegacyABI(contractName, address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fall back to the historical value in RocketStorage
+	if abi == nil {
+		opts := &bind.CallOpts{BlockNumber: new(big.Int).SetUint64(block)}
+		abiEncoded, err := h.rp.RocketStorage.GetString(opts, crypto.Keccak256Hash([]byte("contract.abi"), []byte(contractName)))
+		if err != nil {
+			return nil, fmt.Errorf("Could not load contract %s ABI at block %d: %w", contractName, block, err)
+		}
+		abi, err = DecodeAbi(abiEncoded)
+		if err != nil {
+			return nil, fmt.Errorf("Could not decode contract %s ABI at block %d: %w", contractName, block, err)
+		}
+	}
+
+	// Cache and return
+	h.lock.Lock()
+	h.abis[key] = abi
+	h.lock.Unlock()
+	return abi, nil
+
+}
+
+// Get the ABI version that was active on the given block, and whether or not it's the current one
+func (h *UpgradeHistory) getAbiVersion(name common.Hash, block uint64) (abiVersionKey, bool) {
+	key := abiVersionKey{
+		name:       name,
+		startBlock: h.deployBlock,
+	}
+	for _, changeBlock := range h.abiChanges[name] {
+		if changeBlock > block {
+			return key, false
+		}
+		key.startBlock = changeBlock
+	}
+	return key, true
+}
+
+// Get the ABI for a legacy version of a contract deployed at the provided address, or nil if none of them match
+func (h *UpgradeHistory) getLegacyABI(contractName string, address common.Address) (*abi.ABI, error) {
+
+	// Check the legacy versions from newest to oldest
+	vm := h.rp.VersionManager
+	for _, wrapper := range []LegacyVersionWrapper{vm.V1_1_0, vm.V1_1_0_RC1, vm.V1_0_0} {
+		legacyName, exists := wrapper.GetVersionedContractName(contractName)
+		if !exists {
+			continue
+		}
+		legacyAddress, err := h.rp.GetAddress(legacyName, nil)
+		if err != nil {
+			return nil, err
+		}
+		if *legacyAddress != address {
+			continue
+		}
+		abi, err := DecodeAbi(wrapper.GetEncodedABI(contractName))
+		if err != nil {
+			return nil, fmt.Errorf("Could not decode v%s contract %s ABI: %w", wrapper.GetVersion().String(), contractName, err)
+		}
+		return abi, nil
+	}
+	return nil, nil
+
+}
@@ -0,0 +1,54 @@
+package rocketpool
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Records the contract methods called through the library, so upgrades can be checked against them.
+// Install it with SetInstrumentation, or combine it with other instrumentation using MultiInstrumentation.
+type CallRecorder struct {
+	lock    sync.Mutex
+	methods map[string]map[string]bool
+}
+
+// Create a call recorder
+func NewCallRecorder() *CallRecorder {
+	return &CallRecorder{
+		methods: map[string]map[string]bool{},
+	}
+}
+
+// Record a contract call, transaction or gas estimate
+func (r *CallRecorder) Begin(ctx context.Context, op Operation) (context.Context, func(err error)) {
+	switch op.Kind {
+	case OperationContractCall, OperationContractTransact, OperationEstimateGas:
+		if op.Method == "" {
+			break
+		}
+		r.lock.Lock()
+		if r.methods[op.Contract] == nil {
+			r.methods[op.Contract] = map[string]bool{}
+		}
+		r.methods[op.Contract][op.Method] = true
+		r.lock.Unlock()
+	}
+	return ctx, noopFinish
+}
+
+// Get the methods called on a contract, in alphabetical order.
+// Returns nil if no calls to the contract were recorded, since the methods it needs are unknown rather than none.
+func (r *CallRecorder) GetCalledMethods(contractName string) []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.methods[contractName]) == 0 {
+		return nil
+	}
+	methods := make([]string, 0, len(r.methods[contractName]))
+	for method := range r.methods[contractName] {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
@@ -30,6 +30,7 @@ type Contract struct {
 	Address  *common.Address
 	ABI      *abi.ABI
 	Client   ExecutionClient
+	Name     string
 }
 
 // Response for gas limits from network and from user request
@@ -42,7 +43,33 @@ type GasInfo struct {
 func (c *Contract) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
 	results := make([]interface{}, 1)
 	results[0] = result
-	return c.Contract.Call(opts, &results, method, params...)
+	if GetInstrumentation() == nil {
+		return c.Contract.Call(opts, &results, method, params...)
+	}
+
+	// Instrument the call and pass its context through to the client
+	var ctx context.Context
+	instrumentedOpts := &bind.CallOpts{}
+	if opts != nil {
+		*instrumentedOpts = *opts
+		ctx = opts.Context
+	}
+	ctx, finish := BeginOperation(ctx, Operation{Kind: OperationContractCall, Contract: c.GetName(), Method: method})
+	instrumentedOpts.Context = ctx
+	err := c.Contract.Call(instrumentedOpts, &results, method, params...)
+	finish(err)
+	return err
+}
+
+// Get the name of the contract for instrumentation, falling back to its address if it isn't known
+func (c *Contract) GetName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	if c.Address != nil {
+		return c.Address.Hex()
+	}
+	return ""
 }
 
 // Get Gas Limit for transaction
@@ -84,11 +111,21 @@ func (c *Contract) Transact(opts *bind.TransactOpts, method string, params ...in
 		opts.GasLimit = safeGasLimit
 	}
 
-	// Send transaction
-	tx, err := c.Contract.Transact(opts, method, params...)
+	// Send transaction, passing the instrumentation context through to the client
+	ctx, finish := BeginOperation(opts.Context, Operation{Kind: OperationContractTransact, Contract: c.GetName(), Method: method})
+	txOpts := opts
+	if GetInstrumentation() != nil {
+		txOpts = &bind.TransactOpts{}
+		*txOpts = *opts
+		txOpts.Context = ctx
+	}
+	tx, err := c.Contract.Transact(txOpts, method, params...)
 	if err != nil {
-		return nil, c.normalizeErrorMessage(err)
+		err = c.normalizeErrorMessage(err)
+		finish(err)
+		return nil, err
 	}
+	finish(nil)
 
 	return tx, nil
 
@@ -136,13 +173,19 @@ func (c *Contract) Transfer(opts *bind.TransactOpts) (common.Hash, error) {
 func (c *Contract) estimateGasLimit(opts *bind.TransactOpts, input []byte) (uint64, uint64, error) {
 
 	// Estimate gas limit
-	gasLimit, err := c.Client.EstimateGas(context.Background(), ethereum.CallMsg{
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, finish := BeginOperation(ctx, Operation{Kind: OperationEstimateGas, Contract: c.GetName(), Method: c.getMethodName(input)})
+	gasLimit, err := c.Client.EstimateGas(ctx, ethereum.CallMsg{
 		From:     opts.From,
 		To:       c.Address,
 		GasPrice: big.NewInt(0), // use 0 gwei for simulation
 		Value:    opts.Value,
 		Data:     input,
 	})
+	finish(err)
 
 	if err != nil {
 		return 0, 0, fmt.Errorf("Could not estimate gas needed: %w", c.normalizeErrorMessage(err))
@@ -160,6 +203,18 @@ func (c *Contract) estimateGasLimit(opts *bind.TransactOpts, input []byte) (uint
 
 }
 
+// Get the name of the method that the input data calls
+func (c *Contract) getMethodName(input []byte) string {
+	if len(input) < 4 || GetInstrumentation() == nil {
+		return ""
+	}
+	method, err := c.ABI.MethodById(input[:4])
+	if err != nil {
+		return ""
+	}
+	return method.Name
+}
+
 // Wait for a transaction to be mined and get a tx receipt
 func (c *Contract) getTransactionReceipt(tx *types.Transaction) (*types.Receipt, error) {
 
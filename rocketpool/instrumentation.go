@@ -0,0 +1,87 @@
+package rocketpool
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// The type of operation being instrumented
+type OperationKind string
+
+const (
+	OperationContractCall     OperationKind = "call"
+	OperationContractTransact OperationKind = "transact"
+	OperationEstimateGas      OperationKind = "estimate_gas"
+	OperationMulticall        OperationKind = "multicall"
+	OperationClient           OperationKind = "client"
+)
+
+// An instrumented operation against the execution client
+type Operation struct {
+	Kind      OperationKind
+	Contract  string // The contract name, or its address if the name isn't known
+	Method    string // The contract method or the execution client method
+	BatchSize int    // The number of calls in a multicall batch
+}
+
+// Receives instrumentation events for contract calls and execution client requests.
+// Begin is called before the operation starts; the returned function is called with the result once it finishes.
+type Instrumentation interface {
+	Begin(ctx context.Context, op Operation) (context.Context, func(err error))
+}
+
+// Wrapper so the active instrumentation can be stored atomically
+type instrumentationHolder struct {
+	instrumentation Instrumentation
+}
+
+// The active instrumentation
+var activeInstrumentation atomic.Value
+
+// Finish function used when instrumentation is disabled
+func noopFinish(error) {}
+
+// Set the instrumentation used by all contracts and instrumented clients; nil disables it
+func SetInstrumentation(instrumentation Instrumentation) {
+	activeInstrumentation.Store(instrumentationHolder{instrumentation: instrumentation})
+}
+
+// Get the active instrumentation, or nil if it's disabled
+func GetInstrumentation() Instrumentation {
+	holder, ok := activeInstrumentation.Load().(instrumentationHolder)
+	if !ok {
+		return nil
+	}
+	return holder.instrumentation
+}
+
+// Begin an instrumented operation; this is a no-op if instrumentation is disabled
+func BeginOperation(ctx context.Context, op Operation) (context.Context, func(err error)) {
+	instrumentation := GetInstrumentation()
+	if instrumentation == nil {
+		return ctx, noopFinish
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return instrumentation.Begin(ctx, op)
+}
+
+// Combine several instrumentations into one
+func MultiInstrumentation(instrumentations ...Instrumentation) Instrumentation {
+	return multiInstrumentation(instrumentations)
+}
+
+type multiInstrumentation []Instrumentation
+
+func (m multiInstrumentation) Begin(ctx context.Context, op Operation) (context.Context, func(err error)) {
+	finishers := make([]func(error), len(m))
+	for i, instrumentation := range m {
+		ctx, finishers[i] = instrumentation.Begin(ctx, op)
+	}
+	return ctx, func(err error) {
+		for i := len(finishers) - 1; i >= 0; i-- {
+			finishers[i](err)
+		}
+	}
+}
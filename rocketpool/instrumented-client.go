@@ -0,0 +1,192 @@
+package rocketpool
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// An execution client that reports every request to the active instrumentation
+type InstrumentedClient struct {
+	client ExecutionClient
+}
+
+// Wrap an execution client so its requests are instrumented.
+// Requests are passed straight through to the underlying client while instrumentation is disabled.
+func NewInstrumentedClient(client ExecutionClient) *InstrumentedClient {
+	return &InstrumentedClient{
+		client: client,
+	}
+}
+
+// Get the underlying client
+func (c *InstrumentedClient) Unwrap() ExecutionClient {
+	return c.client
+}
+
+// Begin an instrumented client request
+func (c *InstrumentedClient) begin(ctx context.Context, method string) (context.Context, func(error)) {
+	return BeginOperation(ctx, Operation{Kind: OperationClient, Method: method})
+}
+
+func (c *InstrumentedClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	ctx, finish := c.begin(ctx, "eth_getCode")
+	result, err := c.client.CodeAt(ctx, contract, blockNumber)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	ctx, finish := c.begin(ctx, "eth_call")
+	result, err := c.client.CallContract(ctx, call, blockNumber)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	ctx, finish := c.begin(ctx, "eth_getBlockByHash")
+	result, err := c.client.HeaderByHash(ctx, hash)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	ctx, finish := c.begin(ctx, "eth_getBlockByNumber")
+	result, err := c.client.HeaderByNumber(ctx, number)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	ctx, finish := c.begin(ctx, "eth_getCode")
+	result, err := c.client.PendingCodeAt(ctx, account)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	ctx, finish := c.begin(ctx, "eth_getTransactionCount")
+	result, err := c.client.PendingNonceAt(ctx, account)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	ctx, finish := c.begin(ctx, "eth_gasPrice")
+	result, err := c.client.SuggestGasPrice(ctx)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	ctx, finish := c.begin(ctx, "eth_maxPriorityFeePerGas")
+	result, err := c.client.SuggestGasTipCap(ctx)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	ctx, finish := c.begin(ctx, "eth_estimateGas")
+	result, err := c.client.EstimateGas(ctx, call)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	ctx, finish := c.begin(ctx, "eth_sendRawTransaction")
+	err := c.client.SendTransaction(ctx, tx)
+	finish(err)
+	return err
+}
+
+func (c *InstrumentedClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	ctx, finish := c.begin(ctx, "eth_getLogs")
+	result, err := c.client.FilterLogs(ctx, query)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	ctx, finish := c.begin(ctx, "eth_subscribe")
+	result, err := c.client.SubscribeFilterLogs(ctx, query, ch)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	ctx, finish := c.begin(ctx, "eth_getTransactionReceipt")
+	result, err := c.client.TransactionReceipt(ctx, txHash)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) BlockNumber(ctx context.Context) (uint64, error) {
+	ctx, finish := c.begin(ctx, "eth_blockNumber")
+	result, err := c.client.BlockNumber(ctx)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	ctx, finish := c.begin(ctx, "eth_getBalance")
+	result, err := c.client.BalanceAt(ctx, account, blockNumber)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	ctx, finish := c.begin(ctx, "eth_getTransactionByHash")
+	tx, isPending, err := c.client.TransactionByHash(ctx, hash)
+	finish(err)
+	return tx, isPending, err
+}
+
+func (c *InstrumentedClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	ctx, finish := c.begin(ctx, "eth_getTransactionCount")
+	result, err := c.client.NonceAt(ctx, account, blockNumber)
+	finish(err)
+	return result, err
+}
+
+func (c *InstrumentedClient) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) {
+	ctx, finish := c.begin(ctx, "eth_syncing")
+	result, err := c.client.SyncProgress(ctx)
+	finish(err)
+	return result, err
+}
+
+// A contract backend that reports calls to a single contract as contract calls, for abigen bindings that don't go through Contract
+type instrumentedContractBackend struct {
+	ExecutionClient
+	contractName string
+	abi          *abi.ABI
+}
+
+// Wrap an execution client so calls made by a contract binding are instrumented
+func newInstrumentedContractBackend(client ExecutionClient, contractName string, contractAbi *abi.ABI) *instrumentedContractBackend {
+	return &instrumentedContractBackend{
+		ExecutionClient: client,
+		contractName:    contractName,
+		abi:             contractAbi,
+	}
+}
+
+func (b *instrumentedContractBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if GetInstrumentation() == nil {
+		return b.ExecutionClient.CallContract(ctx, call, blockNumber)
+	}
+	var methodName string
+	if len(call.Data) >= 4 {
+		if method, err := b.abi.MethodById(call.Data[:4]); err == nil {
+			methodName = method.Name
+		}
+	}
+	ctx, finish := BeginOperation(ctx, Operation{Kind: OperationContractCall, Contract: b.contractName, Method: methodName})
+	result, err := b.ExecutionClient.CallContract(ctx, call, blockNumber)
+	finish(err)
+	return result, err
+}
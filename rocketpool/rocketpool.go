@@ -18,6 +18,9 @@ import (
 // Cache settings
 const CacheTTL = 300 // 5 minutes
 
+// The name RocketStorage is reported with in instrumentation
+const rocketStorageContractName = "rocketStorage"
+
 // Cached data types
 type cachedAddress struct {
 	address *common.Address
@@ -38,6 +41,7 @@ type RocketPool struct {
 	RocketStorage         *contracts.RocketStorage
 	RocketStorageContract *Contract
 	VersionManager        *VersionManager
+	UpgradeHistory        *UpgradeHistory
 	addresses             map[string]cachedAddress
 	abis                  map[string]cachedABI
 	contracts             map[string]cachedContract
@@ -50,21 +54,22 @@ type RocketPool struct {
 func NewRocketPool(client ExecutionClient, rocketStorageAddress common.Address) (*RocketPool, error) {
 
 	// Initialize RocketStorage contract
-	rocketStorage, err := contracts.NewRocketStorage(rocketStorageAddress, client)
+	rsAbi, err := abi.JSON(strings.NewReader(contracts.RocketStorageABI))
+	if err != nil {
+		return nil, err
+	}
+	rocketStorage, err := contracts.NewRocketStorage(rocketStorageAddress, newInstrumentedContractBackend(client, rocketStorageContractName, &rsAbi))
 	if err != nil {
 		return nil, fmt.Errorf("Could not initialize Rocket Pool storage contract: %w", err)
 	}
 
 	// Create a Contract for it
-	rsAbi, err := abi.JSON(strings.NewReader(contracts.RocketStorageABI))
-	if err != nil {
-		return nil, err
-	}
 	contract := &Contract{
 		Contract: bind.NewBoundContract(rocketStorageAddress, rsAbi, client, client, client),
 		Address:  &rocketStorageAddress,
 		ABI:      &rsAbi,
 		Client:   client,
+		Name:     rocketStorageContractName,
 	}
 
 	// Create and return
@@ -77,6 +82,7 @@ func NewRocketPool(client ExecutionClient, rocketStorageAddress common.Address)
 		contracts:             make(map[string]cachedContract),
 	}
 	rp.VersionManager = NewVersionManager(rp)
+	rp.UpgradeHistory = NewUpgradeHistory(rp)
 
 	return rp, nil
 
@@ -99,7 +105,15 @@ func (rp *RocketPool) GetAddress(contractName string, opts *bind.CallOpts) (*com
 	// Get address
 	address, err := rp.RocketStorage.GetAddress(opts, crypto.Keccak256Hash([]byte("contract.address"), []byte(contractName)))
 	if err != nil {
-		return nil, fmt.Errorf("Could not load contract %s address: %w", contractName, err)
+		// Fall back to the upgrade history if the node doesn't have the state for the block
+		if opts == nil || opts.BlockNumber == nil {
+			return nil, fmt.Errorf("Could not load contract %s address: %w", contractName, err)
+		}
+		historicalAddress, historyErr := rp.GetContractAddressAtBlock(contractName, opts.BlockNumber.Uint64())
+		if historyErr != nil {
+			return nil, fmt.Errorf("Could not load contract %s address: %w", contractName, err)
+		}
+		address = historicalAddress
 	}
 
 	// Cache address
@@ -160,7 +174,15 @@ func (rp *RocketPool) GetABI(contractName string, opts *bind.CallOpts) (*abi.ABI
 	// Get ABI
 	abiEncoded, err := rp.RocketStorage.GetString(opts, crypto.Keccak256Hash([]byte("contract.abi"), []byte(contractName)))
 	if err != nil {
-		return nil, fmt.Errorf("Could not load contract %s ABI: %w", contractName, err)
+		// Fall back to the upgrade history if the node doesn't have the state for the block
+		if opts == nil || opts.BlockNumber == nil {
+			return nil, fmt.Errorf("Could not load contract %s ABI: %w", contractName, err)
+		}
+		abi, historyErr := rp.GetABIAtBlock(contractName, opts.BlockNumber.Uint64())
+		if historyErr != nil {
+			return nil, fmt.Errorf("Could not load contract %s ABI: %w", contractName, err)
+		}
+		return abi, nil
 	}
 
 	// Decode ABI
@@ -251,13 +273,16 @@ func (rp *RocketPool) GetContract(contractName string, opts *bind.CallOpts) (*Co
 		Address:  address,
 		ABI:      abi,
 		Client:   rp.Client,
+		Name:     contractName,
 	}
 
-	// Cache contract
-	rp.setCachedContract(contractName, cachedContract{
-		contract: contract,
-		time:     time.Now().Unix(),
-	})
+	// Cache contract; contracts loaded at a specific block may use an older address or ABI
+	if opts == nil || opts.BlockNumber == nil {
+		rp.setCachedContract(contractName, cachedContract{
+			contract: contract,
+			time:     time.Now().Unix(),
+		})
+	}
 
 	// Return
 	return contract, nil
@@ -306,6 +331,7 @@ func (rp *RocketPool) MakeContract(contractName string, address common.Address,
 		Address:  &address,
 		ABI:      abi,
 		Client:   rp.Client,
+		Name:     contractName,
 	}, nil
 
 }
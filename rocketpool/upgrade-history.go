@@ -0,0 +1,352 @@
+package rocketpool
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The default number of blocks to scan for upgrade events at once
+const DefaultUpgradeHistoryIntervalSize uint64 = 10000
+
+// The name of the contract that emits the upgrade events
+const upgradeContractName string = "poolseaDAONodeTrustedUpgrade"
+
+// An address a network contract was registered at, and the range of blocks it was active for
+type ContractAddressRange struct {
+	Address    common.Address `json:"address"`
+	StartBlock uint64         `json:"startBlock"`
+	EndBlock   uint64         `json:"endBlock"`
+	IsCurrent  bool           `json:"isCurrent"`
+}
+
+// Check if the address was the registered one at the given block
+func (r ContractAddressRange) IsActiveAt(block uint64) bool {
+	return block >= r.StartBlock && (r.IsCurrent || block <= r.EndBlock)
+}
+
+// Registry of every address the network contracts have been deployed at, built from the upgrade contract's events.
+// The chain is only scanned once; subsequent lookups only scan the blocks that were added since the last one.
+type UpgradeHistory struct {
+	// The number of blocks to scan for events at once, used when a lookup doesn't provide one
+	IntervalSize *big.Int
+
+	rp                       *RocketPool
+	ranges                   map[common.Hash][]ContractAddressRange
+	abiChanges               map[common.Hash][]uint64
+	abis                     map[abiVersionKey]*abi.ABI
+	upgradeContractAddresses []common.Address
+	deployBlock              uint64
+	scannedBlock             uint64
+	loaded                   bool
+	lock                     sync.Mutex
+}
+
+// Create a new upgrade history registry
+func NewUpgradeHistory(rp *RocketPool) *UpgradeHistory {
+	return &UpgradeHistory{
+		rp:           rp,
+		IntervalSize: new(big.Int).SetUint64(DefaultUpgradeHistoryIntervalSize),
+		ranges:       make(map[common.Hash][]ContractAddressRange),
+		abiChanges:   make(map[common.Hash][]uint64),
+		abis:         make(map[abiVersionKey]*abi.ABI),
+	}
+}
+
+// Get the address a network contract was registered at on the given block
+func (rp *RocketPool) GetContractAddressAtBlock(contractName string, block uint64) (common.Address, error) {
+	return rp.UpgradeHistory.GetContractAddressAtBlock(contractName, block)
+}
+
+// Get the address a network contract was registered at on the given block
+func (h *UpgradeHistory) GetContractAddressAtBlock(contractName string, block uint64) (common.Address, error) {
+	ranges, err := h.GetContractAddressRanges(contractName, nil, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	for i := len(ranges) - 1; i >= 0; i-- {
+		if ranges[i].IsActiveAt(block) {
+			return ranges[i].Address, nil
+		}
+	}
+	return common.Address{}, fmt.Errorf("Contract %s was not registered on block %d", contractName, block)
+}
+
+// Get every address a network contract has been registered at up to the block in opts (or the latest block if not set),
+// ordered from oldest to newest. The interval size defaults to the history's IntervalSize if nil.
+func (h *UpgradeHistory) GetContractAddressRanges(contractName string, intervalSize *big.Int, opts *bind.CallOpts) ([]ContractAddressRange, error) {
+
+	// Copy the ranges out of the cache, catching up on any new upgrades first
+	if err := h.update(intervalSize); err != nil {
+		return nil, err
+	}
+	h.lock.Lock()
+	ranges := append([]ContractAddressRange{}, h.ranges[crypto.Keccak256Hash([]byte(contractName))]...)
+	deployBlock := h.deployBlock
+	h.lock.Unlock()
+
+	// Contracts that were never added or upgraded after the initial deployment have no events
+	if len(ranges) == 0 {
+		currentAddress, err := h.rp.GetAddress(contractName, opts)
+		if err != nil {
+			return nil, err
+		}
+		return []ContractAddressRange{{
+			Address:    *currentAddress,
+			StartBlock: deployBlock,
+			IsCurrent:  true,
+		}}, nil
+	}
+
+	// Drop the addresses that were registered after the target block
+	if opts != nil && opts.BlockNumber != nil {
+		block := opts.BlockNumber.Uint64()
+		for len(ranges) > 0 && ranges[len(ranges)-1].StartBlock > block {
+			ranges = ranges[:len(ranges)-1]
+		}
+		if len(ranges) == 0 {
+			return nil, fmt.Errorf("Contract %s was not registered on block %d", contractName, block)
+		}
+	}
+	return ranges, nil
+
+}
+
+// Get every address a network contract has been registered at up to the block in opts (or the latest block if not set),
+// ordered from oldest to newest
+func (h *UpgradeHistory) GetContractAddresses(contractName string, intervalSize *big.Int, opts *bind.CallOpts) ([]common.Address, error) {
+	ranges, err := h.GetContractAddressRanges(contractName, intervalSize, opts)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]common.Address, len(ranges))
+	for i, r := range ranges {
+		addresses[i] = r.Address
+	}
+	return addresses, nil
+}
+
+// Scan the upgrade contract's events for any blocks that haven't been processed yet.
+// The chain is scanned without holding the lock, so lookups from the cache aren't blocked while the logs are loaded.
+func (h *UpgradeHistory) update(intervalSize *big.Int) error {
+
+	// Never scan the whole chain in one call, since most providers reject it
+	if intervalSize == nil {
+		intervalSize = h.IntervalSize
+	}
+	if intervalSize == nil || intervalSize.Sign() <= 0 {
+		intervalSize = new(big.Int).SetUint64(DefaultUpgradeHistoryIntervalSize)
+	}
+
+	// Get the scan state
+	h.lock.Lock()
+	loaded := h.loaded
+	deployBlock := h.deployBlock
+	scannedBlock := h.scannedBlock
+	h.lock.Unlock()
+
+	// Get the block Rocket Pool was deployed on
+	if !loaded {
+		deployBlockValue, err := h.rp.RocketStorage.GetUint(nil, crypto.Keccak256Hash([]byte("deploy.block")))
+		if err != nil {
+			return fmt.Errorf("Could not get deploy block: %w", err)
+		}
+		deployBlock = deployBlockValue.Uint64()
+	}
+
+	// Get the range to scan
+	latestBlock, err := h.rp.Client.BlockNumber(context.Background())
+	if err != nil {
+		return fmt.Errorf("Could not get latest block: %w", err)
+	}
+	startBlock := deployBlock
+	if loaded {
+		startBlock = scannedBlock + 1
+	}
+	if startBlock > latestBlock {
+		return nil
+	}
+
+	// Get the upgrade contract
+	rocketDaoNodeTrustedUpgrade, err := h.rp.GetContract(upgradeContractName, nil)
+	if err != nil {
+		return err
+	}
+	upgradedEvent, exists := rocketDaoNodeTrustedUpgrade.ABI.Events["ContractUpgraded"]
+	if !exists {
+		return fmt.Errorf("Upgrade contract ABI does not contain a ContractUpgraded event")
+	}
+	eventIds := []common.Hash{upgradedEvent.ID}
+	addedEvent, hasAddedEvent := rocketDaoNodeTrustedUpgrade.ABI.Events["ContractAdded"]
+	if hasAddedEvent {
+		eventIds = append(eventIds, addedEvent.ID)
+	}
+	for _, abiEventName := range []string{"ABIUpgraded", "ABIAdded"} {
+		if abiEvent, exists := rocketDaoNodeTrustedUpgrade.ABI.Events[abiEventName]; exists {
+			eventIds = append(eventIds, abiEvent.ID)
+		}
+	}
+
+	// Get the logs from any address, since each deployment of the upgrade contract emitted the events for its own upgrades
+	topicFilter := [][]common.Hash{eventIds}
+	logs, err := filterLogs(h.rp.Client, nil, topicFilter, intervalSize, startBlock, latestBlock)
+	if err != nil {
+		return fmt.Errorf("Could not get contract upgrade events: %w", err)
+	}
+
+	// Merge the logs into the history, skipping any blocks another lookup merged while these were loading
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.loaded && h.scannedBlock >= latestBlock {
+		return nil
+	}
+	var mergedBlock uint64
+	if h.loaded {
+		mergedBlock = h.scannedBlock
+	} else {
+		h.deployBlock = deployBlock
+	}
+	h.addUpgradeContractAddresses(*rocketDaoNodeTrustedUpgrade.Address, upgradedEvent.ID, logs)
+
+	// Process the logs in order
+	upgradeContractNameHash := crypto.Keccak256Hash([]byte(upgradeContractName))
+	for _, log := range logs {
+		if len(log.Topics) < 2 || (h.loaded && log.BlockNumber <= mergedBlock) || !containsAddress(h.upgradeContractAddresses, log.Address) {
+			continue
+		}
+
+		// Every event from the upgrade contract replaces the ABI for the name
+		h.addAbiChange(log.Topics[1], log.BlockNumber)
+
+		switch {
+		case log.Topics[0] == upgradedEvent.ID && len(log.Topics) >= 4:
+			name := log.Topics[1]
+			oldAddress := common.BytesToAddress(log.Topics[2].Bytes())
+			newAddress := common.BytesToAddress(log.Topics[3].Bytes())
+			ranges := h.ranges[name]
+			if len(ranges) == 0 {
+				// The old address was part of the initial deployment
+				ranges = append(ranges, ContractAddressRange{
+					Address:    oldAddress,
+					StartBlock: h.deployBlock,
+				})
+			} else {
+				ranges[len(ranges)-1].IsCurrent = false
+			}
+			ranges[len(ranges)-1].EndBlock = previousBlock(log.BlockNumber)
+			h.ranges[name] = append(ranges, ContractAddressRange{
+				Address:    newAddress,
+				StartBlock: log.BlockNumber,
+				IsCurrent:  true,
+			})
+
+			// Follow upgrades of the upgrade contract itself
+			if name == upgradeContractNameHash && !containsAddress(h.upgradeContractAddresses, newAddress) {
+				h.upgradeContractAddresses = append(h.upgradeContractAddresses, newAddress)
+			}
+
+		case hasAddedEvent && log.Topics[0] == addedEvent.ID && len(log.Topics) >= 3:
+			name := log.Topics[1]
+			newAddress := common.BytesToAddress(log.Topics[2].Bytes())
+			h.ranges[name] = append(h.ranges[name], ContractAddressRange{
+				Address:    newAddress,
+				StartBlock: log.BlockNumber,
+				IsCurrent:  true,
+			})
+		}
+	}
+
+	// Mark the scan as complete
+	h.scannedBlock = latestBlock
+	h.loaded = true
+	return nil
+
+}
+
+// Add the current upgrade contract to the known deployments, along with the deployments before it.
+// Earlier deployments are found by following the upgrade contract's own upgrade events back from the current one;
+// each of those events must have been emitted by the address it upgraded from.
+func (h *UpgradeHistory) addUpgradeContractAddresses(currentAddress common.Address, upgradedEventId common.Hash, logs []types.Log) {
+	name := crypto.Keccak256Hash([]byte(upgradeContractName))
+	address := currentAddress
+	endIndex := len(logs)
+	for !containsAddress(h.upgradeContractAddresses, address) {
+		h.upgradeContractAddresses = append(h.upgradeContractAddresses, address)
+		found := false
+		for i := endIndex - 1; i >= 0; i-- {
+			log := logs[i]
+			if len(log.Topics) < 4 || log.Topics[0] != upgradedEventId || log.Topics[1] != name {
+				continue
+			}
+			oldAddress := common.BytesToAddress(log.Topics[2].Bytes())
+			if common.BytesToAddress(log.Topics[3].Bytes()) == address && log.Address == oldAddress {
+				address = oldAddress
+				endIndex = i
+				found = true
+				break
+			}
+		}
+		if !found {
+			return
+		}
+	}
+}
+
+// Record a block that the ABI for a contract name changed on
+func (h *UpgradeHistory) addAbiChange(name common.Hash, block uint64) {
+	changes := h.abiChanges[name]
+	if len(changes) > 0 && changes[len(changes)-1] == block {
+		return
+	}
+	h.abiChanges[name] = append(changes, block)
+}
+
+// Check if an address is in a list
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, candidate := range addresses {
+		if candidate == address {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the block before the provided one, clamping at 0
+func previousBlock(block uint64) uint64 {
+	if block == 0 {
+		return 0
+	}
+	return block - 1
+}
+
+// Get the logs for a range of blocks, breaking the calls into batches of intervalSize blocks
+func filterLogs(client ExecutionClient, addressFilter []common.Address, topicFilter [][]common.Hash, intervalSize *big.Int, fromBlock uint64, toBlock uint64) ([]types.Log, error) {
+	var logs []types.Log
+	interval := intervalSize.Uint64()
+	for start := fromBlock; start <= toBlock; start += interval {
+		end := start + interval - 1
+		if end > toBlock {
+			end = toBlock
+		}
+		newLogs, err := client.FilterLogs(context.Background(), ethereum.FilterQuery{
+			Addresses: addressFilter,
+			Topics:    topicFilter,
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+		})
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, newLogs...)
+	}
+	return logs, nil
+
+}
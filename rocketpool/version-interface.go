@@ -46,6 +46,7 @@ func GetContractVersion(rp *RocketPool, contractAddress common.Address, opts *bi
 		Address:  &contractAddress,
 		ABI:      versionAbi,
 		Client:   rp.Client,
+		Name:     "rocketVersion",
 	}
 
 	// Get the contract version
@@ -73,5 +74,6 @@ func GetRocketVersionContractForAddress(rp *RocketPool, address common.Address)
 		Address:  &address,
 		ABI:      versionAbi,
 		Client:   rp.Client,
+		Name:     "rocketVersion",
 	}, nil
 }
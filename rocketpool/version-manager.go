@@ -78,6 +78,7 @@ func getLegacyContract(rp *RocketPool, contractName string, m LegacyVersionWrapp
 		Address:  &address,
 		ABI:      abi,
 		Client:   rp.Client,
+		Name:     contractName,
 	}
 
 	return contract, nil
@@ -98,6 +99,7 @@ func getLegacyContractWithAddress(rp *RocketPool, contractName string, address c
 		Address:  &address,
 		ABI:      abi,
 		Client:   rp.Client,
+		Name:     contractName,
 	}
 
 	return contract, nil
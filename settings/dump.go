@@ -0,0 +1,414 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/dao"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/protocol"
+	"github.com/Seb369888/poolsea-go/settings/trustednode"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+	"github.com/Seb369888/poolsea-go/utils/multicall"
+)
+
+// Protocol DAO auction settings
+type AuctionSettings struct {
+	CreateLotEnabled      *bool    `json:"createLotEnabled"`
+	BidOnLotEnabled       *bool    `json:"bidOnLotEnabled"`
+	LotMinimumEthValue    *big.Int `json:"lotMinimumEthValue"`
+	LotMaximumEthValue    *big.Int `json:"lotMaximumEthValue"`
+	LotDuration           *big.Int `json:"lotDuration"`
+	LotStartingPriceRatio *big.Int `json:"lotStartingPriceRatio"`
+	LotReservePriceRatio  *big.Int `json:"lotReservePriceRatio"`
+}
+
+// Protocol DAO deposit settings
+type DepositSettings struct {
+	DepositEnabled            *bool    `json:"depositEnabled"`
+	AssignDepositsEnabled     *bool    `json:"assignDepositsEnabled"`
+	MinimumDeposit            *big.Int `json:"minimumDeposit"`
+	MaximumDepositPoolSize    *big.Int `json:"maximumDepositPoolSize"`
+	MaximumDepositAssignments *big.Int `json:"maximumDepositAssignments"`
+}
+
+// Protocol DAO inflation settings
+type InflationSettings struct {
+	IntervalRate *big.Int `json:"intervalRate"`
+	StartTime    *big.Int `json:"startTime"`
+}
+
+// Protocol DAO minipool settings
+type MinipoolSettings struct {
+	LaunchBalance             *big.Int `json:"launchBalance"`
+	SubmitWithdrawableEnabled *bool    `json:"submitWithdrawableEnabled"`
+	LaunchTimeout             *big.Int `json:"launchTimeout"`
+	BondReductionEnabled      *bool    `json:"bondReductionEnabled"`
+}
+
+// Protocol DAO network settings
+type NetworkSettings struct {
+	NodeConsensusThreshold   *big.Int `json:"nodeConsensusThreshold"`
+	SubmitBalancesEnabled    *bool    `json:"submitBalancesEnabled"`
+	SubmitBalancesFrequency  *big.Int `json:"submitBalancesFrequency"`
+	SubmitPricesEnabled      *bool    `json:"submitPricesEnabled"`
+	SubmitPricesFrequency    *big.Int `json:"submitPricesFrequency"`
+	MinimumNodeFee           *big.Int `json:"minimumNodeFee"`
+	TargetNodeFee            *big.Int `json:"targetNodeFee"`
+	MaximumNodeFee           *big.Int `json:"maximumNodeFee"`
+	NodeFeeDemandRange       *big.Int `json:"nodeFeeDemandRange"`
+	TargetRethCollateralRate *big.Int `json:"targetRethCollateralRate"`
+}
+
+// Protocol DAO node settings
+type NodeSettings struct {
+	RegistrationEnabled     *bool    `json:"registrationEnabled"`
+	DepositEnabled          *bool    `json:"depositEnabled"`
+	VacantMinipoolsEnabled  *bool    `json:"vacantMinipoolsEnabled"`
+	MinimumPerMinipoolStake *big.Int `json:"minimumPerMinipoolStake"`
+	MaximumPerMinipoolStake *big.Int `json:"maximumPerMinipoolStake"`
+}
+
+// Protocol DAO rewards settings
+type RewardsSettings struct {
+	ClaimIntervalTime       *big.Int `json:"claimIntervalTime"`
+	NodeOperatorClaimerPerc *big.Int `json:"nodeOperatorClaimerPerc"`
+	TrustedNodeClaimerPerc  *big.Int `json:"trustedNodeClaimerPerc"`
+	ProtocolDaoClaimerPerc  *big.Int `json:"protocolDaoClaimerPerc"`
+	ClaimersPercTotal       *big.Int `json:"claimersPercTotal"`
+}
+
+// Oracle DAO member settings
+type MembersSettings struct {
+	Quorum                 *big.Int `json:"quorum"`
+	RPLBond                *big.Int `json:"rplBond"`
+	MinipoolUnbondedMax    *big.Int `json:"minipoolUnbondedMax"`
+	MinipoolUnbondedMinFee *big.Int `json:"minipoolUnbondedMinFee"`
+	ChallengeCooldown      *big.Int `json:"challengeCooldown"`
+	ChallengeWindow        *big.Int `json:"challengeWindow"`
+	ChallengeCost          *big.Int `json:"challengeCost"`
+}
+
+// Oracle DAO proposal settings
+type ProposalsSettings struct {
+	CooldownTime  *big.Int `json:"cooldownTime"`
+	VoteTime      *big.Int `json:"voteTime"`
+	VoteDelayTime *big.Int `json:"voteDelayTime"`
+	ExecuteTime   *big.Int `json:"executeTime"`
+	ActionTime    *big.Int `json:"actionTime"`
+}
+
+// Oracle DAO minipool settings
+type TrustedMinipoolSettings struct {
+	ScrubPeriod               *big.Int `json:"scrubPeriod"`
+	PromotionScrubPeriod      *big.Int `json:"promotionScrubPeriod"`
+	ScrubPenaltyEnabled       *bool    `json:"scrubPenaltyEnabled"`
+	BondReductionWindowStart  *big.Int `json:"bondReductionWindowStart"`
+	BondReductionWindowLength *big.Int `json:"bondReductionWindowLength"`
+}
+
+// Every protocol DAO and oracle DAO setting at a block; settings that couldn't be read at the block are nil
+type SettingsDump struct {
+	BlockNumber     uint64                  `json:"blockNumber"`
+	UnreadFields    []string                `json:"unreadFields,omitempty"` // The category.name of each setting that couldn't be read, such as getters that didn't exist yet
+	Auction         AuctionSettings         `json:"auction"`
+	Deposit         DepositSettings         `json:"deposit"`
+	Inflation       InflationSettings       `json:"inflation"`
+	Minipool        MinipoolSettings        `json:"minipool"`
+	Network         NetworkSettings         `json:"network"`
+	Node            NodeSettings            `json:"node"`
+	Rewards         RewardsSettings         `json:"rewards"`
+	Members         MembersSettings         `json:"members"`
+	Proposals       ProposalsSettings       `json:"proposals"`
+	TrustedMinipool TrustedMinipoolSettings `json:"trustedMinipool"`
+}
+
+// A setting that changed between two dumps
+type SettingChange struct {
+	Category     string           `json:"category"`
+	Name         string           `json:"name"`
+	ContractName string           `json:"contractName"`
+	Path         string           `json:"path,omitempty"` // Empty for values that aren't set by path, such as rewards claimer percentages
+	OldValue     dao.SettingValue `json:"oldValue"`
+	NewValue     dao.SettingValue `json:"newValue"`
+}
+
+// The settings that changed between two dumps
+type SettingsChangelog struct {
+	FromBlock uint64          `json:"fromBlock"`
+	ToBlock   uint64          `json:"toBlock"`
+	Changes   []SettingChange `json:"changes"`
+}
+
+// A field of a settings dump and how to read it
+type dumpField struct {
+	category     string
+	name         string
+	contractName string
+	path         string
+	getter       string
+	args         []interface{}
+	unit         rptypes.SettingUnit
+	value        interface{} // A pointer to the field
+}
+
+// Get the settings at a block, or at the latest block if opts doesn't set one, in a single multicall
+func GetSettingsDump(rp *rocketpool.RocketPool, multicallerAddress common.Address, opts *bind.CallOpts) (*SettingsDump, error) {
+
+	// Get the block to read from
+	if opts == nil || opts.BlockNumber == nil {
+		latestBlock, err := rp.Client.BlockNumber(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("Could not get latest block number: %w", err)
+		}
+		blockOpts := &bind.CallOpts{}
+		if opts != nil {
+			*blockOpts = *opts
+		}
+		blockOpts.BlockNumber = new(big.Int).SetUint64(latestBlock)
+		opts = blockOpts
+	}
+
+	// Create the multicaller
+	mc, err := multicall.NewMultiCaller(rp.Client, multicallerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("Could not create multicaller: %w", err)
+	}
+
+	// Add the getter calls, skipping any that the settings contract didn't have at the block
+	dump := &SettingsDump{
+		BlockNumber:  opts.BlockNumber.Uint64(),
+		UnreadFields: []string{},
+	}
+	contracts := map[string]*rocketpool.Contract{}
+	fields := []dumpField{}
+	outputs := []interface{}{}
+	for _, field := range dump.fields() {
+		settingsContract, exists := contracts[field.contractName]
+		if !exists {
+			settingsContract, err = rp.GetContract(field.contractName, opts)
+			if err != nil {
+				return nil, err
+			}
+			contracts[field.contractName] = settingsContract
+		}
+		if _, exists := settingsContract.ABI.Methods[field.getter]; !exists {
+			dump.UnreadFields = append(dump.UnreadFields, field.category+"."+field.name)
+			continue
+		}
+		output := newDumpFieldOutput(field.value)
+		if err := mc.AddCall(settingsContract, output, field.getter, field.args...); err != nil {
+			return nil, fmt.Errorf("Could not add getter for setting %s.%s: %w", field.category, field.name, err)
+		}
+		fields = append(fields, field)
+		outputs = append(outputs, output)
+	}
+
+	// Run the calls, leaving the settings that couldn't be read empty
+	results, err := mc.FlexibleCall(false, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not get settings at block %d: %w", dump.BlockNumber, err)
+	}
+	for i, field := range fields {
+		if !results[i].Success {
+			dump.UnreadFields = append(dump.UnreadFields, field.category+"."+field.name)
+			continue
+		}
+		setDumpFieldValue(field.value, outputs[i])
+	}
+	return dump, nil
+
+}
+
+// Compare two dumps, returning the settings that differ; settings that are nil in a dump are treated as not present
+func DiffSettings(from *SettingsDump, to *SettingsDump) SettingsChangelog {
+	changelog := SettingsChangelog{
+		FromBlock: from.BlockNumber,
+		ToBlock:   to.BlockNumber,
+		Changes:   []SettingChange{},
+	}
+	toFields := to.fields()
+	for i, fromField := range from.fields() {
+		oldValue := getDumpFieldValue(fromField.value)
+		newValue := getDumpFieldValue(toFields[i].value)
+		if settingValuesEqual(oldValue, newValue) {
+			continue
+		}
+		changelog.Changes = append(changelog.Changes, SettingChange{
+			Category:     fromField.category,
+			Name:         fromField.name,
+			ContractName: fromField.contractName,
+			Path:         fromField.path,
+			OldValue:     newDumpSettingValue(oldValue, fromField.unit),
+			NewValue:     newDumpSettingValue(newValue, fromField.unit),
+		})
+	}
+	return changelog
+}
+
+// Render the changelog with one line per changed setting
+func (c SettingsChangelog) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Settings changes from block %d to block %d:\n", c.FromBlock, c.ToBlock)
+	if len(c.Changes) == 0 {
+		sb.WriteString("  (none)\n")
+	}
+	for _, change := range c.Changes {
+		fmt.Fprintf(&sb, "  %s.%s: %s -> %s\n", change.Category, change.Name, change.OldValue.Formatted, change.NewValue.Formatted)
+	}
+	return sb.String()
+}
+
+// Get the fields of the dump, in a fixed order
+func (d *SettingsDump) fields() []dumpField {
+	return []dumpField{
+
+		// Protocol DAO
+		settingField("auction", "createLotEnabled", protocol.AuctionSettingsContractName, protocol.CreateLotEnabledSettingPath, &d.Auction.CreateLotEnabled),
+		settingField("auction", "bidOnLotEnabled", protocol.AuctionSettingsContractName, protocol.BidOnLotEnabledSettingPath, &d.Auction.BidOnLotEnabled),
+		settingField("auction", "lotMinimumEthValue", protocol.AuctionSettingsContractName, protocol.LotMinimumEthValueSettingPath, &d.Auction.LotMinimumEthValue),
+		settingField("auction", "lotMaximumEthValue", protocol.AuctionSettingsContractName, protocol.LotMaximumEthValueSettingPath, &d.Auction.LotMaximumEthValue),
+		settingField("auction", "lotDuration", protocol.AuctionSettingsContractName, protocol.LotDurationSettingPath, &d.Auction.LotDuration),
+		settingField("auction", "lotStartingPriceRatio", protocol.AuctionSettingsContractName, protocol.LotStartingPriceRatioSettingPath, &d.Auction.LotStartingPriceRatio),
+		settingField("auction", "lotReservePriceRatio", protocol.AuctionSettingsContractName, protocol.LotReservePriceRatioSettingPath, &d.Auction.LotReservePriceRatio),
+		settingField("deposit", "depositEnabled", protocol.DepositSettingsContractName, protocol.DepositEnabledSettingPath, &d.Deposit.DepositEnabled),
+		settingField("deposit", "assignDepositsEnabled", protocol.DepositSettingsContractName, protocol.AssignDepositsEnabledSettingPath, &d.Deposit.AssignDepositsEnabled),
+		settingField("deposit", "minimumDeposit", protocol.DepositSettingsContractName, protocol.MinimumDepositSettingPath, &d.Deposit.MinimumDeposit),
+		settingField("deposit", "maximumDepositPoolSize", protocol.DepositSettingsContractName, protocol.MaximumDepositPoolSizeSettingPath, &d.Deposit.MaximumDepositPoolSize),
+		settingField("deposit", "maximumDepositAssignments", protocol.DepositSettingsContractName, protocol.MaximumDepositAssignmentsSettingPath, &d.Deposit.MaximumDepositAssignments),
+		settingField("inflation", "intervalRate", protocol.InflationSettingsContractName, protocol.InflationIntervalRateSettingPath, &d.Inflation.IntervalRate),
+		settingField("inflation", "startTime", protocol.InflationSettingsContractName, protocol.InflationStartTimeSettingPath, &d.Inflation.StartTime),
+		valueField("minipool", "launchBalance", protocol.MinipoolSettingsContractName, "getLaunchBalance", nil, rptypes.SettingUnitEth, &d.Minipool.LaunchBalance),
+		settingField("minipool", "submitWithdrawableEnabled", protocol.MinipoolSettingsContractName, protocol.MinipoolSubmitWithdrawableEnabledSettingPath, &d.Minipool.SubmitWithdrawableEnabled),
+		settingField("minipool", "launchTimeout", protocol.MinipoolSettingsContractName, protocol.MinipoolLaunchTimeoutSettingPath, &d.Minipool.LaunchTimeout),
+		settingField("minipool", "bondReductionEnabled", protocol.MinipoolSettingsContractName, protocol.BondReductionEnabledSettingPath, &d.Minipool.BondReductionEnabled),
+		settingField("network", "nodeConsensusThreshold", protocol.NetworkSettingsContractName, protocol.NodeConsensusThresholdSettingPath, &d.Network.NodeConsensusThreshold),
+		settingField("network", "submitBalancesEnabled", protocol.NetworkSettingsContractName, protocol.SubmitBalancesEnabledSettingPath, &d.Network.SubmitBalancesEnabled),
+		settingField("network", "submitBalancesFrequency", protocol.NetworkSettingsContractName, protocol.SubmitBalancesFrequencySettingPath, &d.Network.SubmitBalancesFrequency),
+		settingField("network", "submitPricesEnabled", protocol.NetworkSettingsContractName, protocol.SubmitPricesEnabledSettingPath, &d.Network.SubmitPricesEnabled),
+		settingField("network", "submitPricesFrequency", protocol.NetworkSettingsContractName, protocol.SubmitPricesFrequencySettingPath, &d.Network.SubmitPricesFrequency),
+		settingField("network", "minimumNodeFee", protocol.NetworkSettingsContractName, protocol.MinimumNodeFeeSettingPath, &d.Network.MinimumNodeFee),
+		settingField("network", "targetNodeFee", protocol.NetworkSettingsContractName, protocol.TargetNodeFeeSettingPath, &d.Network.TargetNodeFee),
+		settingField("network", "maximumNodeFee", protocol.NetworkSettingsContractName, protocol.MaximumNodeFeeSettingPath, &d.Network.MaximumNodeFee),
+		settingField("network", "nodeFeeDemandRange", protocol.NetworkSettingsContractName, protocol.NodeFeeDemandRangeSettingPath, &d.Network.NodeFeeDemandRange),
+		settingField("network", "targetRethCollateralRate", protocol.NetworkSettingsContractName, protocol.TargetRethCollateralRateSettingPath, &d.Network.TargetRethCollateralRate),
+		settingField("node", "registrationEnabled", protocol.NodeSettingsContractName, protocol.NodeRegistrationEnabledSettingPath, &d.Node.RegistrationEnabled),
+		settingField("node", "depositEnabled", protocol.NodeSettingsContractName, protocol.NodeDepositEnabledSettingPath, &d.Node.DepositEnabled),
+		settingField("node", "vacantMinipoolsEnabled", protocol.NodeSettingsContractName, protocol.VacantMinipoolsEnabledSettingPath, &d.Node.VacantMinipoolsEnabled),
+		settingField("node", "minimumPerMinipoolStake", protocol.NodeSettingsContractName, protocol.MinimumPerMinipoolStakeSettingPath, &d.Node.MinimumPerMinipoolStake),
+		settingField("node", "maximumPerMinipoolStake", protocol.NodeSettingsContractName, protocol.MaximumPerMinipoolStakeSettingPath, &d.Node.MaximumPerMinipoolStake),
+		settingField("rewards", "claimIntervalTime", protocol.RewardsSettingsContractName, protocol.RewardsClaimIntervalTimeSettingPath, &d.Rewards.ClaimIntervalTime),
+		valueField("rewards", "nodeOperatorClaimerPerc", protocol.RewardsSettingsContractName, "getRewardsClaimerPerc", []interface{}{protocol.NodeOperatorClaimerContractName}, rptypes.SettingUnitPercent, &d.Rewards.NodeOperatorClaimerPerc),
+		valueField("rewards", "trustedNodeClaimerPerc", protocol.RewardsSettingsContractName, "getRewardsClaimerPerc", []interface{}{protocol.TrustedNodeClaimerContractName}, rptypes.SettingUnitPercent, &d.Rewards.TrustedNodeClaimerPerc),
+		valueField("rewards", "protocolDaoClaimerPerc", protocol.RewardsSettingsContractName, "getRewardsClaimerPerc", []interface{}{protocol.ProtocolDaoClaimerContractName}, rptypes.SettingUnitPercent, &d.Rewards.ProtocolDaoClaimerPerc),
+		valueField("rewards", "claimersPercTotal", protocol.RewardsSettingsContractName, "getRewardsClaimersPercTotal", nil, rptypes.SettingUnitPercent, &d.Rewards.ClaimersPercTotal),
+
+		// Oracle DAO
+		settingField("members", "quorum", trustednode.MembersSettingsContractName, trustednode.QuorumSettingPath, &d.Members.Quorum),
+		settingField("members", "rplBond", trustednode.MembersSettingsContractName, trustednode.RPLBondSettingPath, &d.Members.RPLBond),
+		settingField("members", "minipoolUnbondedMax", trustednode.MembersSettingsContractName, trustednode.MinipoolUnbondedMaxSettingPath, &d.Members.MinipoolUnbondedMax),
+		settingField("members", "minipoolUnbondedMinFee", trustednode.MembersSettingsContractName, trustednode.MinipoolUnbondedMinFeeSettingPath, &d.Members.MinipoolUnbondedMinFee),
+		settingField("members", "challengeCooldown", trustednode.MembersSettingsContractName, trustednode.ChallengeCooldownSettingPath, &d.Members.ChallengeCooldown),
+		settingField("members", "challengeWindow", trustednode.MembersSettingsContractName, trustednode.ChallengeWindowSettingPath, &d.Members.ChallengeWindow),
+		settingField("members", "challengeCost", trustednode.MembersSettingsContractName, trustednode.ChallengeCostSettingPath, &d.Members.ChallengeCost),
+		settingField("proposals", "cooldownTime", trustednode.ProposalsSettingsContractName, trustednode.CooldownTimeSettingPath, &d.Proposals.CooldownTime),
+		settingField("proposals", "voteTime", trustednode.ProposalsSettingsContractName, trustednode.VoteTimeSettingPath, &d.Proposals.VoteTime),
+		settingField("proposals", "voteDelayTime", trustednode.ProposalsSettingsContractName, trustednode.VoteDelayTimeSettingPath, &d.Proposals.VoteDelayTime),
+		settingField("proposals", "executeTime", trustednode.ProposalsSettingsContractName, trustednode.ExecuteTimeSettingPath, &d.Proposals.ExecuteTime),
+		settingField("proposals", "actionTime", trustednode.ProposalsSettingsContractName, trustednode.ActionTimeSettingPath, &d.Proposals.ActionTime),
+		settingField("trustedMinipool", "scrubPeriod", trustednode.MinipoolSettingsContractName, trustednode.ScrubPeriodPath, &d.TrustedMinipool.ScrubPeriod),
+		settingField("trustedMinipool", "promotionScrubPeriod", trustednode.MinipoolSettingsContractName, trustednode.PromotionScrubPeriodPath, &d.TrustedMinipool.PromotionScrubPeriod),
+		settingField("trustedMinipool", "scrubPenaltyEnabled", trustednode.MinipoolSettingsContractName, trustednode.ScrubPenaltyEnabledPath, &d.TrustedMinipool.ScrubPenaltyEnabled),
+		settingField("trustedMinipool", "bondReductionWindowStart", trustednode.MinipoolSettingsContractName, trustednode.BondReductionWindowStartPath, &d.TrustedMinipool.BondReductionWindowStart),
+		settingField("trustedMinipool", "bondReductionWindowLength", trustednode.MinipoolSettingsContractName, trustednode.BondReductionWindowLengthPath, &d.TrustedMinipool.BondReductionWindowLength),
+	}
+}
+
+// Create a dump field for a setting in the registry
+func settingField(category, name, contractName, path string, value interface{}) dumpField {
+	setting, exists := GetSetting(contractName, path)
+	if !exists {
+		panic(fmt.Sprintf("setting %s is not in the registry", path))
+	}
+	return dumpField{
+		category:     category,
+		name:         name,
+		contractName: contractName,
+		path:         path,
+		getter:       setting.Getter,
+		unit:         setting.Unit,
+		value:        value,
+	}
+}
+
+// Create a dump field for a settings contract value that isn't set by path
+func valueField(category, name, contractName, getter string, args []interface{}, unit rptypes.SettingUnit, value interface{}) dumpField {
+	return dumpField{
+		category:     category,
+		name:         name,
+		contractName: contractName,
+		getter:       getter,
+		args:         args,
+		unit:         unit,
+		value:        value,
+	}
+}
+
+// Create the output for a dump field's getter
+func newDumpFieldOutput(value interface{}) interface{} {
+	if _, ok := value.(**bool); ok {
+		return new(bool)
+	}
+	return new(*big.Int)
+}
+
+// Set a dump field from its getter's output
+func setDumpFieldValue(value interface{}, output interface{}) {
+	switch v := value.(type) {
+	case **bool:
+		*v = output.(*bool)
+	case **big.Int:
+		*v = *output.(**big.Int)
+	}
+}
+
+// Get the value a dump field points to, or nil if it isn't present
+func getDumpFieldValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case **bool:
+		if *v != nil {
+			return **v
+		}
+	case **big.Int:
+		if *v != nil {
+			return *v
+		}
+	}
+	return nil
+}
+
+// Create a setting value for a dump field, which may not be present
+func newDumpSettingValue(value interface{}, unit rptypes.SettingUnit) dao.SettingValue {
+	if value == nil {
+		return dao.SettingValue{
+			Unit:      unit,
+			Formatted: "not present",
+		}
+	}
+	return dao.NewSettingValue(value, unit)
+}
+
+// Check if two setting values are equal
+func settingValuesEqual(a interface{}, b interface{}) bool {
+	aInt, aIsInt := a.(*big.Int)
+	bInt, bIsInt := b.(*big.Int)
+	if aIsInt && bIsInt {
+		return aInt.Cmp(bInt) == 0
+	}
+	return a == b
+}
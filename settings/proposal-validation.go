@@ -0,0 +1,207 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+
+	trustednodedao "github.com/Seb369888/poolsea-go/dao/trustednode"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/setting"
+	"github.com/Seb369888/poolsea-go/settings/trustednode"
+)
+
+// The result of checking an oracle DAO proposal before submitting it
+type ProposalValidation struct {
+	Proposer           common.Address `json:"proposer"`
+	IsMember           bool           `json:"isMember"`
+	MemberCount        uint64         `json:"memberCount"`
+	MinimumMemberCount uint64         `json:"minimumMemberCount"`
+	BlockTime          uint64         `json:"blockTime"`
+	LastProposalTime   uint64         `json:"lastProposalTime"`
+	CooldownTime       uint64         `json:"cooldownTime"`
+	NextProposalTime   uint64         `json:"nextProposalTime"`
+	SimulationError    string         `json:"simulationError,omitempty"`
+	Errors             []string       `json:"errors"`
+	CanPropose         bool           `json:"canPropose"`
+}
+
+// Check a proposal to set a setting; value must be a *big.Int for uint settings or a bool for bool settings.
+// This catches proposals that would be rejected on submission or fail on execution.
+func ValidateSettingProposal(rp *rocketpool.RocketPool, proposer common.Address, contractName, path string, value interface{}, opts *bind.CallOpts) (ProposalValidation, error) {
+
+	validation, err := getProposerValidation(rp, proposer, opts)
+	if err != nil {
+		return ProposalValidation{}, err
+	}
+
+	// Check the setting
+	entry, exists := GetSetting(contractName, path)
+	if !exists {
+		validation.Errors = append(validation.Errors, getUnknownSettingError(contractName, path))
+	} else if entry.DAO != setting.DAOTrustedNode {
+		validation.Errors = append(validation.Errors, fmt.Sprintf("Setting %s belongs to the %s DAO and cannot be proposed", path, entry.DAO))
+	} else if err := entry.Validate(value); err != nil {
+		validation.Errors = append(validation.Errors, err.Error())
+	} else {
+
+		// Simulate executing the proposal against the settings contract
+		settingsContract, err := rp.GetContract(contractName, opts)
+		if err != nil {
+			return ProposalValidation{}, err
+		}
+		setter := "setSettingUint"
+		if entry.Type == setting.TypeBool {
+			setter = "setSettingBool"
+		}
+		if err := simulateProposalExecution(rp, settingsContract, opts, setter, path, value); err != nil {
+			validation.SimulationError = err.Error()
+			validation.Errors = append(validation.Errors, fmt.Sprintf("Setting %s would fail on execution: %s", path, err.Error()))
+		}
+
+	}
+
+	validation.CanPropose = len(validation.Errors) == 0
+	return validation, nil
+
+}
+
+// Check a proposal to upgrade or add a contract or ABI, with the same checks as the oracle DAO upgrade report.
+// This catches proposals that would be rejected on submission or fail on execution.
+func ValidateUpgradeProposal(rp *rocketpool.RocketPool, proposer common.Address, upgradeType, contractName, contractAbi string, contractAddress common.Address, opts *bind.CallOpts) (ProposalValidation, error) {
+
+	validation, err := getProposerValidation(rp, proposer, opts)
+	if err != nil {
+		return ProposalValidation{}, err
+	}
+
+	// Check the upgrade
+	report, err := trustednodedao.GetUpgradeReport(rp, upgradeType, contractName, contractAbi, contractAddress, nil, opts)
+	if err != nil {
+		return ProposalValidation{}, err
+	}
+	validation.Errors = append(validation.Errors, report.Errors...)
+
+	// Simulate executing the proposal against the upgrade contract
+	if report.IsValid() {
+		rocketDAONodeTrustedUpgrade, err := rp.GetContract("poolseaDAONodeTrustedUpgrade", opts)
+		if err != nil {
+			return ProposalValidation{}, err
+		}
+		if err := simulateProposalExecution(rp, rocketDAONodeTrustedUpgrade, opts, "upgrade", upgradeType, contractName, report.CompressedAbi, contractAddress); err != nil {
+			validation.SimulationError = err.Error()
+			validation.Errors = append(validation.Errors, fmt.Sprintf("Upgrade of %s would fail on execution: %s", contractName, err.Error()))
+		}
+	}
+
+	validation.CanPropose = len(validation.Errors) == 0
+	return validation, nil
+
+}
+
+// Check whether a node can submit a proposal
+func getProposerValidation(rp *rocketpool.RocketPool, proposer common.Address, opts *bind.CallOpts) (ProposalValidation, error) {
+
+	// Data
+	var wg errgroup.Group
+	validation := ProposalValidation{
+		Proposer: proposer,
+		Errors:   []string{},
+	}
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		validation.IsMember, err = trustednodedao.GetMemberExists(rp, proposer, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		validation.MemberCount, err = trustednodedao.GetMemberCount(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		validation.MinimumMemberCount, err = trustednodedao.GetMinimumMemberCount(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		validation.LastProposalTime, err = trustednodedao.GetMemberLastProposalTime(rp, proposer, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		validation.CooldownTime, err = trustednode.GetProposalCooldownTime(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var blockNumber *big.Int
+		if opts != nil {
+			blockNumber = opts.BlockNumber
+		}
+		header, err := rp.Client.HeaderByNumber(context.Background(), blockNumber)
+		if err == nil {
+			validation.BlockTime = header.Time
+		}
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return ProposalValidation{}, fmt.Errorf("Could not get proposer details for %s: %w", proposer.Hex(), err)
+	}
+
+	// Check the proposer
+	if !validation.IsMember {
+		validation.Errors = append(validation.Errors, fmt.Sprintf("%s is not an oracle DAO member", proposer.Hex()))
+	}
+	if validation.MemberCount < validation.MinimumMemberCount {
+		validation.Errors = append(validation.Errors, fmt.Sprintf("The oracle DAO has %d members but needs %d to accept proposals", validation.MemberCount, validation.MinimumMemberCount))
+	}
+	if validation.LastProposalTime > 0 {
+		validation.NextProposalTime = validation.LastProposalTime + validation.CooldownTime
+		if validation.NextProposalTime > validation.BlockTime {
+			validation.Errors = append(validation.Errors, fmt.Sprintf("Proposal cooldown has not elapsed; %s can propose again in %d seconds", proposer.Hex(), validation.NextProposalTime-validation.BlockTime))
+		}
+	}
+	return validation, nil
+
+}
+
+// Get the error for a setting that isn't in the registry
+func getUnknownSettingError(contractName, path string) string {
+	for _, entry := range Registry {
+		if entry.Path == path {
+			return fmt.Sprintf("Setting %s belongs to contract %s, not %s", path, entry.ContractName, contractName)
+		}
+	}
+	return fmt.Sprintf("Unknown setting %s on contract %s", path, contractName)
+}
+
+// Simulate a call made by the oracle DAO proposals contract when it executes a proposal
+func simulateProposalExecution(rp *rocketpool.RocketPool, target *rocketpool.Contract, opts *bind.CallOpts, method string, args ...interface{}) error {
+	rocketDAONodeTrustedProposals, err := rp.GetContract("poolseaDAONodeTrustedProposals", opts)
+	if err != nil {
+		return err
+	}
+	data, err := target.ABI.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("Could not encode %s call: %w", method, err)
+	}
+	var blockNumber *big.Int
+	if opts != nil {
+		blockNumber = opts.BlockNumber
+	}
+	_, err = rp.Client.CallContract(context.Background(), ethereum.CallMsg{
+		From: *rocketDAONodeTrustedProposals.Address,
+		To:   target.Address,
+		Data: data,
+	}, blockNumber)
+	return err
+}
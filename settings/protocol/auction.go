@@ -1,138 +1,104 @@
 package protocol
 
 import (
-	"fmt"
 	"math/big"
-	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 
-	protocoldao "github.com/Seb369888/poolsea-go/dao/protocol"
 	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/setting"
+	rptypes "github.com/Seb369888/poolsea-go/types"
 	"github.com/Seb369888/poolsea-go/utils/eth"
 )
 
 // Config
-const AuctionSettingsContractName = "poolseaDAOProtocolSettingsAuction"
+const (
+	AuctionSettingsContractName      = "poolseaDAOProtocolSettingsAuction"
+	CreateLotEnabledSettingPath      = "auction.lot.create.enabled"
+	BidOnLotEnabledSettingPath       = "auction.lot.bidding.enabled"
+	LotMinimumEthValueSettingPath    = "auction.lot.value.minimum"
+	LotMaximumEthValueSettingPath    = "auction.lot.value.maximum"
+	LotDurationSettingPath           = "auction.lot.duration"
+	LotStartingPriceRatioSettingPath = "auction.price.start"
+	LotReservePriceRatioSettingPath  = "auction.price.reserve"
+)
+
+// Settings
+var (
+	CreateLotEnabledSetting      = setting.ProtocolBool(AuctionSettingsContractName, CreateLotEnabledSettingPath, "getCreateLotEnabled", "lot creation enabled status")
+	BidOnLotEnabledSetting       = setting.ProtocolBool(AuctionSettingsContractName, BidOnLotEnabledSettingPath, "getBidOnLotEnabled", "lot bidding enabled status")
+	LotMinimumEthValueSetting    = setting.ProtocolUint(AuctionSettingsContractName, LotMinimumEthValueSettingPath, "getLotMinimumEthValue", "lot minimum ETH value", rptypes.SettingUnitEth, nil, nil)
+	LotMaximumEthValueSetting    = setting.ProtocolUint(AuctionSettingsContractName, LotMaximumEthValueSettingPath, "getLotMaximumEthValue", "lot maximum ETH value", rptypes.SettingUnitEth, nil, nil)
+	LotDurationSetting           = setting.ProtocolUint(AuctionSettingsContractName, LotDurationSettingPath, "getLotDuration", "lot duration", rptypes.SettingUnitBlocks, nil, nil)
+	LotStartingPriceRatioSetting = setting.ProtocolUint(AuctionSettingsContractName, LotStartingPriceRatioSettingPath, "getStartingPriceRatio", "lot starting price ratio", rptypes.SettingUnitPercent, nil, nil)
+	LotReservePriceRatioSetting  = setting.ProtocolUint(AuctionSettingsContractName, LotReservePriceRatioSettingPath, "getReservePriceRatio", "lot reserve price ratio", rptypes.SettingUnitPercent, nil, nil)
+)
 
 // Lot creation currently enabled
 func GetCreateLotEnabled(rp *rocketpool.RocketPool, opts *bind.CallOpts) (bool, error) {
-	auctionSettingsContract, err := getAuctionSettingsContract(rp, opts)
-	if err != nil {
-		return false, err
-	}
-	value := new(bool)
-	if err := auctionSettingsContract.Call(opts, value, "getCreateLotEnabled"); err != nil {
-		return false, fmt.Errorf("Could not get lot creation enabled status: %w", err)
-	}
-	return *value, nil
+	return CreateLotEnabledSetting.GetBool(rp, opts)
 }
 func BootstrapCreateLotEnabled(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapBool(rp, AuctionSettingsContractName, "auction.lot.create.enabled", value, opts)
+	return CreateLotEnabledSetting.BootstrapBool(rp, value, opts)
 }
 
 // Lot bidding currently enabled
 func GetBidOnLotEnabled(rp *rocketpool.RocketPool, opts *bind.CallOpts) (bool, error) {
-	auctionSettingsContract, err := getAuctionSettingsContract(rp, opts)
-	if err != nil {
-		return false, err
-	}
-	value := new(bool)
-	if err := auctionSettingsContract.Call(opts, value, "getBidOnLotEnabled"); err != nil {
-		return false, fmt.Errorf("Could not get lot bidding enabled status: %w", err)
-	}
-	return *value, nil
+	return BidOnLotEnabledSetting.GetBool(rp, opts)
 }
 func BootstrapBidOnLotEnabled(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapBool(rp, AuctionSettingsContractName, "auction.lot.bidding.enabled", value, opts)
+	return BidOnLotEnabledSetting.BootstrapBool(rp, value, opts)
 }
 
 // The minimum lot size in ETH value
 func GetLotMinimumEthValue(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*big.Int, error) {
-	auctionSettingsContract, err := getAuctionSettingsContract(rp, opts)
-	if err != nil {
-		return nil, err
-	}
-	value := new(*big.Int)
-	if err := auctionSettingsContract.Call(opts, value, "getLotMinimumEthValue"); err != nil {
-		return nil, fmt.Errorf("Could not get lot minimum ETH value: %w", err)
-	}
-	return *value, nil
+	return LotMinimumEthValueSetting.GetUint(rp, opts)
 }
 func BootstrapLotMinimumEthValue(rp *rocketpool.RocketPool, value *big.Int, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, AuctionSettingsContractName, "auction.lot.value.minimum", value, opts)
+	return LotMinimumEthValueSetting.BootstrapUint(rp, value, opts)
 }
 
 // The maximum lot size in ETH value
 func GetLotMaximumEthValue(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*big.Int, error) {
-	auctionSettingsContract, err := getAuctionSettingsContract(rp, opts)
-	if err != nil {
-		return nil, err
-	}
-	value := new(*big.Int)
-	if err := auctionSettingsContract.Call(opts, value, "getLotMaximumEthValue"); err != nil {
-		return nil, fmt.Errorf("Could not get lot maximum ETH value: %w", err)
-	}
-	return *value, nil
+	return LotMaximumEthValueSetting.GetUint(rp, opts)
 }
 func BootstrapLotMaximumEthValue(rp *rocketpool.RocketPool, value *big.Int, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, AuctionSettingsContractName, "auction.lot.value.maximum", value, opts)
+	return LotMaximumEthValueSetting.BootstrapUint(rp, value, opts)
 }
 
 // The lot duration in blocks
 func GetLotDuration(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
-	auctionSettingsContract, err := getAuctionSettingsContract(rp, opts)
+	value, err := LotDurationSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := auctionSettingsContract.Call(opts, value, "getLotDuration"); err != nil {
-		return 0, fmt.Errorf("Could not get lot duration: %w", err)
-	}
-	return (*value).Uint64(), nil
+	return value.Uint64(), nil
 }
 func BootstrapLotDuration(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, AuctionSettingsContractName, "auction.lot.duration", big.NewInt(int64(value)), opts)
+	return LotDurationSetting.BootstrapUint(rp, big.NewInt(int64(value)), opts)
 }
 
 // The starting price relative to current ETH price, as a fraction
 func GetLotStartingPriceRatio(rp *rocketpool.RocketPool, opts *bind.CallOpts) (float64, error) {
-	auctionSettingsContract, err := getAuctionSettingsContract(rp, opts)
+	value, err := LotStartingPriceRatioSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := auctionSettingsContract.Call(opts, value, "getStartingPriceRatio"); err != nil {
-		return 0, fmt.Errorf("Could not get lot starting price ratio: %w", err)
-	}
-	return eth.WeiToEth(*value), nil
+	return eth.WeiToEth(value), nil
 }
 func BootstrapLotStartingPriceRatio(rp *rocketpool.RocketPool, value float64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, AuctionSettingsContractName, "auction.price.start", eth.EthToWei(value), opts)
+	return LotStartingPriceRatioSetting.BootstrapUint(rp, eth.EthToWei(value), opts)
 }
 
 // The reserve price relative to current ETH price, as a fraction
 func GetLotReservePriceRatio(rp *rocketpool.RocketPool, opts *bind.CallOpts) (float64, error) {
-	auctionSettingsContract, err := getAuctionSettingsContract(rp, opts)
+	value, err := LotReservePriceRatioSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := auctionSettingsContract.Call(opts, value, "getReservePriceRatio"); err != nil {
-		return 0, fmt.Errorf("Could not get lot reserve price ratio: %w", err)
-	}
-	return eth.WeiToEth(*value), nil
+	return eth.WeiToEth(value), nil
 }
 func BootstrapLotReservePriceRatio(rp *rocketpool.RocketPool, value float64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, AuctionSettingsContractName, "auction.price.reserve", eth.EthToWei(value), opts)
-}
-
-// Get contracts
-var auctionSettingsContractLock sync.Mutex
-
-func getAuctionSettingsContract(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*rocketpool.Contract, error) {
-	auctionSettingsContractLock.Lock()
-	defer auctionSettingsContractLock.Unlock()
-	return rp.GetContract(AuctionSettingsContractName, opts)
+	return LotReservePriceRatioSetting.BootstrapUint(rp, eth.EthToWei(value), opts)
 }
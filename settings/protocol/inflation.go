@@ -1,58 +1,50 @@
 package protocol
 
 import (
-	"fmt"
 	"math/big"
-	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 
-	protocoldao "github.com/Seb369888/poolsea-go/dao/protocol"
 	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/setting"
+	rptypes "github.com/Seb369888/poolsea-go/types"
 	"github.com/Seb369888/poolsea-go/utils/eth"
 )
 
 // Config
-const InflationSettingsContractName = "poolseaDAOProtocolSettingsInflation"
+const (
+	InflationSettingsContractName    = "poolseaDAOProtocolSettingsInflation"
+	InflationIntervalRateSettingPath = "rpl.inflation.interval.rate"
+	InflationStartTimeSettingPath    = "rpl.inflation.interval.start"
+)
+
+// Settings
+var (
+	InflationIntervalRateSetting = setting.ProtocolUint(InflationSettingsContractName, InflationIntervalRateSettingPath, "getInflationIntervalRate", "inflation rate", rptypes.SettingUnitNone, nil, nil)
+	InflationStartTimeSetting    = setting.ProtocolUint(InflationSettingsContractName, InflationStartTimeSettingPath, "getInflationIntervalStartTime", "inflation start time", rptypes.SettingUnitTimestamp, nil, nil)
+)
 
 // RPL inflation rate per interval
 func GetInflationIntervalRate(rp *rocketpool.RocketPool, opts *bind.CallOpts) (float64, error) {
-	inflationSettingsContract, err := getInflationSettingsContract(rp, opts)
+	value, err := InflationIntervalRateSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := inflationSettingsContract.Call(opts, value, "getInflationIntervalRate"); err != nil {
-		return 0, fmt.Errorf("Could not get inflation rate: %w", err)
-	}
-	return eth.WeiToEth(*value), nil
+	return eth.WeiToEth(value), nil
 }
 func BootstrapInflationIntervalRate(rp *rocketpool.RocketPool, value float64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, InflationSettingsContractName, "rpl.inflation.interval.rate", eth.EthToWei(value), opts)
+	return InflationIntervalRateSetting.BootstrapUint(rp, eth.EthToWei(value), opts)
 }
 
 // RPL inflation start time
 func GetInflationStartTime(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
-	inflationSettingsContract, err := getInflationSettingsContract(rp, opts)
+	value, err := InflationStartTimeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := inflationSettingsContract.Call(opts, value, "getInflationIntervalStartTime"); err != nil {
-		return 0, fmt.Errorf("Could not get inflation start time: %w", err)
-	}
-	return (*value).Uint64(), nil
+	return value.Uint64(), nil
 }
 func BootstrapInflationStartTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, InflationSettingsContractName, "rpl.inflation.interval.start", big.NewInt(int64(value)), opts)
-}
-
-// Get contracts
-var inflationSettingsContractLock sync.Mutex
-
-func getInflationSettingsContract(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*rocketpool.Contract, error) {
-	inflationSettingsContractLock.Lock()
-	defer inflationSettingsContractLock.Unlock()
-	return rp.GetContract(InflationSettingsContractName, opts)
+	return InflationStartTimeSetting.BootstrapUint(rp, big.NewInt(int64(value)), opts)
 }
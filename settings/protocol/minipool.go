@@ -9,12 +9,25 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 
-	protocoldao "github.com/Seb369888/poolsea-go/dao/protocol"
 	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/setting"
+	rptypes "github.com/Seb369888/poolsea-go/types"
 )
 
 // Config
-const MinipoolSettingsContractName = "poolseaDAOProtocolSettingsMinipool"
+const (
+	MinipoolSettingsContractName                 = "poolseaDAOProtocolSettingsMinipool"
+	MinipoolSubmitWithdrawableEnabledSettingPath = "minipool.submit.withdrawable.enabled"
+	MinipoolLaunchTimeoutSettingPath             = "minipool.launch.timeout"
+	BondReductionEnabledSettingPath              = "minipool.bond.reduction.enabled"
+)
+
+// Settings
+var (
+	MinipoolSubmitWithdrawableEnabledSetting = setting.ProtocolBool(MinipoolSettingsContractName, MinipoolSubmitWithdrawableEnabledSettingPath, "getSubmitWithdrawableEnabled", "minipool withdrawable submissions enabled status")
+	MinipoolLaunchTimeoutSetting             = setting.ProtocolUint(MinipoolSettingsContractName, MinipoolLaunchTimeoutSettingPath, "getLaunchTimeout", "minipool launch timeout", rptypes.SettingUnitSeconds, nil, nil)
+	BondReductionEnabledSetting              = setting.ProtocolBool(MinipoolSettingsContractName, BondReductionEnabledSettingPath, "getBondReductionEnabled", "bond reduction enabled status")
+)
 
 // Get the minipool launch balance
 func GetMinipoolLaunchBalance(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*big.Int, error) {
@@ -101,64 +114,36 @@ func GetMinipoolEmptyDepositUserAmount(rp *rocketpool.RocketPool, opts *bind.Cal
 
 // Minipool withdrawable event submissions currently enabled
 func GetMinipoolSubmitWithdrawableEnabled(rp *rocketpool.RocketPool, opts *bind.CallOpts) (bool, error) {
-	minipoolSettingsContract, err := getMinipoolSettingsContract(rp, opts)
-	if err != nil {
-		return false, err
-	}
-	value := new(bool)
-	if err := minipoolSettingsContract.Call(opts, value, "getSubmitWithdrawableEnabled"); err != nil {
-		return false, fmt.Errorf("Could not get minipool withdrawable submissions enabled status: %w", err)
-	}
-	return *value, nil
+	return MinipoolSubmitWithdrawableEnabledSetting.GetBool(rp, opts)
 }
 func BootstrapMinipoolSubmitWithdrawableEnabled(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapBool(rp, MinipoolSettingsContractName, "minipool.submit.withdrawable.enabled", value, opts)
+	return MinipoolSubmitWithdrawableEnabledSetting.BootstrapBool(rp, value, opts)
 }
 
 // Timeout period in seconds for prelaunch minipools to launch
 func GetMinipoolLaunchTimeout(rp *rocketpool.RocketPool, opts *bind.CallOpts) (time.Duration, error) {
-	minipoolSettingsContract, err := getMinipoolSettingsContract(rp, opts)
+	value, err := MinipoolLaunchTimeoutSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := minipoolSettingsContract.Call(opts, value, "getLaunchTimeout"); err != nil {
-		return 0, fmt.Errorf("Could not get minipool launch timeout: %w", err)
-	}
-	seconds := time.Duration((*value).Int64()) * time.Second
+	seconds := time.Duration(value.Int64()) * time.Second
 	return seconds, nil
 }
 
 // Timeout period in seconds for prelaunch minipools to launch
 func GetMinipoolLaunchTimeoutRaw(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*big.Int, error) {
-	minipoolSettingsContract, err := getMinipoolSettingsContract(rp, opts)
-	if err != nil {
-		return nil, err
-	}
-	value := new(*big.Int)
-	if err := minipoolSettingsContract.Call(opts, value, "getLaunchTimeout"); err != nil {
-		return nil, fmt.Errorf("Could not get minipool launch timeout: %w", err)
-	}
-	return *value, nil
+	return MinipoolLaunchTimeoutSetting.GetUint(rp, opts)
 }
 func BootstrapMinipoolLaunchTimeout(rp *rocketpool.RocketPool, value time.Duration, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, MinipoolSettingsContractName, "minipool.launch.timeout", big.NewInt(int64(value.Seconds())), opts)
+	return MinipoolLaunchTimeoutSetting.BootstrapUint(rp, big.NewInt(int64(value.Seconds())), opts)
 }
 
 // Minipool bond reductions currently enabled
 func GetBondReductionEnabled(rp *rocketpool.RocketPool, opts *bind.CallOpts) (bool, error) {
-	minipoolSettingsContract, err := getMinipoolSettingsContract(rp, opts)
-	if err != nil {
-		return false, err
-	}
-	value := new(bool)
-	if err := minipoolSettingsContract.Call(opts, value, "getBondReductionEnabled"); err != nil {
-		return false, fmt.Errorf("Could not get bond reduction enabled status: %w", err)
-	}
-	return *value, nil
+	return BondReductionEnabledSetting.GetBool(rp, opts)
 }
 func BootstrapBondReductionEnabled(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapBool(rp, MinipoolSettingsContractName, "minipool.bond.reduction.enabled", value, opts)
+	return BondReductionEnabledSetting.BootstrapBool(rp, value, opts)
 }
 
 // Get contracts
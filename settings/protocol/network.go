@@ -1,186 +1,157 @@
 package protocol
 
 import (
-	"fmt"
 	"math/big"
-	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 
-	protocoldao "github.com/Seb369888/poolsea-go/dao/protocol"
 	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/setting"
+	rptypes "github.com/Seb369888/poolsea-go/types"
 	"github.com/Seb369888/poolsea-go/utils/eth"
 )
 
 // Config
-const NetworkSettingsContractName = "poolseaDAOProtocolSettingsNetwork"
+const (
+	NetworkSettingsContractName         = "poolseaDAOProtocolSettingsNetwork"
+	NodeConsensusThresholdSettingPath   = "network.consensus.threshold"
+	SubmitBalancesEnabledSettingPath    = "network.submit.balances.enabled"
+	SubmitBalancesFrequencySettingPath  = "network.submit.balances.frequency"
+	SubmitPricesEnabledSettingPath      = "network.submit.prices.enabled"
+	SubmitPricesFrequencySettingPath    = "network.submit.prices.frequency"
+	MinimumNodeFeeSettingPath           = "network.node.fee.minimum"
+	TargetNodeFeeSettingPath            = "network.node.fee.target"
+	MaximumNodeFeeSettingPath           = "network.node.fee.maximum"
+	NodeFeeDemandRangeSettingPath       = "network.node.fee.demand.range"
+	TargetRethCollateralRateSettingPath = "network.reth.collateral.target"
+)
+
+// Bounds enforced by the network settings contract once it is deployed
+var (
+	MinimumNodeConsensusThreshold = big.NewInt(51e16) // 51%
+	MinimumNodeFeeBound           = big.NewInt(5e16)  // 5%
+	MaximumNodeFeeBound           = big.NewInt(2e17)  // 20%
+)
+
+// Settings
+var (
+	NodeConsensusThresholdSetting   = setting.ProtocolUint(NetworkSettingsContractName, NodeConsensusThresholdSettingPath, "getNodeConsensusThreshold", "trusted node consensus threshold", rptypes.SettingUnitPercent, MinimumNodeConsensusThreshold, nil)
+	SubmitBalancesEnabledSetting    = setting.ProtocolBool(NetworkSettingsContractName, SubmitBalancesEnabledSettingPath, "getSubmitBalancesEnabled", "network balance submissions enabled status")
+	SubmitBalancesFrequencySetting  = setting.ProtocolUint(NetworkSettingsContractName, SubmitBalancesFrequencySettingPath, "getSubmitBalancesFrequency", "network balance submission frequency", rptypes.SettingUnitBlocks, nil, nil)
+	SubmitPricesEnabledSetting      = setting.ProtocolBool(NetworkSettingsContractName, SubmitPricesEnabledSettingPath, "getSubmitPricesEnabled", "network price submissions enabled status")
+	SubmitPricesFrequencySetting    = setting.ProtocolUint(NetworkSettingsContractName, SubmitPricesFrequencySettingPath, "getSubmitPricesFrequency", "network price submission frequency", rptypes.SettingUnitBlocks, nil, nil)
+	MinimumNodeFeeSetting           = setting.ProtocolUint(NetworkSettingsContractName, MinimumNodeFeeSettingPath, "getMinimumNodeFee", "minimum node fee", rptypes.SettingUnitPercent, MinimumNodeFeeBound, MaximumNodeFeeBound)
+	TargetNodeFeeSetting            = setting.ProtocolUint(NetworkSettingsContractName, TargetNodeFeeSettingPath, "getTargetNodeFee", "target node fee", rptypes.SettingUnitPercent, MinimumNodeFeeBound, MaximumNodeFeeBound)
+	MaximumNodeFeeSetting           = setting.ProtocolUint(NetworkSettingsContractName, MaximumNodeFeeSettingPath, "getMaximumNodeFee", "maximum node fee", rptypes.SettingUnitPercent, MinimumNodeFeeBound, MaximumNodeFeeBound)
+	NodeFeeDemandRangeSetting       = setting.ProtocolUint(NetworkSettingsContractName, NodeFeeDemandRangeSettingPath, "getNodeFeeDemandRange", "node fee demand range", rptypes.SettingUnitEth, nil, nil)
+	TargetRethCollateralRateSetting = setting.ProtocolUint(NetworkSettingsContractName, TargetRethCollateralRateSettingPath, "getTargetRethCollateralRate", "target rETH contract collateralization rate", rptypes.SettingUnitPercent, nil, nil)
+)
 
 // The threshold of trusted nodes that must reach consensus on oracle data to commit it
 func GetNodeConsensusThreshold(rp *rocketpool.RocketPool, opts *bind.CallOpts) (float64, error) {
-	networkSettingsContract, err := getNetworkSettingsContract(rp, opts)
+	value, err := NodeConsensusThresholdSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := networkSettingsContract.Call(opts, value, "getNodeConsensusThreshold"); err != nil {
-		return 0, fmt.Errorf("Could not get trusted node consensus threshold: %w", err)
-	}
-	return eth.WeiToEth(*value), nil
+	return eth.WeiToEth(value), nil
 }
 func BootstrapNodeConsensusThreshold(rp *rocketpool.RocketPool, value float64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, NetworkSettingsContractName, "network.consensus.threshold", eth.EthToWei(value), opts)
+	return NodeConsensusThresholdSetting.BootstrapUint(rp, eth.EthToWei(value), opts)
 }
 
 // Network balance submissions currently enabled
 func GetSubmitBalancesEnabled(rp *rocketpool.RocketPool, opts *bind.CallOpts) (bool, error) {
-	networkSettingsContract, err := getNetworkSettingsContract(rp, opts)
-	if err != nil {
-		return false, err
-	}
-	value := new(bool)
-	if err := networkSettingsContract.Call(opts, value, "getSubmitBalancesEnabled"); err != nil {
-		return false, fmt.Errorf("Could not get network balance submissions enabled status: %w", err)
-	}
-	return *value, nil
+	return SubmitBalancesEnabledSetting.GetBool(rp, opts)
 }
 func BootstrapSubmitBalancesEnabled(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapBool(rp, NetworkSettingsContractName, "network.submit.balances.enabled", value, opts)
+	return SubmitBalancesEnabledSetting.BootstrapBool(rp, value, opts)
 }
 
 // The frequency in blocks at which network balances should be submitted by trusted nodes
 func GetSubmitBalancesFrequency(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
-	networkSettingsContract, err := getNetworkSettingsContract(rp, opts)
+	value, err := SubmitBalancesFrequencySetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := networkSettingsContract.Call(opts, value, "getSubmitBalancesFrequency"); err != nil {
-		return 0, fmt.Errorf("Could not get network balance submission frequency: %w", err)
-	}
-	return (*value).Uint64(), nil
+	return value.Uint64(), nil
 }
 func BootstrapSubmitBalancesFrequency(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, NetworkSettingsContractName, "network.submit.balances.frequency", big.NewInt(int64(value)), opts)
+	return SubmitBalancesFrequencySetting.BootstrapUint(rp, big.NewInt(int64(value)), opts)
 }
 
 // Network price submissions currently enabled
 func GetSubmitPricesEnabled(rp *rocketpool.RocketPool, opts *bind.CallOpts) (bool, error) {
-	networkSettingsContract, err := getNetworkSettingsContract(rp, opts)
-	if err != nil {
-		return false, err
-	}
-	value := new(bool)
-	if err := networkSettingsContract.Call(opts, value, "getSubmitPricesEnabled"); err != nil {
-		return false, fmt.Errorf("Could not get network price submissions enabled status: %w", err)
-	}
-	return *value, nil
+	return SubmitPricesEnabledSetting.GetBool(rp, opts)
 }
 func BootstrapSubmitPricesEnabled(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapBool(rp, NetworkSettingsContractName, "network.submit.prices.enabled", value, opts)
+	return SubmitPricesEnabledSetting.BootstrapBool(rp, value, opts)
 }
 
 // The frequency in blocks at which network prices should be submitted by trusted nodes
 func GetSubmitPricesFrequency(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
-	networkSettingsContract, err := getNetworkSettingsContract(rp, opts)
+	value, err := SubmitPricesFrequencySetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := networkSettingsContract.Call(opts, value, "getSubmitPricesFrequency"); err != nil {
-		return 0, fmt.Errorf("Could not get network price submission frequency: %w", err)
-	}
-	return (*value).Uint64(), nil
+	return value.Uint64(), nil
 }
 func BootstrapSubmitPricesFrequency(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, NetworkSettingsContractName, "network.submit.prices.frequency", big.NewInt(int64(value)), opts)
+	return SubmitPricesFrequencySetting.BootstrapUint(rp, big.NewInt(int64(value)), opts)
 }
 
 // Minimum node commission rate
 func GetMinimumNodeFee(rp *rocketpool.RocketPool, opts *bind.CallOpts) (float64, error) {
-	networkSettingsContract, err := getNetworkSettingsContract(rp, opts)
+	value, err := MinimumNodeFeeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := networkSettingsContract.Call(opts, value, "getMinimumNodeFee"); err != nil {
-		return 0, fmt.Errorf("Could not get minimum node fee: %w", err)
-	}
-	return eth.WeiToEth(*value), nil
+	return eth.WeiToEth(value), nil
 }
 func BootstrapMinimumNodeFee(rp *rocketpool.RocketPool, value float64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, NetworkSettingsContractName, "network.node.fee.minimum", eth.EthToWei(value), opts)
+	return MinimumNodeFeeSetting.BootstrapUint(rp, eth.EthToWei(value), opts)
 }
 
 // Target node commission rate
 func GetTargetNodeFee(rp *rocketpool.RocketPool, opts *bind.CallOpts) (float64, error) {
-	networkSettingsContract, err := getNetworkSettingsContract(rp, opts)
+	value, err := TargetNodeFeeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := networkSettingsContract.Call(opts, value, "getTargetNodeFee"); err != nil {
-		return 0, fmt.Errorf("Could not get target node fee: %w", err)
-	}
-	return eth.WeiToEth(*value), nil
+	return eth.WeiToEth(value), nil
 }
 func BootstrapTargetNodeFee(rp *rocketpool.RocketPool, value float64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, NetworkSettingsContractName, "network.node.fee.target", eth.EthToWei(value), opts)
+	return TargetNodeFeeSetting.BootstrapUint(rp, eth.EthToWei(value), opts)
 }
 
 // Maximum node commission rate
 func GetMaximumNodeFee(rp *rocketpool.RocketPool, opts *bind.CallOpts) (float64, error) {
-	networkSettingsContract, err := getNetworkSettingsContract(rp, opts)
+	value, err := MaximumNodeFeeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := networkSettingsContract.Call(opts, value, "getMaximumNodeFee"); err != nil {
-		return 0, fmt.Errorf("Could not get maximum node fee: %w", err)
-	}
-	return eth.WeiToEth(*value), nil
+	return eth.WeiToEth(value), nil
 }
 func BootstrapMaximumNodeFee(rp *rocketpool.RocketPool, value float64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, NetworkSettingsContractName, "network.node.fee.maximum", eth.EthToWei(value), opts)
+	return MaximumNodeFeeSetting.BootstrapUint(rp, eth.EthToWei(value), opts)
 }
 
 // The range of node demand values to base fee calculations on
 func GetNodeFeeDemandRange(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*big.Int, error) {
-	networkSettingsContract, err := getNetworkSettingsContract(rp, opts)
-	if err != nil {
-		return nil, err
-	}
-	value := new(*big.Int)
-	if err := networkSettingsContract.Call(opts, value, "getNodeFeeDemandRange"); err != nil {
-		return nil, fmt.Errorf("Could not get node fee demand range: %w", err)
-	}
-	return *value, nil
+	return NodeFeeDemandRangeSetting.GetUint(rp, opts)
 }
 func BootstrapNodeFeeDemandRange(rp *rocketpool.RocketPool, value *big.Int, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, NetworkSettingsContractName, "network.node.fee.demand.range", value, opts)
+	return NodeFeeDemandRangeSetting.BootstrapUint(rp, value, opts)
 }
 
 // The target collateralization rate for the rETH contract as a fraction
 func GetTargetRethCollateralRate(rp *rocketpool.RocketPool, opts *bind.CallOpts) (float64, error) {
-	networkSettingsContract, err := getNetworkSettingsContract(rp, opts)
+	value, err := TargetRethCollateralRateSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := networkSettingsContract.Call(opts, value, "getTargetRethCollateralRate"); err != nil {
-		return 0, fmt.Errorf("Could not get target rETH contract collateralization rate: %w", err)
-	}
-	return eth.WeiToEth(*value), nil
+	return eth.WeiToEth(value), nil
 }
 func BootstrapTargetRethCollateralRate(rp *rocketpool.RocketPool, value float64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, NetworkSettingsContractName, "network.reth.collateral.target", eth.EthToWei(value), opts)
-}
-
-// Get contracts
-var networkSettingsContractLock sync.Mutex
-
-func getNetworkSettingsContract(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*rocketpool.Contract, error) {
-	networkSettingsContractLock.Lock()
-	defer networkSettingsContractLock.Unlock()
-	return rp.GetContract(NetworkSettingsContractName, opts)
+	return TargetRethCollateralRateSetting.BootstrapUint(rp, eth.EthToWei(value), opts)
 }
@@ -1,132 +1,90 @@
 package protocol
 
 import (
-	"fmt"
 	"math/big"
-	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 
-	protocoldao "github.com/Seb369888/poolsea-go/dao/protocol"
 	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/setting"
+	rptypes "github.com/Seb369888/poolsea-go/types"
 	"github.com/Seb369888/poolsea-go/utils/eth"
 )
 
 // Config
-const NodeSettingsContractName = "poolseaDAOProtocolSettingsNode"
+const (
+	NodeSettingsContractName           = "poolseaDAOProtocolSettingsNode"
+	NodeRegistrationEnabledSettingPath = "node.registration.enabled"
+	NodeDepositEnabledSettingPath      = "node.deposit.enabled"
+	VacantMinipoolsEnabledSettingPath  = "node.vacant.minipools.enabled"
+	MinimumPerMinipoolStakeSettingPath = "node.per.minipool.stake.minimum"
+	MaximumPerMinipoolStakeSettingPath = "node.per.minipool.stake.maximum"
+)
+
+// Settings
+var (
+	NodeRegistrationEnabledSetting = setting.ProtocolBool(NodeSettingsContractName, NodeRegistrationEnabledSettingPath, "getRegistrationEnabled", "node registrations enabled status")
+	NodeDepositEnabledSetting      = setting.ProtocolBool(NodeSettingsContractName, NodeDepositEnabledSettingPath, "getDepositEnabled", "node deposits enabled status")
+	VacantMinipoolsEnabledSetting  = setting.ProtocolBool(NodeSettingsContractName, VacantMinipoolsEnabledSettingPath, "getVacantMinipoolsEnabled", "vacant minipools enabled status")
+	MinimumPerMinipoolStakeSetting = setting.ProtocolUint(NodeSettingsContractName, MinimumPerMinipoolStakeSettingPath, "getMinimumPerMinipoolStake", "minimum RPL stake per minipool", rptypes.SettingUnitPercent, nil, nil)
+	MaximumPerMinipoolStakeSetting = setting.ProtocolUint(NodeSettingsContractName, MaximumPerMinipoolStakeSettingPath, "getMaximumPerMinipoolStake", "maximum RPL stake per minipool", rptypes.SettingUnitPercent, nil, nil)
+)
 
 // Node registrations currently enabled
 func GetNodeRegistrationEnabled(rp *rocketpool.RocketPool, opts *bind.CallOpts) (bool, error) {
-	nodeSettingsContract, err := getNodeSettingsContract(rp, opts)
-	if err != nil {
-		return false, err
-	}
-	value := new(bool)
-	if err := nodeSettingsContract.Call(opts, value, "getRegistrationEnabled"); err != nil {
-		return false, fmt.Errorf("Could not get node registrations enabled status: %w", err)
-	}
-	return *value, nil
+	return NodeRegistrationEnabledSetting.GetBool(rp, opts)
 }
 func BootstrapNodeRegistrationEnabled(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapBool(rp, NodeSettingsContractName, "node.registration.enabled", value, opts)
+	return NodeRegistrationEnabledSetting.BootstrapBool(rp, value, opts)
 }
 
 // Node deposits currently enabled
 func GetNodeDepositEnabled(rp *rocketpool.RocketPool, opts *bind.CallOpts) (bool, error) {
-	nodeSettingsContract, err := getNodeSettingsContract(rp, opts)
-	if err != nil {
-		return false, err
-	}
-	value := new(bool)
-	if err := nodeSettingsContract.Call(opts, value, "getDepositEnabled"); err != nil {
-		return false, fmt.Errorf("Could not get node deposits enabled status: %w", err)
-	}
-	return *value, nil
+	return NodeDepositEnabledSetting.GetBool(rp, opts)
 }
 func BootstrapNodeDepositEnabled(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapBool(rp, NodeSettingsContractName, "node.deposit.enabled", value, opts)
+	return NodeDepositEnabledSetting.BootstrapBool(rp, value, opts)
 }
 
 // Vacant minipools currently enabled
 func GetVacantMinipoolsEnabled(rp *rocketpool.RocketPool, opts *bind.CallOpts) (bool, error) {
-	nodeSettingsContract, err := getNodeSettingsContract(rp, opts)
-	if err != nil {
-		return false, err
-	}
-	value := new(bool)
-	if err := nodeSettingsContract.Call(opts, value, "getVacantMinipoolsEnabled"); err != nil {
-		return false, fmt.Errorf("Could not get vacant minipools enabled status: %w", err)
-	}
-	return *value, nil
+	return VacantMinipoolsEnabledSetting.GetBool(rp, opts)
 }
 func BootstrapVacantMinipoolsEnabled(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapBool(rp, NodeSettingsContractName, "node.vacant.minipools.enabled", value, opts)
+	return VacantMinipoolsEnabledSetting.BootstrapBool(rp, value, opts)
 }
 
 // The minimum RPL stake per minipool as a fraction of assigned user ETH
 func GetMinimumPerMinipoolStake(rp *rocketpool.RocketPool, opts *bind.CallOpts) (float64, error) {
-	nodeSettingsContract, err := getNodeSettingsContract(rp, opts)
+	value, err := MinimumPerMinipoolStakeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := nodeSettingsContract.Call(opts, value, "getMinimumPerMinipoolStake"); err != nil {
-		return 0, fmt.Errorf("Could not get minimum RPL stake per minipool: %w", err)
-	}
-	return eth.WeiToEth(*value), nil
+	return eth.WeiToEth(value), nil
 }
 
 // The minimum RPL stake per minipool as a fraction of assigned user ETH
 func GetMinimumPerMinipoolStakeRaw(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*big.Int, error) {
-	nodeSettingsContract, err := getNodeSettingsContract(rp, opts)
-	if err != nil {
-		return nil, err
-	}
-	value := new(*big.Int)
-	if err := nodeSettingsContract.Call(opts, value, "getMinimumPerMinipoolStake"); err != nil {
-		return nil, fmt.Errorf("Could not get minimum RPL stake per minipool: %w", err)
-	}
-	return *value, nil
+	return MinimumPerMinipoolStakeSetting.GetUint(rp, opts)
 }
 func BootstrapMinimumPerMinipoolStake(rp *rocketpool.RocketPool, value float64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, NodeSettingsContractName, "node.per.minipool.stake.minimum", eth.EthToWei(value), opts)
+	return MinimumPerMinipoolStakeSetting.BootstrapUint(rp, eth.EthToWei(value), opts)
 }
 
 // The maximum RPL stake per minipool as a fraction of assigned user ETH
 func GetMaximumPerMinipoolStake(rp *rocketpool.RocketPool, opts *bind.CallOpts) (float64, error) {
-	nodeSettingsContract, err := getNodeSettingsContract(rp, opts)
+	value, err := MaximumPerMinipoolStakeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := nodeSettingsContract.Call(opts, value, "getMaximumPerMinipoolStake"); err != nil {
-		return 0, fmt.Errorf("Could not get maximum RPL stake per minipool: %w", err)
-	}
-	return eth.WeiToEth(*value), nil
+	return eth.WeiToEth(value), nil
 }
 
 // The maximum RPL stake per minipool as a fraction of assigned user ETH
 func GetMaximumPerMinipoolStakeRaw(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*big.Int, error) {
-	nodeSettingsContract, err := getNodeSettingsContract(rp, opts)
-	if err != nil {
-		return nil, err
-	}
-	value := new(*big.Int)
-	if err := nodeSettingsContract.Call(opts, value, "getMaximumPerMinipoolStake"); err != nil {
-		return nil, fmt.Errorf("Could not get maximum RPL stake per minipool: %w", err)
-	}
-	return *value, nil
+	return MaximumPerMinipoolStakeSetting.GetUint(rp, opts)
 }
 func BootstrapMaximumPerMinipoolStake(rp *rocketpool.RocketPool, value float64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, NodeSettingsContractName, "node.per.minipool.stake.maximum", eth.EthToWei(value), opts)
-}
-
-// Get contracts
-var nodeSettingsContractLock sync.Mutex
-
-func getNodeSettingsContract(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*rocketpool.Contract, error) {
-	nodeSettingsContractLock.Lock()
-	defer nodeSettingsContractLock.Unlock()
-	return rp.GetContract(NodeSettingsContractName, opts)
+	return MaximumPerMinipoolStakeSetting.BootstrapUint(rp, eth.EthToWei(value), opts)
 }
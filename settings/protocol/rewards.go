@@ -8,25 +8,47 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 
-	protocoldao "github.com/Seb369888/poolsea-go/dao/protocol"
 	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/setting"
+	rptypes "github.com/Seb369888/poolsea-go/types"
 	"github.com/Seb369888/poolsea-go/utils/eth"
 )
 
 // Config
-const RewardsSettingsContractName = "poolseaDAOProtocolSettingsRewards"
+const (
+	RewardsSettingsContractName         = "poolseaDAOProtocolSettingsRewards"
+	RewardsClaimIntervalTimeSettingPath = "rpl.rewards.claim.period.time"
+
+	NodeOperatorClaimerContractName = "poolseaClaimNode"
+	TrustedNodeClaimerContractName  = "poolseaClaimTrustedNode"
+	ProtocolDaoClaimerContractName  = "poolseaClaimDAO"
+)
+
+// Settings
+var (
+	RewardsClaimIntervalTimeSetting = setting.ProtocolUint(RewardsSettingsContractName, RewardsClaimIntervalTimeSettingPath, "getRewardsClaimIntervalTime", "rewards claim interval", rptypes.SettingUnitSeconds, nil, nil)
+)
 
 // The claim amount for a claimer as a fraction
 func GetRewardsClaimerPerc(rp *rocketpool.RocketPool, contractName string, opts *bind.CallOpts) (float64, error) {
-	rewardsSettingsContract, err := getRewardsSettingsContract(rp, opts)
+	value, err := GetRewardsClaimerPercRaw(rp, contractName, opts)
 	if err != nil {
 		return 0, err
 	}
+	return eth.WeiToEth(value), nil
+}
+
+// The claim amount for a claimer, where 1e18 = 100%
+func GetRewardsClaimerPercRaw(rp *rocketpool.RocketPool, contractName string, opts *bind.CallOpts) (*big.Int, error) {
+	rewardsSettingsContract, err := getRewardsSettingsContract(rp, opts)
+	if err != nil {
+		return nil, err
+	}
 	value := new(*big.Int)
 	if err := rewardsSettingsContract.Call(opts, value, "getRewardsClaimerPerc", contractName); err != nil {
-		return 0, fmt.Errorf("Could not get rewards claimer percent: %w", err)
+		return nil, fmt.Errorf("Could not get rewards claimer percent: %w", err)
 	}
-	return eth.WeiToEth(*value), nil
+	return *value, nil
 }
 
 // The time that a claimer's share was last updated
@@ -44,31 +66,36 @@ func GetRewardsClaimerPercTimeUpdated(rp *rocketpool.RocketPool, contractName st
 
 // The total claim amount for all claimers as a fraction
 func GetRewardsClaimersPercTotal(rp *rocketpool.RocketPool, opts *bind.CallOpts) (float64, error) {
-	rewardsSettingsContract, err := getRewardsSettingsContract(rp, opts)
+	value, err := GetRewardsClaimersPercTotalRaw(rp, opts)
 	if err != nil {
 		return 0, err
 	}
+	return eth.WeiToEth(value), nil
+}
+
+// The total claim amount for all claimers, where 1e18 = 100%
+func GetRewardsClaimersPercTotalRaw(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*big.Int, error) {
+	rewardsSettingsContract, err := getRewardsSettingsContract(rp, opts)
+	if err != nil {
+		return nil, err
+	}
 	value := new(*big.Int)
 	if err := rewardsSettingsContract.Call(opts, value, "getRewardsClaimersPercTotal"); err != nil {
-		return 0, fmt.Errorf("Could not get rewards claimers total percent: %w", err)
+		return nil, fmt.Errorf("Could not get rewards claimers total percent: %w", err)
 	}
-	return eth.WeiToEth(*value), nil
+	return *value, nil
 }
 
 // Rewards claim interval time
 func GetRewardsClaimIntervalTime(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
-	rewardsSettingsContract, err := getRewardsSettingsContract(rp, opts)
+	value, err := RewardsClaimIntervalTimeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := rewardsSettingsContract.Call(opts, value, "getRewardsClaimIntervalTime"); err != nil {
-		return 0, fmt.Errorf("Could not get rewards claim interval: %w", err)
-	}
-	return (*value).Uint64(), nil
+	return value.Uint64(), nil
 }
 func BootstrapRewardsClaimIntervalTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (common.Hash, error) {
-	return protocoldao.BootstrapUint(rp, RewardsSettingsContractName, "rpl.rewards.claim.period.time", big.NewInt(int64(value)), opts)
+	return RewardsClaimIntervalTimeSetting.BootstrapUint(rp, big.NewInt(int64(value)), opts)
 }
 
 // Get contracts
@@ -0,0 +1,175 @@
+package settings
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/dao"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/protocol"
+	"github.com/Seb369888/poolsea-go/settings/setting"
+	"github.com/Seb369888/poolsea-go/settings/trustednode"
+	"github.com/Seb369888/poolsea-go/utils/multicall"
+)
+
+// A setting and its value at a block
+type SettingReading struct {
+	Setting setting.Setting  `json:"setting"`
+	Value   dao.SettingValue `json:"value"`
+}
+
+// Every protocol DAO and oracle DAO setting
+var Registry = []setting.Setting{
+
+	// Protocol DAO auction settings
+	protocol.CreateLotEnabledSetting,
+	protocol.BidOnLotEnabledSetting,
+	protocol.LotMinimumEthValueSetting,
+	protocol.LotMaximumEthValueSetting,
+	protocol.LotDurationSetting,
+	protocol.LotStartingPriceRatioSetting,
+	protocol.LotReservePriceRatioSetting,
+
+	// Protocol DAO deposit settings
+	protocol.DepositEnabledSetting,
+	protocol.AssignDepositsEnabledSetting,
+	protocol.MinimumDepositSetting,
+	protocol.MaximumDepositPoolSizeSetting,
+	protocol.MaximumDepositAssignmentsSetting,
+
+	// Protocol DAO inflation settings
+	protocol.InflationIntervalRateSetting,
+	protocol.InflationStartTimeSetting,
+
+	// Protocol DAO minipool settings
+	protocol.MinipoolSubmitWithdrawableEnabledSetting,
+	protocol.MinipoolLaunchTimeoutSetting,
+	protocol.BondReductionEnabledSetting,
+
+	// Protocol DAO network settings
+	protocol.NodeConsensusThresholdSetting,
+	protocol.SubmitBalancesEnabledSetting,
+	protocol.SubmitBalancesFrequencySetting,
+	protocol.SubmitPricesEnabledSetting,
+	protocol.SubmitPricesFrequencySetting,
+	protocol.MinimumNodeFeeSetting,
+	protocol.TargetNodeFeeSetting,
+	protocol.MaximumNodeFeeSetting,
+	protocol.NodeFeeDemandRangeSetting,
+	protocol.TargetRethCollateralRateSetting,
+
+	// Protocol DAO node settings
+	protocol.NodeRegistrationEnabledSetting,
+	protocol.NodeDepositEnabledSetting,
+	protocol.VacantMinipoolsEnabledSetting,
+	protocol.MinimumPerMinipoolStakeSetting,
+	protocol.MaximumPerMinipoolStakeSetting,
+
+	// Protocol DAO rewards settings
+	protocol.RewardsClaimIntervalTimeSetting,
+
+	// Oracle DAO member settings
+	trustednode.QuorumSetting,
+	trustednode.RPLBondSetting,
+	trustednode.MinipoolUnbondedMaxSetting,
+	trustednode.MinipoolUnbondedMinFeeSetting,
+	trustednode.ChallengeCooldownSetting,
+	trustednode.ChallengeWindowSetting,
+	trustednode.ChallengeCostSetting,
+
+	// Oracle DAO minipool settings
+	trustednode.ScrubPeriodSetting,
+	trustednode.PromotionScrubPeriodSetting,
+	trustednode.ScrubPenaltyEnabledSetting,
+	trustednode.BondReductionWindowStartSetting,
+	trustednode.BondReductionWindowLengthSetting,
+
+	// Oracle DAO proposal settings
+	trustednode.ProposalCooldownTimeSetting,
+	trustednode.ProposalVoteTimeSetting,
+	trustednode.ProposalVoteDelayTimeSetting,
+	trustednode.ProposalExecuteTimeSetting,
+	trustednode.ProposalActionTimeSetting,
+}
+
+// Get a setting from the registry by its settings contract and path
+func GetSetting(contractName, path string) (setting.Setting, bool) {
+	for _, entry := range Registry {
+		if entry.ContractName == contractName && entry.Path == path {
+			return entry, true
+		}
+	}
+	return setting.Setting{}, false
+}
+
+// Get the settings in the registry for a DAO
+func GetDAOSettings(settingDao setting.DAO) []setting.Setting {
+	settings := []setting.Setting{}
+	for _, entry := range Registry {
+		if entry.DAO == settingDao {
+			settings = append(settings, entry)
+		}
+	}
+	return settings
+}
+
+// Read the values of settings in a single multicall
+func GetSettingValues(rp *rocketpool.RocketPool, multicallerAddress common.Address, settings []setting.Setting, opts *bind.CallOpts) ([]SettingReading, error) {
+
+	// Create the multicaller
+	if opts == nil {
+		opts = &bind.CallOpts{}
+	}
+	mc, err := multicall.NewMultiCaller(rp.Client, multicallerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("Could not create multicaller: %w", err)
+	}
+
+	// Add the getter calls
+	contracts := map[string]*rocketpool.Contract{}
+	outputs := make([]interface{}, len(settings))
+	for i, entry := range settings {
+		settingsContract, exists := contracts[entry.ContractName]
+		if !exists {
+			settingsContract, err = rp.GetContract(entry.ContractName, opts)
+			if err != nil {
+				return nil, err
+			}
+			contracts[entry.ContractName] = settingsContract
+		}
+		if entry.Type == setting.TypeBool {
+			outputs[i] = new(bool)
+		} else {
+			outputs[i] = new(*big.Int)
+		}
+		if err := mc.AddCall(settingsContract, outputs[i], entry.Getter); err != nil {
+			return nil, fmt.Errorf("Could not add getter for setting %s: %w", entry.Path, err)
+		}
+	}
+
+	// Run the calls
+	if _, err := mc.FlexibleCall(true, opts); err != nil {
+		return nil, fmt.Errorf("Could not get setting values: %w", err)
+	}
+
+	// Format the values
+	readings := make([]SettingReading, len(settings))
+	for i, entry := range settings {
+		var value interface{}
+		switch output := outputs[i].(type) {
+		case *bool:
+			value = *output
+		case **big.Int:
+			value = *output
+		}
+		readings[i] = SettingReading{
+			Setting: entry,
+			Value:   dao.NewSettingValue(value, entry.Unit),
+		}
+	}
+	return readings, nil
+
+}
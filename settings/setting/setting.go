@@ -0,0 +1,236 @@
+package setting
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/dao"
+	protocoldao "github.com/Seb369888/poolsea-go/dao/protocol"
+	trustednodedao "github.com/Seb369888/poolsea-go/dao/trustednode"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+// The DAO that owns a setting
+type DAO string
+
+const (
+	DAOProtocol    DAO = "protocol"
+	DAOTrustedNode DAO = "trustednode"
+)
+
+// The type of a setting value; uint settings are *big.Int values and bool settings are bool values
+type Type string
+
+const (
+	TypeUint Type = "uint"
+	TypeBool Type = "bool"
+)
+
+// A DAO setting and how to read and change it
+type Setting struct {
+	DAO          DAO                 `json:"dao"`
+	ContractName string              `json:"contractName"`
+	Path         string              `json:"path"`
+	Getter       string              `json:"getter"`
+	Description  string              `json:"description"`
+	Type         Type                `json:"type"`
+	Unit         rptypes.SettingUnit `json:"unit"`
+	Minimum      *big.Int            `json:"minimum,omitempty"` // The smallest value the settings contract accepts for uint settings, or nil for no bound
+	Maximum      *big.Int            `json:"maximum,omitempty"` // The largest value the settings contract accepts for uint settings, or nil for no bound
+}
+
+// Create a protocol DAO uint setting
+func ProtocolUint(contractName, path, getter, description string, unit rptypes.SettingUnit, minimum, maximum *big.Int) Setting {
+	return newSetting(DAOProtocol, contractName, path, getter, description, TypeUint, unit, minimum, maximum)
+}
+
+// Create a protocol DAO bool setting
+func ProtocolBool(contractName, path, getter, description string) Setting {
+	return newSetting(DAOProtocol, contractName, path, getter, description, TypeBool, rptypes.SettingUnitNone, nil, nil)
+}
+
+// Create an oracle DAO uint setting
+func TrustedNodeUint(contractName, path, getter, description string, unit rptypes.SettingUnit, minimum, maximum *big.Int) Setting {
+	return newSetting(DAOTrustedNode, contractName, path, getter, description, TypeUint, unit, minimum, maximum)
+}
+
+// Create an oracle DAO bool setting
+func TrustedNodeBool(contractName, path, getter, description string) Setting {
+	return newSetting(DAOTrustedNode, contractName, path, getter, description, TypeBool, rptypes.SettingUnitNone, nil, nil)
+}
+
+// Create a setting and register its unit with the proposal payload decoder
+func newSetting(settingDao DAO, contractName, path, getter, description string, settingType Type, unit rptypes.SettingUnit, minimum, maximum *big.Int) Setting {
+	dao.RegisterSettingUnit(contractName, path, unit)
+	return Setting{
+		DAO:          settingDao,
+		ContractName: contractName,
+		Path:         path,
+		Getter:       getter,
+		Description:  description,
+		Type:         settingType,
+		Unit:         unit,
+		Minimum:      minimum,
+		Maximum:      maximum,
+	}
+}
+
+// Get the value of a uint setting
+func (s Setting) GetUint(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*big.Int, error) {
+	if s.Type != TypeUint {
+		return nil, fmt.Errorf("Setting %s is not a uint setting", s.Path)
+	}
+	settingsContract, err := rp.GetContract(s.ContractName, opts)
+	if err != nil {
+		return nil, err
+	}
+	value := new(*big.Int)
+	if err := settingsContract.Call(opts, value, s.Getter); err != nil {
+		return nil, fmt.Errorf("Could not get %s: %w", s.Description, err)
+	}
+	return *value, nil
+}
+
+// Get the value of a bool setting
+func (s Setting) GetBool(rp *rocketpool.RocketPool, opts *bind.CallOpts) (bool, error) {
+	if s.Type != TypeBool {
+		return false, fmt.Errorf("Setting %s is not a bool setting", s.Path)
+	}
+	settingsContract, err := rp.GetContract(s.ContractName, opts)
+	if err != nil {
+		return false, err
+	}
+	value := new(bool)
+	if err := settingsContract.Call(opts, value, s.Getter); err != nil {
+		return false, fmt.Errorf("Could not get %s: %w", s.Description, err)
+	}
+	return *value, nil
+}
+
+// Get the value of the setting, formatted with its unit
+func (s Setting) GetValue(rp *rocketpool.RocketPool, opts *bind.CallOpts) (dao.SettingValue, error) {
+	var value interface{}
+	var err error
+	if s.Type == TypeBool {
+		value, err = s.GetBool(rp, opts)
+	} else {
+		value, err = s.GetUint(rp, opts)
+	}
+	if err != nil {
+		return dao.SettingValue{}, err
+	}
+	return dao.NewSettingValue(value, s.Unit), nil
+}
+
+// Check that a value has the setting's type and is within the range the settings contract accepts
+func (s Setting) Validate(value interface{}) error {
+	switch s.Type {
+	case TypeBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("Setting %s requires a bool value", s.Path)
+		}
+	case TypeUint:
+		uintValue, ok := value.(*big.Int)
+		if !ok || uintValue == nil {
+			return fmt.Errorf("Setting %s requires a uint value", s.Path)
+		}
+		if uintValue.Sign() < 0 {
+			return fmt.Errorf("Setting %s cannot be negative", s.Path)
+		}
+		if s.Minimum != nil && uintValue.Cmp(s.Minimum) < 0 {
+			return fmt.Errorf("Setting %s must be at least %s", s.Path, dao.NewSettingValue(s.Minimum, s.Unit).Formatted)
+		}
+		if s.Maximum != nil && uintValue.Cmp(s.Maximum) > 0 {
+			return fmt.Errorf("Setting %s must be at most %s", s.Path, dao.NewSettingValue(s.Maximum, s.Unit).Formatted)
+		}
+	default:
+		return fmt.Errorf("Setting %s has unknown type %s", s.Path, s.Type)
+	}
+	return nil
+}
+
+// Bootstrap a uint setting without validating the value
+func (s Setting) BootstrapUint(rp *rocketpool.RocketPool, value *big.Int, opts *bind.TransactOpts) (common.Hash, error) {
+	if s.DAO == DAOTrustedNode {
+		return trustednodedao.BootstrapUint(rp, s.ContractName, s.Path, value, opts)
+	}
+	return protocoldao.BootstrapUint(rp, s.ContractName, s.Path, value, opts)
+}
+
+// Bootstrap a bool setting
+func (s Setting) BootstrapBool(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (common.Hash, error) {
+	if s.DAO == DAOTrustedNode {
+		return trustednodedao.BootstrapBool(rp, s.ContractName, s.Path, value, opts)
+	}
+	return protocoldao.BootstrapBool(rp, s.ContractName, s.Path, value, opts)
+}
+
+// Bootstrap the setting after validating the value; value must be a *big.Int for uint settings or a bool for bool settings
+func (s Setting) Bootstrap(rp *rocketpool.RocketPool, value interface{}, opts *bind.TransactOpts) (common.Hash, error) {
+	if err := s.Validate(value); err != nil {
+		return common.Hash{}, err
+	}
+	if s.Type == TypeBool {
+		return s.BootstrapBool(rp, value.(bool), opts)
+	}
+	return s.BootstrapUint(rp, value.(*big.Int), opts)
+}
+
+// Estimate the gas of ProposeUint
+func (s Setting) EstimateProposeUintGas(rp *rocketpool.RocketPool, value *big.Int, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
+	return trustednodedao.EstimateProposeSetUintGas(rp, s.GetProposalMessage(), s.ContractName, s.Path, value, opts)
+}
+
+// Propose a new value for an oracle DAO uint setting without validating it
+func (s Setting) ProposeUint(rp *rocketpool.RocketPool, value *big.Int, opts *bind.TransactOpts) (uint64, common.Hash, error) {
+	return trustednodedao.ProposeSetUint(rp, s.GetProposalMessage(), s.ContractName, s.Path, value, opts)
+}
+
+// Estimate the gas of ProposeBool
+func (s Setting) EstimateProposeBoolGas(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
+	return trustednodedao.EstimateProposeSetBoolGas(rp, s.GetProposalMessage(), s.ContractName, s.Path, value, opts)
+}
+
+// Propose a new value for an oracle DAO bool setting
+func (s Setting) ProposeBool(rp *rocketpool.RocketPool, value bool, opts *bind.TransactOpts) (uint64, common.Hash, error) {
+	return trustednodedao.ProposeSetBool(rp, s.GetProposalMessage(), s.ContractName, s.Path, value, opts)
+}
+
+// Estimate the gas of Propose
+func (s Setting) EstimateProposeGas(rp *rocketpool.RocketPool, value interface{}, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
+	if err := s.checkPropose(value); err != nil {
+		return rocketpool.GasInfo{}, err
+	}
+	if s.Type == TypeBool {
+		return s.EstimateProposeBoolGas(rp, value.(bool), opts)
+	}
+	return s.EstimateProposeUintGas(rp, value.(*big.Int), opts)
+}
+
+// Propose a new value for the setting after validating it; only oracle DAO settings can be proposed
+func (s Setting) Propose(rp *rocketpool.RocketPool, value interface{}, opts *bind.TransactOpts) (uint64, common.Hash, error) {
+	if err := s.checkPropose(value); err != nil {
+		return 0, common.Hash{}, err
+	}
+	if s.Type == TypeBool {
+		return s.ProposeBool(rp, value.(bool), opts)
+	}
+	return s.ProposeUint(rp, value.(*big.Int), opts)
+}
+
+// Get the message used when proposing the setting
+func (s Setting) GetProposalMessage() string {
+	return fmt.Sprintf("set %s", s.Path)
+}
+
+// Check that a value can be proposed for the setting
+func (s Setting) checkPropose(value interface{}) error {
+	if s.DAO != DAOTrustedNode {
+		return fmt.Errorf("Setting %s belongs to the %s DAO and cannot be proposed", s.Path, s.DAO)
+	}
+	return s.Validate(value)
+}
@@ -1,15 +1,14 @@
 package trustednode
 
 import (
-	"fmt"
 	"math/big"
-	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 
-	trustednodedao "github.com/Seb369888/poolsea-go/dao/trustednode"
 	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings/setting"
+	rptypes "github.com/Seb369888/poolsea-go/types"
 )
 
 // Config
@@ -22,121 +21,101 @@ const (
 	ActionTimeSettingPath         = "proposal.action.time"
 )
 
+// Settings
+var (
+	ProposalCooldownTimeSetting  = setting.TrustedNodeUint(ProposalsSettingsContractName, CooldownTimeSettingPath, "getCooldownTime", "proposal cooldown period", rptypes.SettingUnitSeconds, nil, nil)
+	ProposalVoteTimeSetting      = setting.TrustedNodeUint(ProposalsSettingsContractName, VoteTimeSettingPath, "getVoteTime", "proposal voting period", rptypes.SettingUnitSeconds, nil, nil)
+	ProposalVoteDelayTimeSetting = setting.TrustedNodeUint(ProposalsSettingsContractName, VoteDelayTimeSettingPath, "getVoteDelayTime", "proposal voting delay", rptypes.SettingUnitSeconds, nil, nil)
+	ProposalExecuteTimeSetting   = setting.TrustedNodeUint(ProposalsSettingsContractName, ExecuteTimeSettingPath, "getExecuteTime", "proposal execution period", rptypes.SettingUnitSeconds, nil, nil)
+	ProposalActionTimeSetting    = setting.TrustedNodeUint(ProposalsSettingsContractName, ActionTimeSettingPath, "getActionTime", "proposal action period", rptypes.SettingUnitSeconds, nil, nil)
+)
+
 // The cooldown period a member must wait after making a proposal before making another in seconds
 func GetProposalCooldownTime(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
-	proposalsSettingsContract, err := getProposalsSettingsContract(rp, opts)
+	value, err := ProposalCooldownTimeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := proposalsSettingsContract.Call(opts, value, "getCooldownTime"); err != nil {
-		return 0, fmt.Errorf("Could not get proposal cooldown period: %w", err)
-	}
-	return (*value).Uint64(), nil
+	return value.Uint64(), nil
 }
 func BootstrapProposalCooldownTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (common.Hash, error) {
-	return trustednodedao.BootstrapUint(rp, ProposalsSettingsContractName, CooldownTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalCooldownTimeSetting.BootstrapUint(rp, big.NewInt(int64(value)), opts)
 }
 func ProposeProposalCooldownTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (uint64, common.Hash, error) {
-	return trustednodedao.ProposeSetUint(rp, fmt.Sprintf("set %s", CooldownTimeSettingPath), ProposalsSettingsContractName, CooldownTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalCooldownTimeSetting.ProposeUint(rp, big.NewInt(int64(value)), opts)
 }
 func EstimateProposeProposalCooldownTimeGas(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
-	return trustednodedao.EstimateProposeSetUintGas(rp, fmt.Sprintf("set %s", CooldownTimeSettingPath), ProposalsSettingsContractName, CooldownTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalCooldownTimeSetting.EstimateProposeUintGas(rp, big.NewInt(int64(value)), opts)
 }
 
 // The period a proposal can be voted on for in seconds
 func GetProposalVoteTime(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
-	proposalsSettingsContract, err := getProposalsSettingsContract(rp, opts)
+	value, err := ProposalVoteTimeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := proposalsSettingsContract.Call(opts, value, "getVoteTime"); err != nil {
-		return 0, fmt.Errorf("Could not get proposal voting period: %w", err)
-	}
-	return (*value).Uint64(), nil
+	return value.Uint64(), nil
 }
 func BootstrapProposalVoteTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (common.Hash, error) {
-	return trustednodedao.BootstrapUint(rp, ProposalsSettingsContractName, VoteTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalVoteTimeSetting.BootstrapUint(rp, big.NewInt(int64(value)), opts)
 }
 func ProposeProposalVoteTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (uint64, common.Hash, error) {
-	return trustednodedao.ProposeSetUint(rp, fmt.Sprintf("set %s", VoteTimeSettingPath), ProposalsSettingsContractName, VoteTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalVoteTimeSetting.ProposeUint(rp, big.NewInt(int64(value)), opts)
 }
 func EstimateProposeProposalVoteTimeGas(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
-	return trustednodedao.EstimateProposeSetUintGas(rp, fmt.Sprintf("set %s", VoteTimeSettingPath), ProposalsSettingsContractName, VoteTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalVoteTimeSetting.EstimateProposeUintGas(rp, big.NewInt(int64(value)), opts)
 }
 
 // The delay after creation before a proposal can be voted on in seconds
 func GetProposalVoteDelayTime(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
-	proposalsSettingsContract, err := getProposalsSettingsContract(rp, opts)
+	value, err := ProposalVoteDelayTimeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := proposalsSettingsContract.Call(opts, value, "getVoteDelayTime"); err != nil {
-		return 0, fmt.Errorf("Could not get proposal voting delay: %w", err)
-	}
-	return (*value).Uint64(), nil
+	return value.Uint64(), nil
 }
 func BootstrapProposalVoteDelayTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (common.Hash, error) {
-	return trustednodedao.BootstrapUint(rp, ProposalsSettingsContractName, VoteDelayTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalVoteDelayTimeSetting.BootstrapUint(rp, big.NewInt(int64(value)), opts)
 }
 func ProposeProposalVoteDelayTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (uint64, common.Hash, error) {
-	return trustednodedao.ProposeSetUint(rp, fmt.Sprintf("set %s", VoteDelayTimeSettingPath), ProposalsSettingsContractName, VoteDelayTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalVoteDelayTimeSetting.ProposeUint(rp, big.NewInt(int64(value)), opts)
 }
 func EstimateProposeProposalVoteDelayTimeGas(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
-	return trustednodedao.EstimateProposeSetUintGas(rp, fmt.Sprintf("set %s", VoteDelayTimeSettingPath), ProposalsSettingsContractName, VoteDelayTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalVoteDelayTimeSetting.EstimateProposeUintGas(rp, big.NewInt(int64(value)), opts)
 }
 
 // The period during which a passed proposal can be executed in time
 func GetProposalExecuteTime(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
-	proposalsSettingsContract, err := getProposalsSettingsContract(rp, opts)
+	value, err := ProposalExecuteTimeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := proposalsSettingsContract.Call(opts, value, "getExecuteTime"); err != nil {
-		return 0, fmt.Errorf("Could not get proposal execution period: %w", err)
-	}
-	return (*value).Uint64(), nil
+	return value.Uint64(), nil
 }
 func BootstrapProposalExecuteTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (common.Hash, error) {
-	return trustednodedao.BootstrapUint(rp, ProposalsSettingsContractName, ExecuteTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalExecuteTimeSetting.BootstrapUint(rp, big.NewInt(int64(value)), opts)
 }
 func ProposeProposalExecuteTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (uint64, common.Hash, error) {
-	return trustednodedao.ProposeSetUint(rp, fmt.Sprintf("set %s", ExecuteTimeSettingPath), ProposalsSettingsContractName, ExecuteTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalExecuteTimeSetting.ProposeUint(rp, big.NewInt(int64(value)), opts)
 }
 func EstimateProposeProposalExecuteTimeGas(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
-	return trustednodedao.EstimateProposeSetUintGas(rp, fmt.Sprintf("set %s", ExecuteTimeSettingPath), ProposalsSettingsContractName, ExecuteTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalExecuteTimeSetting.EstimateProposeUintGas(rp, big.NewInt(int64(value)), opts)
 }
 
 // The period during which an action can be performed on an executed proposal in seconds
 func GetProposalActionTime(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
-	proposalsSettingsContract, err := getProposalsSettingsContract(rp, opts)
+	value, err := ProposalActionTimeSetting.GetUint(rp, opts)
 	if err != nil {
 		return 0, err
 	}
-	value := new(*big.Int)
-	if err := proposalsSettingsContract.Call(opts, value, "getActionTime"); err != nil {
-		return 0, fmt.Errorf("Could not get proposal action period: %w", err)
-	}
-	return (*value).Uint64(), nil
+	return value.Uint64(), nil
 }
 func BootstrapProposalActionTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (common.Hash, error) {
-	return trustednodedao.BootstrapUint(rp, ProposalsSettingsContractName, ActionTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalActionTimeSetting.BootstrapUint(rp, big.NewInt(int64(value)), opts)
 }
 func ProposeProposalActionTime(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (uint64, common.Hash, error) {
-	return trustednodedao.ProposeSetUint(rp, fmt.Sprintf("set %s", ActionTimeSettingPath), ProposalsSettingsContractName, ActionTimeSettingPath, big.NewInt(int64(value)), opts)
+	return ProposalActionTimeSetting.ProposeUint(rp, big.NewInt(int64(value)), opts)
 }
 func EstimateProposeProposalActionTimeGas(rp *rocketpool.RocketPool, value uint64, opts *bind.TransactOpts) (rocketpool.GasInfo, error) {
-	return trustednodedao.EstimateProposeSetUintGas(rp, fmt.Sprintf("set %s", ActionTimeSettingPath), ProposalsSettingsContractName, ActionTimeSettingPath, big.NewInt(int64(value)), opts)
-}
-
-// Get contracts
-var proposalsSettingsContractLock sync.Mutex
-
-func getProposalsSettingsContract(rp *rocketpool.RocketPool, opts *bind.CallOpts) (*rocketpool.Contract, error) {
-	proposalsSettingsContractLock.Lock()
-	defer proposalsSettingsContractLock.Unlock()
-	return rp.GetContract(ProposalsSettingsContractName, opts)
+	return ProposalActionTimeSetting.EstimateProposeUintGas(rp, big.NewInt(int64(value)), opts)
 }
@@ -0,0 +1,142 @@
+package auction
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Seb369888/poolsea-go/auction"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/fakeclient"
+)
+
+func TestBiddingStrategy(t *testing.T) {
+
+	strategy := auction.NewBiddingStrategy(auction.BiddingStrategyConfig{
+		MinimumDiscount: big.NewInt(1e17), // 10%
+		MaxBidPerLot:    eth.EthToWei(1),
+		MaxBidPerRound:  eth.EthToWei(1.5),
+		ClaimBids:       true,
+		RecoverRPL:      true,
+	}, nil)
+
+	// Open lot at a 20% discount with an earlier bid from the bidder
+	openLot := auction.LotDetails{
+		Index:              0,
+		Exists:             true,
+		StartBlock:         100,
+		EndBlock:           200,
+		StartPrice:         big.NewInt(8e15),
+		ReservePrice:       big.NewInt(8e15),
+		CurrentPrice:       big.NewInt(8e15),
+		TotalRPLAmount:     eth.EthToWei(1000),
+		TotalBidAmount:     eth.EthToWei(0.25),
+		AddressBidAmount:   eth.EthToWei(0.25),
+		RemainingRPLAmount: eth.EthToWei(968.75),
+	}
+
+	// Second open lot at the same discount, limited by the round budget
+	secondLot := openLot
+	secondLot.Index = 1
+	secondLot.TotalBidAmount = big.NewInt(0)
+	secondLot.AddressBidAmount = big.NewInt(0)
+
+	// Open lot at a 5% discount
+	expensiveLot := openLot
+	expensiveLot.Index = 2
+	expensiveLot.CurrentPrice = big.NewInt(95e14)
+
+	// Cleared lot with a bid and unclaimed RPL
+	clearedLot := openLot
+	clearedLot.Index = 3
+	clearedLot.Cleared = true
+
+	actions := strategy.Evaluate([]auction.LotDetails{openLot, secondLot, expensiveLot, clearedLot}, big.NewInt(1e16), nil, 150)
+	if len(actions) != 4 {
+		t.Fatalf("Incorrect action count %d: %v", len(actions), actions)
+	}
+	if actions[0].Type != auction.BidActionPlaceBid || actions[0].Amount.Cmp(eth.EthToWei(0.75)) != 0 {
+		t.Errorf("Incorrect first bid %s of %s", actions[0].Type, actions[0].Amount.String())
+	}
+	if actions[0].Discount.Cmp(big.NewInt(2e17)) != 0 {
+		t.Errorf("Incorrect discount %s", actions[0].Discount.String())
+	}
+	if actions[1].Type != auction.BidActionPlaceBid || actions[1].LotIndex != 1 || actions[1].Amount.Cmp(eth.EthToWei(0.75)) != 0 {
+		t.Errorf("Incorrect second bid on lot %d of %s", actions[1].LotIndex, actions[1].Amount.String())
+	}
+	if actions[2].Type != auction.BidActionClaimBid || actions[3].Type != auction.BidActionRecoverRPL {
+		t.Errorf("Incorrect settlement actions %s and %s", actions[2].Type, actions[3].Type)
+	}
+
+	// A lower external price removes the discount
+	actions = strategy.Evaluate([]auction.LotDetails{openLot}, big.NewInt(1e16), big.NewInt(85e14), 150)
+	if len(actions) != 0 {
+		t.Errorf("Bid was placed without the discount against the external price: %v", actions)
+	}
+
+}
+
+func TestBiddingStrategyExecute(t *testing.T) {
+
+	// Set up the auction manager on a fake client
+	storageAddress := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	auctionManagerAddress := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	client := fakeclient.NewClient(1000)
+	storage, err := client.AddStorage(storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("deploy.block")), 0, big.NewInt(100))
+	if err := storage.SetContract("poolseaAuctionManager", 0, auctionManagerAddress, `[
+		{"type":"function","name":"placeBid","stateMutability":"payable","inputs":[{"name":"_lotIndex","type":"uint256"}],"outputs":[]},
+		{"type":"function","name":"claimBid","stateMutability":"nonpayable","inputs":[{"name":"_lotIndex","type":"uint256"}],"outputs":[]}
+	]`); err != nil {
+		t.Fatal(err)
+	}
+	rp, err := rocketpool.NewRocketPool(client, storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run a bid and a claim with a fixed nonce and gas limit
+	strategy := auction.NewBiddingStrategy(auction.BiddingStrategyConfig{}, nil)
+	opts := &bind.TransactOpts{
+		From:     common.HexToAddress("0x4000000000000000000000000000000000000001"),
+		Signer:   func(address common.Address, tx *types.Transaction) (*types.Transaction, error) { return tx, nil },
+		Nonce:    big.NewInt(5),
+		GasLimit: 21000,
+	}
+	hashes, err := strategy.Execute(rp, []auction.BidAction{
+		{Type: auction.BidActionPlaceBid, LotIndex: 0, Amount: eth.EthToWei(0.5)},
+		{Type: auction.BidActionClaimBid, LotIndex: 1},
+	}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 2 || len(client.Transactions) != 2 {
+		t.Fatalf("Incorrect transaction count %d", len(client.Transactions))
+	}
+
+	// Each transaction gets the next nonce and its own gas estimate, and only bids send ETH
+	for i, tx := range client.Transactions {
+		if tx.Nonce() != uint64(5+i) {
+			t.Errorf("Incorrect nonce %d for transaction %d", tx.Nonce(), i)
+		}
+		if tx.Gas() != uint64(float64(client.GasEstimate)*rocketpool.GasLimitMultiplier) {
+			t.Errorf("Incorrect gas limit %d for transaction %d", tx.Gas(), i)
+		}
+	}
+	if client.Transactions[0].Value().Cmp(eth.EthToWei(0.5)) != 0 || client.Transactions[1].Value().Sign() != 0 {
+		t.Errorf("Incorrect transaction values %s and %s", client.Transactions[0].Value().String(), client.Transactions[1].Value().String())
+	}
+	if opts.Nonce.Cmp(big.NewInt(5)) != 0 || opts.GasLimit != 21000 {
+		t.Error("The caller's transaction options were modified")
+	}
+
+}
@@ -0,0 +1,168 @@
+package auction
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Seb369888/poolsea-go/auction"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/fakeclient"
+)
+
+const (
+	lotHistoryUpgradeAbi = `[
+		{"type":"event","name":"ContractUpgraded","anonymous":false,"inputs":[{"name":"name","type":"bytes32","indexed":true},{"name":"oldAddress","type":"address","indexed":true},{"name":"newAddress","type":"address","indexed":true},{"name":"time","type":"uint256","indexed":false}]}
+	]`
+	lotHistoryAuctionManagerV1 = `[
+		{"type":"event","name":"LotCreated","anonymous":false,"inputs":[{"name":"lotIndex","type":"uint256","indexed":true},{"name":"by","type":"address","indexed":true},{"name":"rplAmount","type":"uint256","indexed":false},{"name":"time","type":"uint256","indexed":false}]},
+		{"type":"event","name":"BidPlaced","anonymous":false,"inputs":[{"name":"lotIndex","type":"uint256","indexed":true},{"name":"bidder","type":"address","indexed":true},{"name":"bidAmount","type":"uint256","indexed":false},{"name":"time","type":"uint256","indexed":false}]},
+		{"type":"event","name":"BidClaimed","anonymous":false,"inputs":[{"name":"lotIndex","type":"uint256","indexed":true},{"name":"bidder","type":"address","indexed":true},{"name":"bidAmount","type":"uint256","indexed":false},{"name":"rplAmount","type":"uint256","indexed":false},{"name":"time","type":"uint256","indexed":false}]},
+		{"type":"event","name":"RPLRecovered","anonymous":false,"inputs":[{"name":"lotIndex","type":"uint256","indexed":true},{"name":"by","type":"address","indexed":true},{"name":"rplAmount","type":"uint256","indexed":false},{"name":"time","type":"uint256","indexed":false}]}
+	]`
+	lotHistoryAuctionManagerV2 = `[
+		{"type":"event","name":"LotCreated","anonymous":false,"inputs":[{"name":"lotIndex","type":"uint256","indexed":true},{"name":"by","type":"address","indexed":true},{"name":"rplAmount","type":"uint256","indexed":false},{"name":"time","type":"uint256","indexed":false}]},
+		{"type":"event","name":"BidPlaced","anonymous":false,"inputs":[{"name":"lotIndex","type":"uint256","indexed":true},{"name":"bidder","type":"address","indexed":true},{"name":"bidAmount","type":"uint256","indexed":false},{"name":"rplAmount","type":"uint256","indexed":false},{"name":"time","type":"uint256","indexed":false}]},
+		{"type":"event","name":"BidClaimed","anonymous":false,"inputs":[{"name":"lotIndex","type":"uint256","indexed":true},{"name":"bidder","type":"address","indexed":true},{"name":"bidAmount","type":"uint256","indexed":false},{"name":"rplAmount","type":"uint256","indexed":false},{"name":"time","type":"uint256","indexed":false}]},
+		{"type":"event","name":"RPLRecovered","anonymous":false,"inputs":[{"name":"lotIndex","type":"uint256","indexed":true},{"name":"by","type":"address","indexed":true},{"name":"rplAmount","type":"uint256","indexed":false},{"name":"time","type":"uint256","indexed":false}]}
+	]`
+)
+
+// Create a log for an auction manager event, encoded with the provided ABI
+func lotHistoryEventLog(t *testing.T, contractAbi string, address common.Address, block uint64, eventName string, lotIndex uint64, bidder common.Address, values ...interface{}) types.Log {
+	parsed, err := abi.JSON(strings.NewReader(contractAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := parsed.Events[eventName]
+	data, err := event.Inputs.NonIndexed().Pack(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.Log{
+		Address:     address,
+		Topics:      []common.Hash{event.ID, common.BigToHash(new(big.Int).SetUint64(lotIndex)), common.BytesToHash(bidder.Bytes())},
+		Data:        data,
+		BlockNumber: block,
+	}
+}
+
+func TestLotSettlementReport(t *testing.T) {
+
+	bidder1 := common.HexToAddress("0x01")
+	bidder2 := common.HexToAddress("0x02")
+	lot := auction.LotDetails{
+		Index:          4,
+		Cleared:        true,
+		CurrentPrice:   big.NewInt(8e15),
+		TotalRPLAmount: eth.EthToWei(1000),
+		TotalBidAmount: eth.EthToWei(6),
+	}
+	history := auction.LotHistory{
+		LotIndex: 4,
+		Events: []auction.LotEvent{
+			{Type: auction.LotEventCreated, LotIndex: 4, RPLAmount: eth.EthToWei(1000)},
+			{Type: auction.LotEventBidPlaced, LotIndex: 4, Address: bidder1, BidAmount: eth.EthToWei(2)},
+			{Type: auction.LotEventBidPlaced, LotIndex: 4, Address: bidder2, BidAmount: eth.EthToWei(2)},
+			{Type: auction.LotEventBidPlaced, LotIndex: 4, Address: bidder1, BidAmount: eth.EthToWei(2)},
+			{Type: auction.LotEventBidClaimed, LotIndex: 4, Address: bidder1, BidAmount: eth.EthToWei(4), RPLAmount: eth.EthToWei(500)},
+			{Type: auction.LotEventRPLRecovered, LotIndex: 4, RPLAmount: eth.EthToWei(250)},
+		},
+	}
+
+	report := auction.BuildLotSettlementReport(lot, history)
+	if len(report.Bidders) != 2 {
+		t.Fatalf("Incorrect bidder count %d", len(report.Bidders))
+	}
+
+	// The first bidder claimed
+	first := report.Bidders[0]
+	if first.Bidder != bidder1 || first.BidCount != 2 || !first.Claimed {
+		t.Errorf("Incorrect first bidder settlement %+v", first)
+	}
+	if first.EffectivePrice.Cmp(big.NewInt(8e15)) != 0 {
+		t.Errorf("Incorrect first bidder effective price %s", first.EffectivePrice.String())
+	}
+
+	// The second bidder hasn't claimed yet
+	second := report.Bidders[1]
+	if second.Claimed || second.RPLAmount.Cmp(eth.EthToWei(250)) != 0 {
+		t.Errorf("Incorrect second bidder RPL amount %s", second.RPLAmount.String())
+	}
+
+	if report.RPLSold.Cmp(eth.EthToWei(750)) != 0 || report.RPLClaimed.Cmp(eth.EthToWei(500)) != 0 || report.RPLRecovered.Cmp(eth.EthToWei(250)) != 0 {
+		t.Errorf("Incorrect RPL totals: sold %s, claimed %s, recovered %s", report.RPLSold.String(), report.RPLClaimed.String(), report.RPLRecovered.String())
+	}
+
+}
+
+func TestLotHistoryAcrossDeployments(t *testing.T) {
+
+	// The auction manager was upgraded on block 500, which changed the BidPlaced event
+	storageAddress := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	upgradeAddress := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	oldAuctionManagerAddress := common.HexToAddress("0x2000000000000000000000000000000000000001")
+	auctionManagerAddress := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	bidder := common.HexToAddress("0x4000000000000000000000000000000000000001")
+	client := fakeclient.NewClient(1000)
+	storage, err := client.AddStorage(storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("deploy.block")), 0, big.NewInt(100))
+	if err := storage.SetContract("poolseaDAONodeTrustedUpgrade", 0, upgradeAddress, lotHistoryUpgradeAbi); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.SetContract("poolseaAuctionManager", 0, oldAuctionManagerAddress, lotHistoryAuctionManagerV1); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.SetContract("poolseaAuctionManager", 500, auctionManagerAddress, lotHistoryAuctionManagerV2); err != nil {
+		t.Fatal(err)
+	}
+	upgradeAbi, err := abi.JSON(strings.NewReader(lotHistoryUpgradeAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Logs = []types.Log{
+		lotHistoryEventLog(t, lotHistoryAuctionManagerV1, oldAuctionManagerAddress, 200, "BidPlaced", 3, bidder, eth.EthToWei(1), big.NewInt(1600000000)),
+		{
+			Address:     upgradeAddress,
+			Topics:      []common.Hash{upgradeAbi.Events["ContractUpgraded"].ID, crypto.Keccak256Hash([]byte("poolseaAuctionManager")), common.BytesToHash(oldAuctionManagerAddress.Bytes()), common.BytesToHash(auctionManagerAddress.Bytes())},
+			BlockNumber: 500,
+		},
+		lotHistoryEventLog(t, lotHistoryAuctionManagerV2, auctionManagerAddress, 600, "BidPlaced", 3, bidder, eth.EthToWei(2), eth.EthToWei(250), big.NewInt(1600001000)),
+	}
+	rp, err := rocketpool.NewRocketPool(client, storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each bid is found and decoded with the ABI of the deployment that emitted it
+	history, err := auction.GetLotHistory(rp, 3, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Events) != 2 {
+		t.Fatalf("Incorrect event count %d: %+v", len(history.Events), history.Events)
+	}
+	for i, expected := range []struct {
+		bidAmount *big.Int
+		time      int64
+	}{{eth.EthToWei(1), 1600000000}, {eth.EthToWei(2), 1600001000}} {
+		event := history.Events[i]
+		if event.Type != auction.LotEventBidPlaced || event.Address != bidder || event.BidAmount.Cmp(expected.bidAmount) != 0 || event.Time.Unix() != expected.time {
+			t.Errorf("Incorrect event %d: %+v", i, event)
+		}
+	}
+	if history.Events[0].RPLAmount != nil || history.Events[1].RPLAmount.Cmp(eth.EthToWei(250)) != 0 {
+		t.Errorf("Incorrect RPL amounts %v and %v", history.Events[0].RPLAmount, history.Events[1].RPLAmount)
+	}
+
+}
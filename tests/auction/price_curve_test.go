@@ -0,0 +1,178 @@
+package auction
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Seb369888/poolsea-go/auction"
+	"github.com/Seb369888/poolsea-go/network"
+	"github.com/Seb369888/poolsea-go/settings/protocol"
+	"github.com/Seb369888/poolsea-go/settings/trustednode"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+
+	auctionutils "github.com/Seb369888/poolsea-go/tests/testutils/auction"
+	"github.com/Seb369888/poolsea-go/tests/testutils/evm"
+	nodeutils "github.com/Seb369888/poolsea-go/tests/testutils/node"
+)
+
+func TestLotPriceCurve(t *testing.T) {
+
+	// State snapshotting
+	if err := evm.TakeSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := evm.RevertSnapshot(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Register nodes
+	if err := nodeutils.RegisterTrustedNode(rp, ownerAccount, trustedNodeAccount1); err != nil {
+		t.Fatal(err)
+	}
+	if err := nodeutils.RegisterTrustedNode(rp, ownerAccount, trustedNodeAccount2); err != nil {
+		t.Fatal(err)
+	}
+	if err := nodeutils.RegisterTrustedNode(rp, ownerAccount, trustedNodeAccount3); err != nil {
+		t.Fatal(err)
+	}
+
+	// Disable min commission rate for unbonded pools
+	if _, err := trustednode.BootstrapMinipoolUnbondedMinFee(rp, uint64(0), ownerAccount.GetTransactor()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Set network parameters
+	if _, err := network.SubmitPrices(rp, 1, eth.EthToWei(1), trustedNodeAccount1.GetTransactor()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := network.SubmitPrices(rp, 1, eth.EthToWei(1), trustedNodeAccount2.GetTransactor()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := protocol.BootstrapLotStartingPriceRatio(rp, 1.0, ownerAccount.GetTransactor()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := protocol.BootstrapLotReservePriceRatio(rp, 0.5, ownerAccount.GetTransactor()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := protocol.BootstrapLotDuration(rp, 37, ownerAccount.GetTransactor()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a lot with a bid on it
+	if err := auctionutils.CreateSlashedRPL(t, rp, ownerAccount, trustedNodeAccount1, trustedNodeAccount2, userAccount1); err != nil {
+		t.Fatal(err)
+	}
+	lotIndex, _, err := auction.CreateLot(rp, userAccount1.GetTransactor())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bidOpts := userAccount1.GetTransactor()
+	bidOpts.Value = eth.EthToWei(0.01)
+	if _, err := auction.PlaceBid(rp, lotIndex, bidOpts); err != nil {
+		t.Fatal(err)
+	}
+
+	// Build the curve
+	lot, err := auction.GetLotDetails(rp, lotIndex, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curve := auction.NewLotPriceCurve(lot)
+
+	// Compare the offline prices with the contract across the lot
+	for block := lot.StartBlock - 1; block <= lot.EndBlock+1; block++ {
+		expectedPrice, err := auction.GetLotPriceAtBlock(rp, lotIndex, block, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if price := curve.GetPriceAtBlock(block); price.Cmp(expectedPrice) != 0 {
+			t.Errorf("Incorrect price at block %d: expected %s, got %s", block, expectedPrice.String(), price.String())
+		}
+	}
+	if expectedPrice, err := auction.GetLotPriceByTotalBids(rp, lotIndex, nil); err != nil {
+		t.Fatal(err)
+	} else if price := curve.GetPriceByTotalBids(); price.Cmp(expectedPrice) != 0 {
+		t.Errorf("Incorrect price by total bids: expected %s, got %s", expectedPrice.String(), price.String())
+	}
+
+	// Compare the values at the current block
+	currentBlock, err := rp.Client.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price := curve.GetCurrentPrice(currentBlock); price.Cmp(lot.CurrentPrice) != 0 {
+		t.Errorf("Incorrect current price: expected %s, got %s", lot.CurrentPrice.String(), price.String())
+	}
+	if claimed := curve.GetClaimedRPLAmount(currentBlock); claimed.Cmp(lot.ClaimedRPLAmount) != 0 {
+		t.Errorf("Incorrect claimed RPL amount: expected %s, got %s", lot.ClaimedRPLAmount.String(), claimed.String())
+	}
+	if cleared := curve.GetIsCleared(currentBlock); cleared != lot.Cleared {
+		t.Errorf("Incorrect cleared status: expected %t, got %t", lot.Cleared, cleared)
+	}
+
+}
+
+func TestLotPriceCurveOffline(t *testing.T) {
+
+	curve := auction.LotPriceCurve{
+		StartBlock:     100,
+		EndBlock:       200,
+		StartPrice:     eth.EthToWei(0.01),
+		ReservePrice:   eth.EthToWei(0.005),
+		TotalRPLAmount: eth.EthToWei(1000),
+		TotalBidAmount: eth.EthToWei(3),
+	}
+
+	// Quadratic decay: halfway through, the price has dropped by a quarter of the range
+	if price := curve.GetPriceAtBlock(150); price.Cmp(eth.EthToWei(0.00875)) != 0 {
+		t.Errorf("Incorrect halfway price %s", price.String())
+	}
+	if price := curve.GetPriceAtBlock(50); price.Cmp(curve.StartPrice) != 0 {
+		t.Errorf("Incorrect price before the start %s", price.String())
+	}
+	if price := curve.GetPriceAtBlock(250); price.Cmp(curve.ReservePrice) != 0 {
+		t.Errorf("Incorrect price after the end %s", price.String())
+	}
+
+	// The bids cover 3 ETH / 1000 RPL, which is below the reserve price, so the lot clears at the end
+	if block, price := curve.GetClearingBlock(); block != 200 || price.Cmp(curve.ReservePrice) != 0 {
+		t.Errorf("Incorrect clearing block %d at price %s", block, price.String())
+	}
+	if curve.GetIsCleared(150) {
+		t.Error("Lot was cleared early")
+	}
+
+	// With 8 ETH bid, the lot clears once the price drops to 0.008
+	curve.TotalBidAmount = eth.EthToWei(8)
+	block, price := curve.GetClearingBlock()
+	if curve.GetPriceAtBlock(block).Cmp(big.NewInt(8e15)) > 0 || curve.GetPriceAtBlock(block-1).Cmp(big.NewInt(8e15)) <= 0 {
+		t.Errorf("Incorrect clearing block %d", block)
+	}
+	if price.Cmp(big.NewInt(8e15)) != 0 {
+		t.Errorf("Incorrect clearing price %s", price.String())
+	}
+	if !curve.GetIsCleared(block) || curve.GetIsCleared(block-1) {
+		t.Error("Incorrect cleared status around the clearing block")
+	}
+	if claimed := curve.GetClaimedRPLAmount(block); claimed.Cmp(curve.TotalRPLAmount) != 0 {
+		t.Errorf("Incorrect claimed RPL amount %s", claimed.String())
+	}
+	if maxBid := curve.GetMaxBidAmount(block); maxBid.Sign() != 0 {
+		t.Errorf("Incorrect max bid amount %s", maxBid.String())
+	}
+
+	// Before clearing there's room for more bids
+	if maxBid := curve.GetMaxBidAmount(100); maxBid.Cmp(eth.EthToWei(2)) != 0 {
+		t.Errorf("Incorrect max bid amount %s", maxBid.String())
+	}
+	if points := curve.GetCurve(30); len(points) != 5 || points[4].Block != 200 {
+		t.Errorf("Incorrect curve %v", points)
+	}
+	if _, reached := curve.GetBlockAtPrice(big.NewInt(1)); reached {
+		t.Error("Price below the reserve price was reached")
+	}
+
+}
@@ -0,0 +1,100 @@
+package dao
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/dao"
+	trustednodesettings "github.com/Seb369888/poolsea-go/settings/trustednode"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+const proposalsAbi = `[
+	{"type":"function","name":"proposalSettingUint","inputs":[{"name":"_settingContractName","type":"string"},{"name":"_settingPath","type":"string"},{"name":"_value","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"proposalSettingBool","inputs":[{"name":"_settingContractName","type":"string"},{"name":"_settingPath","type":"string"},{"name":"_value","type":"bool"}],"outputs":[]},
+	{"type":"function","name":"proposalKick","inputs":[{"name":"_nodeAddress","type":"address"},{"name":"_rplFine","type":"uint256"}],"outputs":[]}
+]`
+
+func TestDecodeProposalPayload(t *testing.T) {
+
+	proposalsContractAbi, err := abi.JSON(strings.NewReader(proposalsAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Uint setting with a unit
+	payload, err := proposalsContractAbi.Pack("proposalSettingUint", trustednodesettings.MembersSettingsContractName, trustednodesettings.QuorumSettingPath, big.NewInt(51e16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := dao.DecodeProposalPayload(&proposalsContractAbi, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Method != "proposalSettingUint" {
+		t.Errorf("Incorrect method %s", decoded.Method)
+	}
+	if len(decoded.Arguments) != 3 || decoded.Arguments[1].Name != "settingPath" || decoded.Arguments[2].Type != "uint256" {
+		t.Errorf("Incorrect arguments %+v", decoded.Arguments)
+	}
+	if decoded.ContractName != trustednodesettings.MembersSettingsContractName || decoded.SettingPath != trustednodesettings.QuorumSettingPath {
+		t.Errorf("Incorrect setting %s %s", decoded.ContractName, decoded.SettingPath)
+	}
+	if decoded.ProposedValue == nil {
+		t.Fatal("Missing proposed value")
+	}
+	if decoded.ProposedValue.Unit != rptypes.SettingUnitPercent || decoded.ProposedValue.Formatted != "51%" {
+		t.Errorf("Incorrect proposed value %+v", *decoded.ProposedValue)
+	}
+	if decoded.CurrentValue != nil {
+		t.Error("Offline decoding should not have a current value")
+	}
+	if _, err := json.Marshal(decoded); err != nil {
+		t.Errorf("Could not serialize decoded payload: %s", err)
+	}
+
+	// Seconds setting
+	payload, err = proposalsContractAbi.Pack("proposalSettingUint", trustednodesettings.ProposalsSettingsContractName, trustednodesettings.VoteTimeSettingPath, big.NewInt(7200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := dao.DecodeProposalPayload(&proposalsContractAbi, payload); err != nil {
+		t.Error(err)
+	} else if decoded.ProposedValue.Formatted != "7200 seconds (2h0m0s)" {
+		t.Errorf("Incorrect proposed value %s", decoded.ProposedValue.Formatted)
+	}
+
+	// Bool setting
+	payload, err = proposalsContractAbi.Pack("proposalSettingBool", trustednodesettings.MinipoolSettingsContractName, trustednodesettings.ScrubPenaltyEnabledPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := dao.DecodeProposalPayload(&proposalsContractAbi, payload); err != nil {
+		t.Error(err)
+	} else if decoded.ProposedValue.Raw != true || decoded.ProposedValue.Formatted != "true" {
+		t.Errorf("Incorrect proposed value %+v", *decoded.ProposedValue)
+	}
+
+	// Non-setting proposal
+	kickAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	payload, err = proposalsContractAbi.Pack("proposalKick", kickAddress, big.NewInt(1e18))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := dao.DecodeProposalPayload(&proposalsContractAbi, payload); err != nil {
+		t.Error(err)
+	} else {
+		if decoded.SettingPath != "" || decoded.ProposedValue != nil {
+			t.Error("Non-setting proposal should not have a setting")
+		}
+		if decoded.Arguments[0].Name != "nodeAddress" || decoded.Arguments[0].Value != kickAddress {
+			t.Errorf("Incorrect arguments %+v", decoded.Arguments)
+		}
+	}
+
+}
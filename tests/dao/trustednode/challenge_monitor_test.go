@@ -0,0 +1,276 @@
+package trustednode
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	trustednodedao "github.com/Seb369888/poolsea-go/dao/trustednode"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/fakeclient"
+)
+
+const challengeActionsAbi = `[
+	{"type":"event","name":"ActionChallengeMade","anonymous":false,"inputs":[{"name":"nodeChallengedAddress","type":"address","indexed":true},{"name":"nodeChallengerAddress","type":"address","indexed":true},{"name":"time","type":"uint256","indexed":false}]},
+	{"type":"event","name":"ActionChallengeDecided","anonymous":false,"inputs":[{"name":"nodeChallengedAddress","type":"address","indexed":true},{"name":"nodeChallengeDeciderAddress","type":"address","indexed":true},{"name":"success","type":"bool","indexed":false},{"name":"time","type":"uint256","indexed":false}]},
+	{"type":"function","name":"actionChallengeDecide","stateMutability":"nonpayable","inputs":[{"name":"_nodeAddress","type":"address"}],"outputs":[]}
+]`
+
+var (
+	challengeActionsAddress = common.HexToAddress("0x2000000000000000000000000000000000000003")
+	challengedMember        = common.HexToAddress("0x4000000000000000000000000000000000000001")
+	challenger              = common.HexToAddress("0x4000000000000000000000000000000000000002")
+)
+
+// Create a fake network with the oracle DAO contracts used by the challenge monitor
+func newChallengeMonitorNetwork(t *testing.T, isChallenged *bool) (*rocketpool.RocketPool, *fakeclient.Client, *fakeclient.Storage) {
+
+	storageAddress := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	client := fakeclient.NewClient(1000)
+	storage, err := client.AddStorage(storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("deploy.block")), 0, big.NewInt(100))
+
+	contracts := []struct {
+		name    string
+		address common.Address
+		abi     string
+		handler fakeclient.Handler
+	}{
+		{"poolseaDAONodeTrustedUpgrade", common.HexToAddress("0x2000000000000000000000000000000000000001"), `[
+			{"type":"event","name":"ContractUpgraded","anonymous":false,"inputs":[{"name":"name","type":"bytes32","indexed":true},{"name":"oldAddress","type":"address","indexed":true},{"name":"newAddress","type":"address","indexed":true},{"name":"time","type":"uint256","indexed":false}]},
+			{"type":"event","name":"ContractAdded","anonymous":false,"inputs":[{"name":"name","type":"bytes32","indexed":true},{"name":"newAddress","type":"address","indexed":true},{"name":"time","type":"uint256","indexed":false}]}
+		]`, nil},
+		{"poolseaDAONodeTrusted", common.HexToAddress("0x2000000000000000000000000000000000000002"), `[
+			{"type":"function","name":"getMemberIsChallenged","stateMutability":"view","inputs":[{"name":"_nodeAddress","type":"address"}],"outputs":[{"name":"","type":"bool"}]}
+		]`, func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+			return []interface{}{*isChallenged}, nil
+		}},
+		{"poolseaDAONodeTrustedActions", challengeActionsAddress, challengeActionsAbi, nil},
+		{"poolseaDAONodeTrustedSettingsMembers", common.HexToAddress("0x2000000000000000000000000000000000000004"), `[
+			{"type":"function","name":"getChallengeWindow","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}
+		]`, func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+			return []interface{}{big.NewInt(3600)}, nil
+		}},
+	}
+	for _, contract := range contracts {
+		if err := storage.SetContract(contract.name, 0, contract.address, contract.abi); err != nil {
+			t.Fatal(err)
+		}
+		if contract.handler != nil {
+			if err := client.AddContract(contract.address, contract.abi, contract.handler); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	rp, err := rocketpool.NewRocketPool(client, storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rp, client, storage
+
+}
+
+// Create a log for a challenge event
+func challengeEventLog(t *testing.T, eventName string, block uint64, challenged, sender common.Address, values ...interface{}) types.Log {
+	parsed, err := abi.JSON(strings.NewReader(challengeActionsAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := parsed.Events[eventName]
+	data, err := event.Inputs.NonIndexed().Pack(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.Log{
+		Address:     challengeActionsAddress,
+		Topics:      []common.Hash{event.ID, common.BytesToHash(challenged.Bytes()), common.BytesToHash(sender.Bytes())},
+		Data:        data,
+		BlockNumber: block,
+		TxHash:      common.BigToHash(new(big.Int).SetUint64(block)),
+	}
+}
+
+// Set the time a member was challenged at from a block onwards
+func setChallengedTime(storage *fakeclient.Storage, block uint64, challengedTime int64) {
+	storage.Set(crypto.Keccak256Hash([]byte("dao.trustednodes."), []byte("member.challenged.time"), challengedMember.Bytes()), block, big.NewInt(challengedTime))
+}
+
+func TestGetChallengeEvents(t *testing.T) {
+
+	isChallenged := false
+	rp, client, _ := newChallengeMonitorNetwork(t, &isChallenged)
+	client.Logs = []types.Log{
+		challengeEventLog(t, "ActionChallengeMade", 200, challengedMember, challenger, big.NewInt(5000)),
+		challengeEventLog(t, "ActionChallengeMade", 250, challenger, challengedMember, big.NewInt(5500)), // Another member's challenge
+		challengeEventLog(t, "ActionChallengeDecided", 300, challengedMember, challengedMember, false, big.NewInt(6000)),
+	}
+
+	// Check the events are decoded
+	events, err := trustednodedao.GetChallengeEvents(rp, challengedMember, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Incorrect event count %d", len(events))
+	}
+	made, decided := events[0], events[1]
+	if made.Type != trustednodedao.ChallengeEventMade || made.Challenged != challengedMember || made.Sender != challenger || !made.Time.Equal(time.Unix(5000, 0)) || made.BlockNumber != 200 || made.Success {
+		t.Errorf("Incorrect challenge made event %+v", made)
+	}
+	if decided.Type != trustednodedao.ChallengeEventDecided || decided.Sender != challengedMember || !decided.Time.Equal(time.Unix(6000, 0)) || decided.Success {
+		t.Errorf("Incorrect challenge decided event %+v", decided)
+	}
+
+	// Events with missing topics are rejected
+	truncated := challengeEventLog(t, "ActionChallengeMade", 400, challengedMember, challenger, big.NewInt(7000))
+	truncated.Topics = truncated.Topics[:2]
+	client.Logs = append(client.Logs, truncated)
+	if _, err := trustednodedao.GetChallengeEvents(rp, challengedMember, nil, nil, nil, nil); err == nil {
+		t.Error("Event with missing topics decoded")
+	}
+
+}
+
+func TestGetChallengeStatus(t *testing.T) {
+
+	isChallenged := false
+	rp, _, storage := newChallengeMonitorNetwork(t, &isChallenged)
+	setChallengedTime(storage, 0, 9000)
+
+	// The challenged time is ignored while the member isn't challenged
+	status, err := trustednodedao.GetChallengeStatus(rp, challengedMember, time.Unix(10000, 0), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.IsChallenged || !status.ChallengedTime.IsZero() || !status.Deadline.IsZero() || status.ChallengeWindow != time.Hour {
+		t.Errorf("Incorrect unchallenged status %+v", status)
+	}
+
+	// The deadline is the challenged time plus the window, and nothing remains once it passes
+	isChallenged = true
+	tests := []struct {
+		now       int64
+		remaining time.Duration
+	}{
+		{10000, 2600 * time.Second},
+		{12599, time.Second},
+		{12600, 0},
+		{13000, 0},
+	}
+	for _, test := range tests {
+		status, err := trustednodedao.GetChallengeStatus(rp, challengedMember, time.Unix(test.now, 0), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !status.ChallengedTime.Equal(time.Unix(9000, 0)) || !status.Deadline.Equal(time.Unix(12600, 0)) || status.TimeRemaining != test.remaining {
+			t.Errorf("Incorrect status at %d: %+v", test.now, status)
+		}
+	}
+
+}
+
+func TestChallengeMonitor(t *testing.T) {
+
+	isChallenged := false
+	rp, client, storage := newChallengeMonitorNetwork(t, &isChallenged)
+	client.Logs = []types.Log{
+		challengeEventLog(t, "ActionChallengeMade", 400, challengedMember, challenger, big.NewInt(4000)),
+		challengeEventLog(t, "ActionChallengeDecided", 700, challengedMember, challengedMember, false, big.NewInt(7000)),
+	}
+
+	// The member's transactor fails the first time it signs
+	signErr := errors.New("signer unavailable")
+	transactor := &bind.TransactOpts{
+		From: challengedMember,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if signErr != nil {
+				return nil, signErr
+			}
+			return tx, nil
+		},
+	}
+	notifications := []trustednodedao.ChallengeNotification{}
+	monitor := trustednodedao.NewChallengeMonitor(challengedMember, big.NewInt(500), nil, transactor, func(notification trustednodedao.ChallengeNotification) {
+		notifications = append(notifications, notification)
+	})
+	check := func(latestBlock uint64, now int64) ([]trustednodedao.ChallengeEvent, error) {
+		client.LatestBlock = latestBlock
+		_, events, err := monitor.Check(rp, time.Unix(now, 0))
+		return events, err
+	}
+
+	// The first check scans from the starting block and reports decided challenges
+	events, err := check(1000, 8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != trustednodedao.ChallengeEventDecided {
+		t.Errorf("Incorrect events %+v", events)
+	}
+	if len(notifications) != 1 || notifications[0].Type != trustednodedao.ChallengeNotificationDecided || len(client.Transactions) != 0 {
+		t.Errorf("Incorrect notifications %+v", notifications)
+	}
+	if query := client.LogQueries[len(client.LogQueries)-1]; query.FromBlock.Uint64() != 500 || query.ToBlock.Uint64() != 1000 {
+		t.Errorf("Incorrect first scan from %s to %s", query.FromBlock, query.ToBlock)
+	}
+
+	// A failed response is reported and retried on the next check
+	isChallenged = true
+	setChallengedTime(storage, 1050, 9000)
+	notifications = notifications[:0]
+	if _, err := check(1100, 10000); err == nil {
+		t.Error("Failed response not returned")
+	}
+	if query := client.LogQueries[len(client.LogQueries)-1]; query.FromBlock.Uint64() != 1001 || query.ToBlock.Uint64() != 1100 {
+		t.Errorf("Incorrect second scan from %s to %s", query.FromBlock, query.ToBlock)
+	}
+	if len(notifications) != 2 || notifications[0].Type != trustednodedao.ChallengeNotificationChallenged || notifications[1].Type != trustednodedao.ChallengeNotificationResponseFailed || notifications[1].Error == nil {
+		t.Errorf("Incorrect failed response notifications %+v", notifications)
+	}
+	if notifications[0].Status.TimeRemaining != 2600*time.Second {
+		t.Errorf("Incorrect time remaining %s", notifications[0].Status.TimeRemaining)
+	}
+	signErr = nil
+	notifications = notifications[:0]
+	if _, err := check(1110, 10100); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 2 || notifications[1].Type != trustednodedao.ChallengeNotificationResponded || len(client.Transactions) != 1 {
+		t.Fatalf("Incorrect response notifications %+v", notifications)
+	}
+	if tx := client.Transactions[0]; *tx.To() != challengeActionsAddress || notifications[1].TxHash != tx.Hash() {
+		t.Errorf("Incorrect response transaction to %s", tx.To().Hex())
+	}
+
+	// The same challenge is only responded to once
+	notifications = notifications[:0]
+	if _, err := check(1120, 10200); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 0 || len(client.Transactions) != 1 {
+		t.Errorf("Challenge responded to more than once: %+v", notifications)
+	}
+
+	// A new challenge is responded to
+	setChallengedTime(storage, 1125, 11000)
+	if _, err := check(1130, 11100); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 2 || len(client.Transactions) != 2 {
+		t.Errorf("New challenge not responded to: %+v", notifications)
+	}
+
+}
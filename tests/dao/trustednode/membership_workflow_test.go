@@ -0,0 +1,72 @@
+package trustednode
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/dao"
+	trustednodedao "github.com/Seb369888/poolsea-go/dao/trustednode"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+)
+
+func TestEvaluateMembershipWorkflow(t *testing.T) {
+
+	now := time.Unix(1000000, 0)
+	bond := eth.EthToWei(1750)
+	newState := func(proposalState rptypes.ProposalState, executedAgo time.Duration, balance *big.Int, allowance *big.Int) trustednodedao.MembershipState {
+		state := trustednodedao.MembershipState{
+			Address:            common.HexToAddress("0x01"),
+			ActionTime:         4 * time.Hour,
+			MemberCount:        5,
+			MinimumMemberCount: 3,
+			RPLBond:            bond,
+			RPLBalance:         balance,
+			RPLAllowance:       allowance,
+			Proposal:           &dao.ProposalDetails{ID: 7, State: proposalState},
+		}
+		if proposalState == rptypes.Executed {
+			state.ProposalExecutedTime = now.Add(-executedAgo)
+		}
+		return state
+	}
+
+	cases := []struct {
+		name         string
+		workflowType trustednodedao.MembershipWorkflowType
+		state        trustednodedao.MembershipState
+		step         trustednodedao.MembershipStep
+		blocked      bool
+	}{
+		{"no proposal", trustednodedao.MembershipWorkflowJoin, trustednodedao.MembershipState{RPLBond: bond}, trustednodedao.MembershipStepPropose, false},
+		{"defeated", trustednodedao.MembershipWorkflowJoin, newState(rptypes.Defeated, 0, bond, bond), trustednodedao.MembershipStepPropose, false},
+		{"voting", trustednodedao.MembershipWorkflowJoin, newState(rptypes.Active, 0, bond, bond), trustednodedao.MembershipStepAwaitVotes, false},
+		{"passed", trustednodedao.MembershipWorkflowJoin, newState(rptypes.Succeeded, 0, bond, bond), trustednodedao.MembershipStepExecute, false},
+		{"needs approval", trustednodedao.MembershipWorkflowJoin, newState(rptypes.Executed, time.Hour, bond, big.NewInt(0)), trustednodedao.MembershipStepApproveRPL, false},
+		{"needs RPL", trustednodedao.MembershipWorkflowJoin, newState(rptypes.Executed, time.Hour, big.NewInt(0), bond), trustednodedao.MembershipStepJoin, true},
+		{"can join", trustednodedao.MembershipWorkflowJoin, newState(rptypes.Executed, time.Hour, bond, bond), trustednodedao.MembershipStepJoin, false},
+		{"window closed", trustednodedao.MembershipWorkflowJoin, newState(rptypes.Executed, 5*time.Hour, bond, bond), trustednodedao.MembershipStepPropose, false},
+		{"can leave", trustednodedao.MembershipWorkflowLeave, func() trustednodedao.MembershipState {
+			state := newState(rptypes.Executed, time.Hour, nil, nil)
+			state.IsMember = true
+			return state
+		}(), trustednodedao.MembershipStepLeave, false},
+		{"too few members", trustednodedao.MembershipWorkflowLeave, func() trustednodedao.MembershipState {
+			state := newState(rptypes.Executed, time.Hour, nil, nil)
+			state.IsMember = true
+			state.MemberCount = 3
+			return state
+		}(), trustednodedao.MembershipStepLeave, true},
+		{"left", trustednodedao.MembershipWorkflowLeave, newState(rptypes.Executed, time.Hour, nil, nil), trustednodedao.MembershipStepComplete, false},
+	}
+	for _, c := range cases {
+		workflow := trustednodedao.EvaluateMembershipWorkflow(c.workflowType, c.state, now)
+		if workflow.Step != c.step || workflow.Blocked != c.blocked {
+			t.Errorf("%s: expected step %s (blocked %t), got %s (blocked %t: %s)", c.name, c.step, c.blocked, workflow.Step, workflow.Blocked, workflow.BlockedReason)
+		}
+	}
+
+}
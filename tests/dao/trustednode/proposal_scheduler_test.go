@@ -0,0 +1,137 @@
+package trustednode
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Seb369888/poolsea-go/dao"
+	trustednodedao "github.com/Seb369888/poolsea-go/dao/trustednode"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/fakeclient"
+)
+
+func TestProposalSchedulerEvaluate(t *testing.T) {
+
+	now := time.Unix(1000000, 0)
+	unix := func(offset time.Duration) uint64 {
+		return uint64(now.Add(offset).Unix())
+	}
+
+	// Proposals in each state
+	proposals := []dao.ProposalDetails{
+		{ID: 1, State: rptypes.Pending, StartTime: unix(time.Hour), EndTime: unix(3 * time.Hour), ExpiryTime: unix(5 * time.Hour)},
+		{ID: 2, State: rptypes.Active, StartTime: unix(-time.Hour), EndTime: unix(2 * time.Hour), ExpiryTime: unix(4 * time.Hour)},
+		{ID: 3, State: rptypes.Active, StartTime: unix(-time.Hour), EndTime: unix(time.Hour), ExpiryTime: unix(3 * time.Hour), MemberVoted: true, VotesFor: 1, VotesRequired: 2},
+		{ID: 4, State: rptypes.Succeeded, EndTime: unix(-time.Hour), ExpiryTime: unix(10 * time.Minute), VotesFor: 3, VotesRequired: 2},
+		{ID: 5, State: rptypes.Succeeded, EndTime: unix(-time.Hour), ExpiryTime: unix(5 * time.Hour), VotesFor: 3, VotesRequired: 2},
+		{ID: 6, State: rptypes.Executed},
+		{ID: 7, State: rptypes.Expired},
+	}
+	scheduler := trustednodedao.NewProposalScheduler(trustednodedao.ProposalSchedulerConfig{
+		AutoExecute:      true,
+		ExecutionWarning: time.Hour,
+	}, nil)
+	schedule := scheduler.Evaluate(proposals, trustednodedao.ProposalTimingSettings{}, now)
+
+	// Check the schedule; due actions come first, with ties broken by deadline
+	expected := []struct {
+		id      uint64
+		action  trustednodedao.ProposalActionType
+		closing bool
+	}{
+		{4, trustednodedao.ProposalActionExecute, true},
+		{2, trustednodedao.ProposalActionVote, false},
+		{5, trustednodedao.ProposalActionExecute, false},
+		{3, trustednodedao.ProposalActionWaitForQuorum, false},
+		{1, trustednodedao.ProposalActionWaitForVoting, false},
+	}
+	if len(schedule.Proposals) != len(expected) {
+		t.Fatalf("Incorrect scheduled proposal count %d", len(schedule.Proposals))
+	}
+	for i, e := range expected {
+		scheduled := schedule.Proposals[i]
+		if scheduled.Proposal.ID != e.id || scheduled.NextAction != e.action || scheduled.ExecutionClosing != e.closing {
+			t.Errorf("Incorrect scheduled proposal %d: expected %d %s (closing %t), got %d %s (closing %t)", i, e.id, e.action, e.closing, scheduled.Proposal.ID, scheduled.NextAction, scheduled.ExecutionClosing)
+		}
+	}
+	if pending := schedule.Proposals[4]; !pending.NextActionTime.Equal(now.Add(time.Hour)) || !pending.Deadline.Equal(now.Add(3*time.Hour)) {
+		t.Errorf("Incorrect pending proposal times %s to %s", pending.NextActionTime, pending.Deadline)
+	}
+	if schedule.Proposals[3].QuorumReached || !schedule.Proposals[0].QuorumReached {
+		t.Error("Incorrect quorum status")
+	}
+
+}
+
+func TestProposalSchedulerExecute(t *testing.T) {
+
+	// Set up the proposals contract on a fake client
+	storageAddress := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	proposalsAddress := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	client := fakeclient.NewClient(1000)
+	storage, err := client.AddStorage(storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("deploy.block")), 0, big.NewInt(100))
+	if err := storage.SetContract("poolseaDAONodeTrustedProposals", 0, proposalsAddress, `[
+		{"type":"function","name":"vote","stateMutability":"nonpayable","inputs":[{"name":"_proposalID","type":"uint256"},{"name":"_support","type":"bool"}],"outputs":[]},
+		{"type":"function","name":"execute","stateMutability":"nonpayable","inputs":[{"name":"_proposalID","type":"uint256"}],"outputs":[]}
+	]`); err != nil {
+		t.Fatal(err)
+	}
+	rp, err := rocketpool.NewRocketPool(client, storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Vote on one proposal and execute another with a fixed nonce and gas limit
+	scheduler := trustednodedao.NewProposalScheduler(trustednodedao.ProposalSchedulerConfig{AutoExecute: true}, func(proposal dao.ProposalDetails) (bool, bool) {
+		return true, true
+	})
+	schedule := trustednodedao.ProposalSchedule{
+		Proposals: []trustednodedao.ScheduledProposal{
+			{Proposal: dao.ProposalDetails{ID: 1}, NextAction: trustednodedao.ProposalActionVote},
+			{Proposal: dao.ProposalDetails{ID: 2}, NextAction: trustednodedao.ProposalActionWaitForQuorum},
+			{Proposal: dao.ProposalDetails{ID: 3}, NextAction: trustednodedao.ProposalActionExecute},
+		},
+	}
+	opts := &bind.TransactOpts{
+		From:     common.HexToAddress("0x4000000000000000000000000000000000000001"),
+		Signer:   func(address common.Address, tx *types.Transaction) (*types.Transaction, error) { return tx, nil },
+		Nonce:    big.NewInt(9),
+		GasLimit: 21000,
+	}
+	transactions, err := scheduler.Execute(rp, schedule, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 2 || len(client.Transactions) != 2 {
+		t.Fatalf("Incorrect transaction count %d", len(client.Transactions))
+	}
+	if transactions[0].ProposalID != 1 || transactions[0].Action != trustednodedao.ProposalActionVote || transactions[1].ProposalID != 3 || transactions[1].Action != trustednodedao.ProposalActionExecute {
+		t.Errorf("Incorrect transactions %+v", transactions)
+	}
+
+	// Each transaction gets the next nonce and its own gas estimate
+	for i, tx := range client.Transactions {
+		if tx.Nonce() != uint64(9+i) {
+			t.Errorf("Incorrect nonce %d for transaction %d", tx.Nonce(), i)
+		}
+		if tx.Gas() != uint64(float64(client.GasEstimate)*rocketpool.GasLimitMultiplier) {
+			t.Errorf("Incorrect gas limit %d for transaction %d", tx.Gas(), i)
+		}
+	}
+	if opts.Nonce.Cmp(big.NewInt(9)) != 0 || opts.GasLimit != 21000 {
+		t.Error("The caller's transaction options were modified")
+	}
+
+}
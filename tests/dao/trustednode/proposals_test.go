@@ -298,12 +298,12 @@ func TestProposeUpgradeContract(t *testing.T) {
 	}
 
 	// Get & check updated contract details
-	if contractAddress, err := rp.GetAddress(proposalContractName); err != nil {
+	if contractAddress, err := rp.GetAddress(proposalContractName, nil); err != nil {
 		t.Error(err)
 	} else if !bytes.Equal(contractAddress.Bytes(), proposalContractAddress.Bytes()) {
 		t.Errorf("Incorrect updated contract address %s", contractAddress.Hex())
 	}
-	if contractAbi, err := rp.GetABI(proposalContractName); err != nil {
+	if contractAbi, err := rp.GetABI(proposalContractName, nil); err != nil {
 		t.Error(err)
 	} else if _, ok := contractAbi.Methods["foo"]; !ok {
 		t.Errorf("Incorrect updated contract ABI")
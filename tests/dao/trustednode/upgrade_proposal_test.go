@@ -0,0 +1,162 @@
+package trustednode
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	trustednodedao "github.com/Seb369888/poolsea-go/dao/trustednode"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/fakeclient"
+)
+
+func TestGetUpgradeReport(t *testing.T) {
+
+	// Register a contract with two methods, and deploy its replacement
+	storageAddress := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	currentAddress := common.HexToAddress("0x2000000000000000000000000000000000000001")
+	newAddress := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	client := fakeclient.NewClient(1000)
+	storage, err := client.AddStorage(storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("deploy.block")), 0, big.NewInt(100))
+	if err := storage.SetContract("poolseaNodeManager", 0, currentAddress, `[
+		{"type":"function","name":"getNodeCount","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+		{"type":"function","name":"getNodeExists","stateMutability":"view","inputs":[{"name":"_nodeAddress","type":"address"}],"outputs":[{"name":"","type":"bool"}]}
+	]`); err != nil {
+		t.Fatal(err)
+	}
+	client.SetCode(newAddress, []byte{0x60, 0x80})
+	rp, err := rocketpool.NewRocketPool(client, storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check an upgrade that removes a method and adds another
+	newAbi := `[
+		{"type":"function","name":"getNodeCount","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+		{"type":"function","name":"getNodeAt","stateMutability":"view","inputs":[{"name":"_index","type":"uint256"}],"outputs":[{"name":"","type":"address"}]}
+	]`
+	report, err := trustednodedao.GetUpgradeReport(rp, "upgradeContract", "poolseaNodeManager", newAbi, newAddress, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.IsValid() || report.CurrentAddress != currentAddress || report.CodeSize != 2 {
+		t.Errorf("Incorrect report %+v", report)
+	}
+	if len(report.AbiDiff.Methods) != 2 || len(report.BreakingChanges) != 1 || report.BreakingChanges[0].Name != "getNodeExists" || report.BreakingChanges[0].Kind != rocketpool.AbiChangeRemoved {
+		t.Errorf("Incorrect ABI changes %+v", report.AbiDiff.Methods)
+	}
+	if message := report.GetProposalMessage(); message != "upgradeContract poolseaNodeManager at "+newAddress.Hex()+" (methods: 1 added, 1 removed, 1 breaking)" {
+		t.Errorf("Incorrect proposal message %s", message)
+	}
+	if report.CallsKnown || !strings.Contains(report.String(), "no calls to poolseaNodeManager were recorded") {
+		t.Errorf("Unknown called methods weren't reported:\n%s", report.String())
+	}
+
+	// Breaking changes are limited to the called methods when they're known
+	report, err = trustednodedao.GetUpgradeReport(rp, "upgradeContract", "poolseaNodeManager", newAbi, newAddress, []string{"getNodeCount"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.CallsKnown || len(report.BreakingChanges) != 0 || strings.Contains(report.String(), "were recorded") {
+		t.Errorf("Incorrect report for called methods %+v", report)
+	}
+
+	// An invalid ABI is reported without listing every current method as removed
+	report, err = trustednodedao.GetUpgradeReport(rp, "upgradeABI", "poolseaNodeManager", "not json", common.Address{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.IsValid() || report.AbiDiff.HasChanges() || len(report.BreakingChanges) != 0 {
+		t.Errorf("Incorrect invalid ABI report %+v", report)
+	}
+
+}
+
+func TestProposeCheckedUpgrade(t *testing.T) {
+
+	// Register the proposal contracts and the contract to upgrade
+	storageAddress := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	proposalsAddress := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	daoProposalAddress := common.HexToAddress("0x1000000000000000000000000000000000000003")
+	currentAddress := common.HexToAddress("0x2000000000000000000000000000000000000001")
+	newAddress := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	proposalsAbi := `[
+		{"type":"function","name":"propose","stateMutability":"nonpayable","inputs":[{"name":"_proposalMessage","type":"string"},{"name":"_payload","type":"bytes"}],"outputs":[{"name":"","type":"uint256"}]},
+		{"type":"function","name":"proposalUpgrade","stateMutability":"nonpayable","inputs":[{"name":"_type","type":"string"},{"name":"_name","type":"string"},{"name":"_contractAbi","type":"string"},{"name":"_contractAddress","type":"address"}],"outputs":[]}
+	]`
+	daoProposalAbi := `[{"type":"function","name":"getTotal","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}]`
+	client := fakeclient.NewClient(1000)
+	storage, err := client.AddStorage(storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("deploy.block")), 0, big.NewInt(100))
+	if err := client.AddContract(daoProposalAddress, daoProposalAbi, func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+		return []interface{}{big.NewInt(4)}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, contract := range []struct {
+		name        string
+		address     common.Address
+		contractAbi string
+	}{
+		{"poolseaDAONodeTrustedProposals", proposalsAddress, proposalsAbi},
+		{"poolseaDAOProposal", daoProposalAddress, daoProposalAbi},
+		{"poolseaNodeManager", currentAddress, `[{"type":"function","name":"getNodeExists","stateMutability":"view","inputs":[{"name":"_nodeAddress","type":"address"}],"outputs":[{"name":"","type":"bool"}]}]`},
+	} {
+		if err := storage.SetContract(contract.name, 0, contract.address, contract.contractAbi); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.SetCode(newAddress, []byte{0x60, 0x80})
+	rp, err := rocketpool.NewRocketPool(client, storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Propose an upgrade that removes a method
+	newAbi := `[{"type":"function","name":"getNodeCount","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}]`
+	report, err := trustednodedao.GetUpgradeReport(rp, "upgradeContract", "poolseaNodeManager", newAbi, newAddress, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &bind.TransactOpts{
+		From:   common.HexToAddress("0x4000000000000000000000000000000000000001"),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) { return tx, nil },
+	}
+	proposalId, _, err := trustednodedao.ProposeCheckedUpgrade(rp, report, newAbi, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proposalId != 5 || len(client.Transactions) != 1 {
+		t.Fatalf("Incorrect proposal %d with %d transactions", proposalId, len(client.Transactions))
+	}
+
+	// The proposal message is the full report, including the individual ABI changes
+	parsedAbi, err := abi.JSON(strings.NewReader(proposalsAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := client.Transactions[0].Data()
+	args, err := parsedAbi.Methods["propose"].Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := args[0].(string)
+	if message != report.String() || !strings.Contains(message, "- getNodeExists(address) view returns (bool)") {
+		t.Errorf("Incorrect proposal message:\n%s", message)
+	}
+
+}
@@ -0,0 +1,103 @@
+package trustednode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/dao"
+	trustednodedao "github.com/Seb369888/poolsea-go/dao/trustednode"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+func TestBuildVotingReport(t *testing.T) {
+
+	day := 24 * time.Hour
+	now := time.Unix(100*86400, 0)
+	unix := func(offset time.Duration) uint64 {
+		return uint64(now.Add(offset).Unix())
+	}
+
+	active := common.HexToAddress("0x01")
+	idle := common.HexToAddress("0x02")
+	recent := common.HexToAddress("0x03")
+	members := []trustednodedao.MemberDetails{
+		{Address: active, ID: "active", JoinedTime: unix(-90 * day)},
+		{Address: idle, ID: "idle", JoinedTime: unix(-90 * day)},
+		{Address: recent, ID: "recent", JoinedTime: unix(-2 * day)},
+	}
+	proposals := []dao.ProposalDetails{
+		{ID: 1, CreatedTime: unix(-60 * day), StartTime: unix(-59 * day), State: rptypes.Executed},
+		{ID: 2, CreatedTime: unix(-30 * day), StartTime: unix(-29 * day), State: rptypes.Expired},
+		{ID: 3, CreatedTime: unix(-day), StartTime: unix(-day / 2), State: rptypes.Active},
+		{ID: 4, CreatedTime: unix(-20 * day), StartTime: unix(-19 * day), State: rptypes.Cancelled, IsCancelled: true},
+	}
+	votes := []dao.ProposalVote{
+		{ProposalID: 1, Voter: active, Supported: true, Time: now.Add(-58 * day)},
+		{ProposalID: 2, Voter: active, Supported: false, Time: now.Add(-28 * day)},
+		{ProposalID: 3, Voter: active, Supported: true, Time: now.Add(-day / 4)},
+		{ProposalID: 1, Voter: idle, Supported: true, Time: now.Add(-58 * day)},
+		{ProposalID: 99, Voter: idle, Supported: true, Time: now.Add(-day)}, // Another DAO's proposal
+	}
+	report := trustednodedao.BuildVotingReport(members, proposals, votes, time.Time{}, 14*day, now)
+
+	if len(report.Votes) != 4 {
+		t.Errorf("Incorrect vote count %d", len(report.Votes))
+	}
+	if len(report.ExpiredProposals) != 1 || report.ExpiredProposals[0].ID != 2 {
+		t.Errorf("Incorrect expired proposals %+v", report.ExpiredProposals)
+	}
+
+	activeStats := report.Members[0]
+	if activeStats.EligibleProposals != 3 || activeStats.VotesCast != 3 || activeStats.VotesFor != 2 || activeStats.VotesAgainst != 1 || activeStats.ParticipationRate != 1 || activeStats.Inactive {
+		t.Errorf("Incorrect active member stats %+v", activeStats)
+	}
+	idleStats := report.Members[1]
+	if idleStats.VotesCast != 1 || len(idleStats.MissedProposals) != 1 || idleStats.MissedProposals[0] != 2 || !idleStats.Inactive {
+		t.Errorf("Incorrect idle member stats %+v", idleStats)
+	}
+	recentStats := report.Members[2]
+	if recentStats.EligibleProposals != 1 || recentStats.VotesCast != 0 || len(recentStats.MissedProposals) != 0 || recentStats.Inactive {
+		t.Errorf("Incorrect recent member stats %+v", recentStats)
+	}
+	if len(report.InactiveMembers) != 1 || report.InactiveMembers[0] != idle {
+		t.Errorf("Incorrect inactive members %v", report.InactiveMembers)
+	}
+
+}
+
+func TestBuildVotingReportSince(t *testing.T) {
+
+	day := 24 * time.Hour
+	now := time.Unix(100*86400, 0)
+	unix := func(offset time.Duration) uint64 {
+		return uint64(now.Add(offset).Unix())
+	}
+
+	// The first proposal was created before the votes were loaded
+	member := common.HexToAddress("0x01")
+	members := []trustednodedao.MemberDetails{
+		{Address: member, ID: "member", JoinedTime: unix(-90 * day)},
+	}
+	proposals := []dao.ProposalDetails{
+		{ID: 1, CreatedTime: unix(-60 * day), StartTime: unix(-59 * day), State: rptypes.Expired},
+		{ID: 2, CreatedTime: unix(-30 * day), StartTime: unix(-29 * day), State: rptypes.Executed},
+	}
+	votes := []dao.ProposalVote{
+		{ProposalID: 1, Voter: member, Supported: true, Time: now.Add(-40 * day)},
+		{ProposalID: 2, Voter: member, Supported: true, Time: now.Add(-28 * day)},
+	}
+	since := now.Add(-45 * day)
+	report := trustednodedao.BuildVotingReport(members, proposals, votes, since, 14*day, now)
+
+	// Proposals from before the votes were loaded and their votes are left out
+	if !report.Since.Equal(since) || len(report.Votes) != 1 || report.Votes[0].ProposalID != 2 || len(report.ExpiredProposals) != 0 {
+		t.Errorf("Incorrect report %+v", report)
+	}
+	stats := report.Members[0]
+	if stats.EligibleProposals != 1 || stats.VotesCast != 1 || len(stats.MissedProposals) != 0 || stats.ParticipationRate != 1 {
+		t.Errorf("Incorrect member stats %+v", stats)
+	}
+
+}
@@ -0,0 +1,66 @@
+package minipool
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/minipool"
+)
+
+func TestAddressPrediction(t *testing.T) {
+
+	// Get the predictor
+	predictor, err := minipool.NewAddressPredictor(rp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the predictions against the factory
+	for _, salt := range []int64{0, 1, 2, 1000} {
+		if err := predictor.Verify(rp, nodeAccount.Address, big.NewInt(salt), nil); err != nil {
+			t.Error(err)
+		}
+	}
+
+}
+
+func TestVanitySaltSearch(t *testing.T) {
+
+	predictor := &minipool.AddressPredictor{
+		FactoryAddress:      common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		MinipoolBaseAddress: common.HexToAddress("0x2222222222222222222222222222222222222222"),
+	}
+	predictor.InitCodeHash = minipool.GetMinipoolInitCodeHash(predictor.MinipoolBaseAddress)
+	nodeAddress := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	// Search for a prefix with an odd number of nibbles
+	result, err := predictor.SearchVanitySalt(context.Background(), nodeAddress, "0xabc", big.NewInt(0), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(strings.ToLower(result.Address.Hex()), "0xabc") {
+		t.Errorf("Address %s does not have the requested prefix", result.Address.Hex())
+	}
+	if address := predictor.GetExpectedAddress(nodeAddress, result.Salt); address != result.Address {
+		t.Errorf("Salt %s gives address %s instead of %s", result.Salt.String(), address.Hex(), result.Address.Hex())
+	}
+
+	// Check batch predictions
+	addresses := predictor.GetExpectedAddresses(nodeAddress, result.Salt, 3)
+	if addresses[0].Address != result.Address {
+		t.Error("Incorrect first batch address")
+	}
+	if addresses[2].Salt.Cmp(new(big.Int).Add(result.Salt, big.NewInt(2))) != 0 {
+		t.Error("Incorrect batch salt")
+	}
+
+	// Invalid prefixes
+	if _, err := predictor.SearchVanitySalt(context.Background(), nodeAddress, "0xzz", big.NewInt(0), 1); err == nil {
+		t.Error("Invalid prefix was accepted")
+	}
+
+}
@@ -0,0 +1,169 @@
+package minipool
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/minipool"
+	"github.com/Seb369888/poolsea-go/types"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+)
+
+func TestPlanBondReductions(t *testing.T) {
+
+	// Settings
+	settings := minipool.LifecycleSettings{
+		BondReductionEnabled:      true,
+		BondReductionWindowStart:  12 * time.Hour,
+		BondReductionWindowLength: 2 * time.Hour,
+	}
+	now := time.Unix(1680000000, 0)
+
+	// A node with enough RPL for 40 ETH matched at 10% collateral
+	nodeState := minipool.BondReductionNodeState{
+		RplStake:                eth.EthToWei(400),
+		RplPrice:                eth.EthToWei(0.01),
+		MinimumPerMinipoolStake: eth.EthToWei(0.1),
+		EthMatched:              eth.EthToWei(24),
+		DepositAmounts:          []*big.Int{eth.EthToWei(16), eth.EthToWei(8)},
+	}
+
+	// Minipools
+	staking := func(address string) minipool.BondReductionMinipoolState {
+		return minipool.BondReductionMinipoolState{
+			Address: common.HexToAddress(address),
+			Lifecycle: minipool.LifecycleState{
+				Version:    3,
+				Status:     types.Staking,
+				StatusTime: now.Add(-24 * time.Hour),
+			},
+			NodeDepositBalance: eth.EthToWei(16),
+			ReduceBondValue:    big.NewInt(0),
+		}
+	}
+	first := staking("0x01")
+	second := staking("0x02")
+	inProgress := staking("0x03")
+	inProgress.Lifecycle.ReduceBondTime = now.Add(-13 * time.Hour)
+	inProgress.ReduceBondValue = eth.EthToWei(8)
+	legacy := staking("0x04")
+	legacy.Lifecycle.Version = 2
+
+	plan := minipool.PlanBondReductions(nodeState, []minipool.BondReductionMinipoolState{first, second, inProgress, legacy}, settings, eth.EthToWei(8), now)
+
+	// The reduction in progress and the first new reduction fit within the limit
+	if !plan.Minipools[2].Eligible || !plan.Minipools[2].InProgress {
+		t.Error("Reduction in progress was not planned")
+	}
+	if !plan.Minipools[0].Eligible {
+		t.Errorf("First minipool was not eligible: %s", plan.Minipools[0].IneligibleReason)
+	}
+	if plan.Minipools[1].Eligible {
+		t.Error("Second minipool was eligible despite the RPL limit")
+	}
+	if plan.Minipools[3].Eligible {
+		t.Error("Legacy minipool was eligible")
+	}
+	if plan.TotalDepositCredit.Cmp(eth.EthToWei(16)) != 0 {
+		t.Errorf("Incorrect deposit credit %s", plan.TotalDepositCredit.String())
+	}
+	if plan.RplShortfall.Cmp(eth.EthToWei(80)) != 0 {
+		t.Errorf("Incorrect RPL shortfall %s", plan.RplShortfall.String())
+	}
+
+	// Steps are ordered by time: finish the reduction in progress, begin the new one, then finish it in its window
+	if len(plan.Steps) != 3 {
+		t.Fatalf("Incorrect step count %d", len(plan.Steps))
+	}
+	if plan.Steps[0].Action != minipool.MinipoolActionReduceBondAmount || plan.Steps[0].MinipoolAddress != inProgress.Address {
+		t.Error("Incorrect first step")
+	}
+	if plan.Steps[1].Action != minipool.MinipoolActionBeginReduceBondAmount || !plan.Steps[1].NotBefore.Equal(now) {
+		t.Error("Incorrect second step")
+	}
+	if plan.Steps[2].Action != minipool.MinipoolActionReduceBondAmount || !plan.Steps[2].NotBefore.Equal(now.Add(settings.BondReductionWindowStart)) {
+		t.Error("Incorrect third step")
+	}
+
+}
+
+func TestPlanBondReductionsIneligible(t *testing.T) {
+
+	// Settings
+	settings := minipool.LifecycleSettings{
+		BondReductionEnabled:      true,
+		BondReductionWindowStart:  12 * time.Hour,
+		BondReductionWindowLength: 2 * time.Hour,
+	}
+	now := time.Unix(1680000000, 0)
+
+	// A node with enough RPL for 24 ETH matched at 10% collateral, which it has already matched
+	nodeState := minipool.BondReductionNodeState{
+		RplStake:                eth.EthToWei(240),
+		RplPrice:                eth.EthToWei(0.01),
+		MinimumPerMinipoolStake: eth.EthToWei(0.1),
+		EthMatched:              eth.EthToWei(24),
+		DepositAmounts:          []*big.Int{eth.EthToWei(16), eth.EthToWei(8)},
+	}
+
+	// Minipools
+	staking := func(address string) minipool.BondReductionMinipoolState {
+		return minipool.BondReductionMinipoolState{
+			Address: common.HexToAddress(address),
+			Lifecycle: minipool.LifecycleState{
+				Version:    3,
+				Status:     types.Staking,
+				StatusTime: now.Add(-24 * time.Hour),
+			},
+			NodeDepositBalance: eth.EthToWei(16),
+			ReduceBondValue:    big.NewInt(0),
+		}
+	}
+	inProgress := staking("0x01")
+	inProgress.Lifecycle.ReduceBondTime = now.Add(-13 * time.Hour)
+	inProgress.ReduceBondValue = eth.EthToWei(8)
+	cancelled := staking("0x02")
+	cancelled.Lifecycle.ReduceBondCancelled = true
+	cancelledInProgress := staking("0x03")
+	cancelledInProgress.Lifecycle.ReduceBondTime = now.Add(-13 * time.Hour)
+	cancelledInProgress.Lifecycle.ReduceBondCancelled = true
+	cancelledInProgress.ReduceBondValue = eth.EthToWei(8)
+
+	plan := minipool.PlanBondReductions(nodeState, []minipool.BondReductionMinipoolState{inProgress, cancelled, cancelledInProgress}, settings, eth.EthToWei(8), now)
+
+	// The reduction in progress is over the ETH matched limit, so it's kept in the plan but blocked
+	if plan.Minipools[0].Eligible || !plan.Minipools[0].InProgress || plan.Minipools[0].IneligibleReason == "" {
+		t.Errorf("Reduction in progress over the limit was not blocked: %+v", plan.Minipools[0])
+	}
+	if len(plan.Minipools[0].Steps) != 1 || plan.Minipools[0].Steps[0].Action != minipool.MinipoolActionReduceBondAmount {
+		t.Error("Blocked reduction in progress lost its step")
+	}
+	if len(plan.Steps) != 0 || plan.TotalDepositCredit.Sign() != 0 || plan.EthMatchedAfter.Cmp(eth.EthToWei(24)) != 0 {
+		t.Errorf("Blocked reduction was included in the node plan: %d steps, %s credit", len(plan.Steps), plan.TotalDepositCredit.String())
+	}
+	if plan.RplShortfall.Cmp(eth.EthToWei(80)) != 0 {
+		t.Errorf("Incorrect RPL shortfall %s", plan.RplShortfall.String())
+	}
+
+	// Cancelled minipools are ineligible, whether or not a reduction was started
+	for _, i := range []int{1, 2} {
+		if plan.Minipools[i].Eligible || plan.Minipools[i].InProgress || plan.Minipools[i].IneligibleReason == "" {
+			t.Errorf("Cancelled minipool %d was eligible: %+v", i, plan.Minipools[i])
+		}
+	}
+
+	// New bond amounts must be accepted by the node deposit contract
+	nodeState.RplStake = eth.EthToWei(1000)
+	plan = minipool.PlanBondReductions(nodeState, []minipool.BondReductionMinipoolState{staking("0x04")}, settings, eth.EthToWei(12), now)
+	if plan.Minipools[0].Eligible || plan.Minipools[0].IneligibleReason == "" {
+		t.Error("Minipool was eligible for an invalid bond amount")
+	}
+	plan = minipool.PlanBondReductions(nodeState, []minipool.BondReductionMinipoolState{staking("0x04")}, settings, eth.EthToWei(8), now)
+	if !plan.Minipools[0].Eligible {
+		t.Errorf("Minipool was not eligible for a valid bond amount: %s", plan.Minipools[0].IneligibleReason)
+	}
+
+}
@@ -0,0 +1,129 @@
+package minipool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Seb369888/poolsea-go/minipool"
+	"github.com/Seb369888/poolsea-go/types"
+)
+
+func TestMinipoolLifecycle(t *testing.T) {
+
+	// Settings
+	settings := minipool.LifecycleSettings{
+		ScrubPeriod:               12 * time.Hour,
+		PromotionScrubPeriod:      3 * 24 * time.Hour,
+		LaunchTimeout:             72 * time.Hour,
+		BondReductionEnabled:      true,
+		BondReductionWindowStart:  12 * time.Hour,
+		BondReductionWindowLength: 2 * time.Hour,
+	}
+	statusTime := time.Unix(1680000000, 0)
+
+	// Prelaunch minipool during the scrub period
+	state := minipool.LifecycleState{
+		Version:    3,
+		Status:     types.Prelaunch,
+		StatusTime: statusTime,
+	}
+	lifecycle := minipool.EvaluateLifecycle(state, settings, statusTime.Add(time.Hour))
+	if stake, _ := lifecycle.GetAction(minipool.MinipoolActionStake); stake.Allowed {
+		t.Error("Stake was allowed during the scrub period")
+	} else if expected := statusTime.Add(settings.ScrubPeriod + time.Second); !stake.UnlockTime.Equal(expected) {
+		t.Errorf("Incorrect stake unlock time: expected %s, got %s", expected, stake.UnlockTime)
+	}
+	if lifecycle.IsAllowed(minipool.MinipoolActionDissolve) {
+		t.Error("Dissolve was allowed before the launch timeout")
+	}
+	if lifecycle.IsAllowed(minipool.MinipoolActionPromote) {
+		t.Error("Promote was allowed for a non-vacant minipool")
+	}
+
+	// Prelaunch minipool after the scrub period and launch timeout
+	lifecycle = minipool.EvaluateLifecycle(state, settings, statusTime.Add(settings.LaunchTimeout))
+	if !lifecycle.IsAllowed(minipool.MinipoolActionStake) {
+		t.Error("Stake was not allowed after the scrub period")
+	}
+	if !lifecycle.IsAllowed(minipool.MinipoolActionDissolve) {
+		t.Error("Dissolve was not allowed after the launch timeout")
+	}
+
+	// Vacant minipool
+	state.IsVacant = true
+	lifecycle = minipool.EvaluateLifecycle(state, settings, statusTime.Add(settings.ScrubPeriod+time.Hour))
+	if lifecycle.IsAllowed(minipool.MinipoolActionStake) {
+		t.Error("Stake was allowed for a vacant minipool")
+	}
+	if lifecycle.IsAllowed(minipool.MinipoolActionPromote) {
+		t.Error("Promote was allowed during the promotion scrub period")
+	}
+	lifecycle = minipool.EvaluateLifecycle(state, settings, statusTime.Add(settings.PromotionScrubPeriod+time.Second))
+	if !lifecycle.IsAllowed(minipool.MinipoolActionPromote) {
+		t.Error("Promote was not allowed after the promotion scrub period")
+	}
+
+	// Staking minipool with a bond reduction in progress
+	reduceBondTime := statusTime.Add(24 * time.Hour)
+	state = minipool.LifecycleState{
+		Version:        3,
+		Status:         types.Staking,
+		StatusTime:     statusTime,
+		ReduceBondTime: reduceBondTime,
+	}
+	lifecycle = minipool.EvaluateLifecycle(state, settings, reduceBondTime.Add(time.Hour))
+	if reduce, _ := lifecycle.GetAction(minipool.MinipoolActionReduceBondAmount); reduce.Allowed {
+		t.Error("Reduce bond was allowed before the window started")
+	} else if expected := reduceBondTime.Add(settings.BondReductionWindowStart); !reduce.UnlockTime.Equal(expected) {
+		t.Errorf("Incorrect reduce bond unlock time: expected %s, got %s", expected, reduce.UnlockTime)
+	}
+	if lifecycle.IsAllowed(minipool.MinipoolActionBeginReduceBondAmount) {
+		t.Error("Begin reduce bond was allowed while a reduction was in progress")
+	}
+	if !lifecycle.IsAllowed(minipool.MinipoolActionDistributeBalance) {
+		t.Error("Distribute balance was not allowed for a staking minipool")
+	}
+	lifecycle = minipool.EvaluateLifecycle(state, settings, reduceBondTime.Add(settings.BondReductionWindowStart+time.Hour))
+	if !lifecycle.IsAllowed(minipool.MinipoolActionReduceBondAmount) {
+		t.Error("Reduce bond was not allowed during the window")
+	}
+	state.ReduceBondCancelled = true
+	lifecycle = minipool.EvaluateLifecycle(state, settings, reduceBondTime.Add(settings.BondReductionWindowStart+time.Hour))
+	if lifecycle.IsAllowed(minipool.MinipoolActionReduceBondAmount) {
+		t.Error("Reduce bond was allowed after being cancelled")
+	}
+	lifecycle = minipool.EvaluateLifecycle(state, settings, reduceBondTime.Add(settings.BondReductionWindowStart+settings.BondReductionWindowLength+time.Hour))
+	if begin, _ := lifecycle.GetAction(minipool.MinipoolActionBeginReduceBondAmount); begin.Allowed {
+		t.Error("Begin reduce bond was allowed after being cancelled")
+	} else if begin.BlockedReason != "bond reduction was cancelled by the Oracle DAO" {
+		t.Errorf("Incorrect begin reduce bond blocked reason %s", begin.BlockedReason)
+	}
+
+	// Finalisation
+	state = minipool.LifecycleState{
+		Version:    3,
+		Status:     types.Staking,
+		StatusTime: statusTime,
+	}
+	lifecycle = minipool.EvaluateLifecycle(state, settings, statusTime)
+	if lifecycle.IsAllowed(minipool.MinipoolActionFinalise) {
+		t.Error("Finalise was allowed before the user balance was distributed")
+	}
+	state.UserDistributed = true
+	lifecycle = minipool.EvaluateLifecycle(state, settings, statusTime)
+	if !lifecycle.IsAllowed(minipool.MinipoolActionFinalise) {
+		t.Error("Finalise was not allowed after the user balance was distributed")
+	}
+
+	// Dissolved minipool
+	state = minipool.LifecycleState{
+		Version:    3,
+		Status:     types.Dissolved,
+		StatusTime: statusTime,
+	}
+	lifecycle = minipool.EvaluateLifecycle(state, settings, statusTime)
+	if !lifecycle.IsAllowed(minipool.MinipoolActionClose) {
+		t.Error("Close was not allowed for a dissolved minipool")
+	}
+
+}
@@ -5,7 +5,7 @@ import (
 
 	trustednodesettings "github.com/Seb369888/poolsea-go/settings/trustednode"
 
-	"github.com/Seb369888/poolsea-go/minipool"
+	v110_minipool "github.com/Seb369888/poolsea-go/legacy/v1.1.0/minipool"
 	"github.com/Seb369888/poolsea-go/node"
 	"github.com/Seb369888/poolsea-go/utils/eth"
 
@@ -40,7 +40,7 @@ func TestQueueLengths(t *testing.T) {
 	}
 
 	// Get & check queue lengths
-	if queueLengths, err := minipool.GetQueueLengths(rp, nil); err != nil {
+	if queueLengths, err := v110_minipool.GetQueueLengths(rp, nil, nil); err != nil {
 		t.Error(err)
 	} else {
 		if queueLengths.Total != 0 {
@@ -63,7 +63,7 @@ func TestQueueLengths(t *testing.T) {
 	}
 
 	// Get & check queue lengths
-	if queueLengths, err := minipool.GetQueueLengths(rp, nil); err != nil {
+	if queueLengths, err := v110_minipool.GetQueueLengths(rp, nil, nil); err != nil {
 		t.Error(err)
 	} else {
 		if queueLengths.Total != 1 {
@@ -86,7 +86,7 @@ func TestQueueLengths(t *testing.T) {
 	}
 
 	// Get & check queue lengths
-	if queueLengths, err := minipool.GetQueueLengths(rp, nil); err != nil {
+	if queueLengths, err := v110_minipool.GetQueueLengths(rp, nil, nil); err != nil {
 		t.Error(err)
 	} else {
 		if queueLengths.Total != 2 {
@@ -107,7 +107,7 @@ func TestQueueLengths(t *testing.T) {
 	//if _, err := minipoolutils.CreateMinipool(t, rp, ownerAccount, trustedNodeAccount, eth.EthToWei(0), 3); err != nil { t.Fatal(err) }
 
 	// Get & check queue lengths
-	if queueLengths, err := minipool.GetQueueLengths(rp, nil); err != nil {
+	if queueLengths, err := v110_minipool.GetQueueLengths(rp, nil, nil); err != nil {
 		t.Error(err)
 	} else {
 		if queueLengths.Total != 2 {
@@ -152,7 +152,7 @@ func TestQueueCapacity(t *testing.T) {
 	}
 
 	// Get & check queue capacity
-	if queueCapacity, err := minipool.GetQueueCapacity(rp, nil); err != nil {
+	if queueCapacity, err := v110_minipool.GetQueueCapacity(rp, nil, nil); err != nil {
 		t.Error(err)
 	} else {
 		if queueCapacity.Total.Cmp(eth.EthToWei(0)) != 0 {
@@ -171,7 +171,7 @@ func TestQueueCapacity(t *testing.T) {
 	   if _, err := minipoolutils.CreateMinipool(t, rp, ownerAccount, trustedNodeAccount, eth.EthToWei(0)); err != nil { t.Fatal(err) }
 
 	   // Get & check queue capacity
-	   if queueCapacity, err := minipool.GetQueueCapacity(rp, nil); err != nil {
+	   if queueCapacity, err := v110_minipool.GetQueueCapacity(rp, nil, nil); err != nil {
 	       t.Error(err)
 	   } else {
 	       if queueCapacity.Total.Cmp(eth.EthToWei(32)) != 0 {
@@ -192,7 +192,7 @@ func TestQueueCapacity(t *testing.T) {
 	}
 
 	// Get & check queue capacity
-	if queueCapacity, err := minipool.GetQueueCapacity(rp, nil); err != nil {
+	if queueCapacity, err := v110_minipool.GetQueueCapacity(rp, nil, nil); err != nil {
 		t.Error(err)
 	} else {
 		if queueCapacity.Total.Cmp(eth.EthToWei(16)) != 0 {
@@ -212,7 +212,7 @@ func TestQueueCapacity(t *testing.T) {
 	}
 
 	// Get & check queue capacity
-	if queueCapacity, err := minipool.GetQueueCapacity(rp, nil); err != nil {
+	if queueCapacity, err := v110_minipool.GetQueueCapacity(rp, nil, nil); err != nil {
 		t.Error(err)
 	} else {
 		if queueCapacity.Total.Cmp(eth.EthToWei(32)) != 0 {
@@ -0,0 +1,157 @@
+package minipool
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Seb369888/poolsea-go/minipool"
+	"github.com/Seb369888/poolsea-go/node"
+	"github.com/Seb369888/poolsea-go/types"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+	"github.com/Seb369888/poolsea-go/utils/state"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/evm"
+	minipoolutils "github.com/Seb369888/poolsea-go/tests/testutils/minipool"
+)
+
+func TestOfflineMinipoolShares(t *testing.T) {
+
+	// State snapshotting
+	if err := evm.TakeSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := evm.RevertSnapshot(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Register node
+	if _, err := node.RegisterNode(rp, "Australia/Brisbane", nodeAccount.GetTransactor()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create minipool
+	mp, err := minipoolutils.CreateMinipool(t, rp, ownerAccount, nodeAccount, eth.EthToWei(16), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get the share details
+	status, err := mp.GetStatus(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	depositType, err := mp.GetDepositType(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeFee, err := mp.GetNodeFeeRaw(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeDepositBalance, err := mp.GetNodeDepositBalance(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userDepositBalance, err := mp.GetUserDepositBalance(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	penaltyRate, err := minipool.GetMinipoolPenaltyRate(rp, mp.GetAddress(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	details := minipool.ShareDetails{
+		Version:            mp.GetVersion(),
+		Status:             status,
+		DepositType:        depositType,
+		NodeFee:            nodeFee,
+		NodeDepositBalance: nodeDepositBalance,
+		UserDepositBalance: userDepositBalance,
+		PenaltyRate:        penaltyRate,
+	}
+
+	// Compare the offline shares with the contract for balances covering rewards, slashing and full withdrawals
+	balances := []float64{0, 0.05, 1, 7.999, 8, 12, 16, 20, 24, 31.5, 32, 32.1, 33, 40}
+	for _, balanceEth := range balances {
+		balance := eth.EthToWei(balanceEth)
+		expectedNodeShare, err := mp.CalculateNodeShare(balance, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedUserShare, err := mp.CalculateUserShare(balance, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nodeShare := minipool.CalculateNodeShareOffline(details, balance); nodeShare.Cmp(expectedNodeShare) != 0 {
+			t.Errorf("Incorrect node share for balance %s: expected %s, got %s", balance.String(), expectedNodeShare.String(), nodeShare.String())
+		}
+		if userShare := minipool.CalculateUserShareOffline(details, balance); userShare.Cmp(expectedUserShare) != 0 {
+			t.Errorf("Incorrect user share for balance %s: expected %s, got %s", balance.String(), expectedUserShare.String(), userShare.String())
+		}
+	}
+
+}
+
+func TestOfflineMinipoolSharesWithPenalties(t *testing.T) {
+
+	// Amounts in milliether, so the expected shares are exact
+	milli := func(amount int64) *big.Int {
+		return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e15))
+	}
+
+	// The expected shares follow calculateNodeShare() on the v2 and v3 delegates
+	halfDeposit := minipool.ShareDetails{Version: 2, Status: types.Staking, DepositType: types.Half, NodeFee: milli(150), NodeDepositBalance: milli(16000), UserDepositBalance: milli(16000)}
+	emptyDeposit := minipool.ShareDetails{Version: 2, Status: types.Staking, DepositType: types.Empty, NodeFee: milli(150), NodeDepositBalance: milli(0), UserDepositBalance: milli(32000)}
+	leb8 := minipool.ShareDetails{Version: 3, Status: types.Staking, DepositType: types.Variable, NodeFee: milli(140), NodeDepositBalance: milli(8000), UserDepositBalance: milli(24000)}
+	for _, test := range []struct {
+		name        string
+		details     minipool.ShareDetails
+		penaltyRate int64
+		balance     int64
+		nodeShare   int64
+	}{
+		// v2: half of the 2 ETH of rewards plus 15% commission on the other half goes to the node, then the penalty
+		{"v2 half deposit", halfDeposit, 0, 34000, 17150},
+		{"v2 half deposit, 10% penalty", halfDeposit, 100, 34000, 15435},
+		{"v2 empty deposit, 10% penalty", emptyDeposit, 100, 34000, 135},
+		{"v2 slashed, 10% penalty", halfDeposit, 100, 15000, 0},
+		{"v2 full penalty", halfDeposit, 1000, 34000, 0},
+
+		// v3: a quarter of the rewards plus 14% commission on the rest goes to the node; penalties only apply to full withdrawals
+		{"v3 rewards, 20% penalty", leb8, 200, 1000, 355},
+		{"v3 full withdrawal, 20% penalty", leb8, 200, 33000, 6684},
+		{"v3 partial slashing, 50% penalty", leb8, 500, 30000, 3000},
+		{"v3 slashed below the user capital, 50% penalty", leb8, 500, 20000, 0},
+	} {
+		details := test.details
+		details.PenaltyRate = milli(test.penaltyRate)
+		balance := milli(test.balance)
+		nodeShare := minipool.CalculateNodeShareOffline(details, balance)
+		if nodeShare.Cmp(milli(test.nodeShare)) != 0 {
+			t.Errorf("%s: incorrect node share %s", test.name, nodeShare.String())
+		}
+		if userShare := minipool.CalculateUserShareOffline(details, balance); new(big.Int).Add(userShare, nodeShare).Cmp(balance) != 0 {
+			t.Errorf("%s: user share %s doesn't make up the rest of the balance", test.name, userShare.String())
+		}
+	}
+
+	// Stored penalty rates above the network maximum are capped, as getPenaltyRate() does
+	mpDetails := state.NativeMinipoolDetails{
+		Version:            3,
+		Status:             types.Staking,
+		DepositType:        types.Variable,
+		NodeFee:            milli(140),
+		NodeDepositBalance: milli(8000),
+		UserDepositBalance: milli(24000),
+		PenaltyRate:        milli(800),
+	}
+	if nodeShare := minipool.CalculateNodeShareOffline(mpDetails.GetShareDetails(milli(500)), milli(30000)); nodeShare.Cmp(milli(3000)) != 0 {
+		t.Errorf("Incorrect node share with a capped penalty rate %s", nodeShare.String())
+	}
+	if rate := minipool.CapPenaltyRate(milli(300), milli(500)); rate.Cmp(milli(300)) != 0 {
+		t.Errorf("Incorrect penalty rate under the maximum %s", rate.String())
+	}
+
+}
@@ -0,0 +1,96 @@
+package minipool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/minipool"
+	"github.com/Seb369888/poolsea-go/types"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+)
+
+func TestCheckSoloMigration(t *testing.T) {
+
+	minipoolAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	launchBalance := eth.EthToWei(32)
+	validator := minipool.MigrationValidator{
+		WithdrawalCredentials: common.HexToHash("0x00aa"),
+		Balance:               32123456789,
+		IsActive:              true,
+	}
+
+	// Validator with BLS credentials
+	validation := minipool.CheckSoloMigration(validator, minipoolAddress, launchBalance)
+	if !validation.CanMigrate {
+		t.Errorf("Validator could not migrate: %v", validation.Errors)
+	}
+	if !validation.RequiresCredentialsChange {
+		t.Error("BLS credentials change was not required")
+	}
+	if validation.CurrentBalance.String() != "32123456789000000000" {
+		t.Errorf("Incorrect current balance %s", validation.CurrentBalance.String())
+	}
+
+	// Validator with credentials for another address
+	validator.WithdrawalCredentials = common.HexToHash("0x0100000000000000000000002222222222222222222222222222222222222222")
+	if validation := minipool.CheckSoloMigration(validator, minipoolAddress, launchBalance); validation.CanMigrate {
+		t.Error("Validator with foreign credentials could migrate")
+	}
+
+	// Validator with low balance and credentials for the minipool
+	validator.WithdrawalCredentials = minipool.CalculateMinipoolWithdrawalCredentials(minipoolAddress)
+	validator.Balance = 31e9
+	validation = minipool.CheckSoloMigration(validator, minipoolAddress, launchBalance)
+	if validation.CanMigrate || len(validation.Errors) != 1 {
+		t.Errorf("Incorrect errors for a low balance: %v", validation.Errors)
+	}
+
+}
+
+func TestEvaluateSoloMigration(t *testing.T) {
+
+	minipoolAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	statusTime := time.Unix(1680000000, 0)
+	settings := minipool.LifecycleSettings{
+		PromotionScrubPeriod: 3 * 24 * time.Hour,
+	}
+	state := minipool.LifecycleState{
+		Version:    3,
+		Status:     types.Prelaunch,
+		StatusTime: statusTime,
+		IsVacant:   true,
+	}
+	validator := &minipool.MigrationValidator{
+		WithdrawalCredentials: common.HexToHash("0x00aa"),
+		Balance:               32e9,
+		IsActive:              true,
+	}
+
+	// During the scrub period, with BLS credentials
+	lifecycle := minipool.EvaluateLifecycle(state, settings, statusTime.Add(time.Hour))
+	status := minipool.EvaluateSoloMigration(minipoolAddress, lifecycle, eth.EthToWei(32), validator)
+	if status.Stage != minipool.SoloMigrationStageScrubbing {
+		t.Errorf("Incorrect stage %s", status.Stage)
+	}
+	if len(status.ScrubRisks) != 1 {
+		t.Errorf("Incorrect scrub risks: %v", status.ScrubRisks)
+	}
+
+	// After the scrub period
+	validator.WithdrawalCredentials = minipool.CalculateMinipoolWithdrawalCredentials(minipoolAddress)
+	lifecycle = minipool.EvaluateLifecycle(state, settings, statusTime.Add(settings.PromotionScrubPeriod+time.Second))
+	status = minipool.EvaluateSoloMigration(minipoolAddress, lifecycle, eth.EthToWei(32), validator)
+	if status.Stage != minipool.SoloMigrationStageReady || len(status.ScrubRisks) != 0 {
+		t.Errorf("Incorrect stage %s with scrub risks %v", status.Stage, status.ScrubRisks)
+	}
+
+	// Scrubbed
+	state.Status = types.Dissolved
+	lifecycle = minipool.EvaluateLifecycle(state, settings, statusTime.Add(time.Hour))
+	if status := minipool.EvaluateSoloMigration(minipoolAddress, lifecycle, eth.EthToWei(32), validator); status.Stage != minipool.SoloMigrationStageScrubbed {
+		t.Errorf("Incorrect stage %s", status.Stage)
+	}
+
+}
@@ -0,0 +1,76 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/Seb369888/poolsea-go/node"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+)
+
+func TestCollateralPlanning(t *testing.T) {
+
+	// A node with one 8 ETH minipool and 300 RPL at 0.01 ETH each
+	state := node.CollateralState{
+		RplStake:                eth.EthToWei(300),
+		RplPrice:                eth.EthToWei(0.01),
+		MinimumPerMinipoolStake: eth.EthToWei(0.1),
+		MaximumPerMinipoolStake: eth.EthToWei(1.5),
+		EthMatched:              eth.EthToWei(24),
+		EthProvided:             eth.EthToWei(8),
+		LaunchBalance:           eth.EthToWei(32),
+	}
+
+	// Check the contract-equivalent values
+	if limit := state.GetEthMatchedLimit(); limit.Cmp(eth.EthToWei(30)) != 0 {
+		t.Errorf("Incorrect ETH matched limit %s", limit.String())
+	}
+	if minimum := state.GetMinimumRplStake(); minimum.Cmp(eth.EthToWei(240)) != 0 {
+		t.Errorf("Incorrect minimum RPL stake %s", minimum.String())
+	}
+	if maximum := state.GetMaximumRplStake(); maximum.Cmp(eth.EthToWei(1200)) != 0 {
+		t.Errorf("Incorrect maximum RPL stake %s", maximum.String())
+	}
+	if effective := state.GetEffectiveRplStake(); effective.Cmp(state.RplStake) != 0 {
+		t.Errorf("Incorrect effective RPL stake %s", effective.String())
+	}
+	if ratio := state.GetCollateralRatio(); ratio.Cmp(eth.EthToWei(0.125)) != 0 {
+		t.Errorf("Incorrect collateral ratio %s", ratio.String())
+	}
+	if count := state.GetMaxNewMinipools(eth.EthToWei(8)); count != 0 {
+		t.Errorf("Incorrect max new minipools %d", count)
+	}
+
+	// Plan two more 8 ETH minipools
+	plan := state.PlanMinipools(2, eth.EthToWei(8))
+	if plan.RequiredRplStake.Cmp(eth.EthToWei(720)) != 0 {
+		t.Errorf("Incorrect required RPL stake %s", plan.RequiredRplStake.String())
+	}
+	if plan.AdditionalRplStake.Cmp(eth.EthToWei(420)) != 0 {
+		t.Errorf("Incorrect additional RPL stake %s", plan.AdditionalRplStake.String())
+	}
+	if plan.EffectiveRplStakeAfter.Sign() != 0 {
+		t.Error("Effective stake was not zero while undercollateralized")
+	}
+	if count := state.WithRplStake(plan.RequiredRplStake).GetMaxNewMinipools(eth.EthToWei(8)); count != 2 {
+		t.Errorf("Required stake supports %d minipools instead of 2", count)
+	}
+
+	// Reducing a 16 ETH bond to 8 ETH
+	reduced := state.WithBondReduction(eth.EthToWei(16), eth.EthToWei(8))
+	if reduced.EthMatched.Cmp(eth.EthToWei(32)) != 0 || reduced.EthProvided.Cmp(eth.EthToWei(0)) != 0 {
+		t.Errorf("Incorrect bond reduction state: matched %s, provided %s", reduced.EthMatched.String(), reduced.EthProvided.String())
+	}
+
+	// Required stake rounds up so the deposit passes the limit
+	state.RplPrice = eth.EthToWei(0.03)
+	required := node.GetRequiredRplStake(eth.EthToWei(1), state.RplPrice, state.MinimumPerMinipoolStake)
+	if node.GetEthMatchedLimit(required, state.RplPrice, state.MinimumPerMinipoolStake).Cmp(eth.EthToWei(1)) < 0 {
+		t.Error("Required stake does not cover the ETH matched")
+	}
+
+	// Cap price: 8 ETH * 150% / 300 RPL
+	if price := state.GetCapPrice(); price.Cmp(eth.EthToWei(0.04)) != 0 {
+		t.Errorf("Incorrect cap price %s", price.String())
+	}
+
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/Seb369888/poolsea-go/deposit"
+	v110_node "github.com/Seb369888/poolsea-go/legacy/v1.1.0/node"
 	"github.com/Seb369888/poolsea-go/minipool"
 	"github.com/Seb369888/poolsea-go/node"
 	"github.com/Seb369888/poolsea-go/settings/protocol"
@@ -50,7 +51,7 @@ func TestStakeRPL(t *testing.T) {
 	}
 
 	// Approve RPL transfer for staking
-	rocketNodeStakingAddress, err := rp.GetAddress("poolseaNodeStaking")
+	rocketNodeStakingAddress, err := rp.GetAddress("poolseaNodeStaking", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -64,7 +65,7 @@ func TestStakeRPL(t *testing.T) {
 	} else if totalRplStake.Cmp(big.NewInt(0)) != 0 {
 		t.Errorf("Incorrect initial total RPL stake %s", totalRplStake.String())
 	}
-	if totalEffectiveRplStake, err := node.GetTotalEffectiveRPLStake(rp, nil); err != nil {
+	if totalEffectiveRplStake, err := v110_node.GetTotalEffectiveRPLStake(rp, nil, nil); err != nil {
 		t.Error(err)
 	} else if totalEffectiveRplStake.Cmp(big.NewInt(0)) != 0 {
 		t.Errorf("Incorrect initial total effective RPL stake %s", totalEffectiveRplStake.String())
@@ -89,7 +90,7 @@ func TestStakeRPL(t *testing.T) {
 	} else if nodeRplStakedTime != 0 {
 		t.Errorf("Incorrect initial node RPL staked time %d", nodeRplStakedTime)
 	}
-	if nodeMinipoolLimit, err := node.GetNodeMinipoolLimit(rp, nodeAccount.Address, nil); err != nil {
+	if nodeMinipoolLimit, err := v110_node.GetNodeMinipoolLimit(rp, nodeAccount.Address, nil, nil); err != nil {
 		t.Error(err)
 	} else if nodeMinipoolLimit != 0 {
 		t.Errorf("Incorrect initial node minipool limit %d", nodeMinipoolLimit)
@@ -106,7 +107,7 @@ func TestStakeRPL(t *testing.T) {
 	} else if totalRplStake.Cmp(rplAmount) != 0 {
 		t.Errorf("Incorrect updated total RPL stake 1 %s", totalRplStake.String())
 	}
-	if totalEffectiveRplStake, err := node.GetTotalEffectiveRPLStake(rp, nil); err != nil {
+	if totalEffectiveRplStake, err := v110_node.GetTotalEffectiveRPLStake(rp, nil, nil); err != nil {
 		t.Error(err)
 	} else if totalEffectiveRplStake.Cmp(big.NewInt(0)) != 0 {
 		t.Errorf("Incorrect updated total effective RPL stake 1 %s", totalEffectiveRplStake.String())
@@ -131,7 +132,7 @@ func TestStakeRPL(t *testing.T) {
 	} else if nodeRplStakedTime == 0 {
 		t.Errorf("Incorrect updated node RPL staked time 1 %d", nodeRplStakedTime)
 	}
-	if nodeMinipoolLimit, err := node.GetNodeMinipoolLimit(rp, nodeAccount.Address, nil); err != nil {
+	if nodeMinipoolLimit, err := v110_node.GetNodeMinipoolLimit(rp, nodeAccount.Address, nil, nil); err != nil {
 		t.Error(err)
 	} else if nodeMinipoolLimit != 2 {
 		t.Errorf("Incorrect updated node minipool limit 1 %d", nodeMinipoolLimit)
@@ -142,7 +143,7 @@ func TestStakeRPL(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	mp, err := minipool.NewMinipool(rp, minipoolAddress)
+	mp, err := minipool.NewMinipool(rp, minipoolAddress, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -170,7 +171,7 @@ func TestStakeRPL(t *testing.T) {
 	}
 
 	// Check updated staking details
-	if totalEffectiveRplStake, err := node.GetTotalEffectiveRPLStake(rp, nil); err != nil {
+	if totalEffectiveRplStake, err := v110_node.GetTotalEffectiveRPLStake(rp, nil, nil); err != nil {
 		t.Error(err)
 	} else if totalEffectiveRplStake.Cmp(rplAmount) != 0 {
 		t.Errorf("Incorrect updated total effective RPL stake 2 %s", totalEffectiveRplStake.String())
@@ -0,0 +1,72 @@
+package rewards
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Seb369888/poolsea-go/rewards"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+)
+
+func TestProjectClaimerEmissions(t *testing.T) {
+
+	// 10% inflation per interval, two inflation intervals per claim interval
+	supply := eth.EthToWei(1000)
+	rate := eth.EthToWei(1.1)
+	percs := []*big.Int{eth.EthToWei(0.7), eth.EthToWei(0.2), eth.EthToWei(0.05)}
+	projections := rewards.ProjectClaimerEmissions(supply, rate, 2, percs, 2)
+	if len(projections) != 2 {
+		t.Fatalf("Incorrect projection count %d", len(projections))
+	}
+
+	// The first claim interval mints 1000 * 1.1^2 - 1000 = 210 RPL
+	first := projections[0]
+	if first.Emission.Cmp(eth.EthToWei(210)) != 0 {
+		t.Errorf("Incorrect first emission %s", first.Emission)
+	}
+	for i, expected := range []float64{147, 42, 10.5} {
+		if first.ClaimerEmissions[i].Cmp(eth.EthToWei(expected)) != 0 {
+			t.Errorf("Incorrect claimer %d emission %s", i, first.ClaimerEmissions[i])
+		}
+	}
+	if first.Unallocated.Cmp(eth.EthToWei(10.5)) != 0 {
+		t.Errorf("Incorrect unallocated emission %s", first.Unallocated)
+	}
+
+	// The second compounds on the first
+	second := projections[1]
+	if second.TotalSupply.Cmp(eth.EthToWei(1210)) != 0 || second.Emission.Cmp(eth.EthToWei(254.1)) != 0 {
+		t.Errorf("Incorrect second projection: supply %s, emission %s", second.TotalSupply, second.Emission)
+	}
+
+}
+
+func TestProjectClaimerEmissionsRounding(t *testing.T) {
+
+	// The rate is compounded over the claim interval before it's applied to the supply, as the RPL token does
+	supply, _ := new(big.Int).SetString("1234567890123456789012", 10)
+	rate, _ := new(big.Int).SetString("1000133680617119386", 10)
+	percs := []*big.Int{eth.EthToWei(0.7), eth.EthToWei(0.2), eth.EthToWei(0.05)}
+	projections := rewards.ProjectClaimerEmissions(supply, rate, 3, percs, 2)
+	if len(projections) != 2 {
+		t.Fatalf("Incorrect projection count %d", len(projections))
+	}
+
+	// Check the emissions to the wei
+	first, second := projections[0], projections[1]
+	if first.Emission.String() != "495179582295578780" {
+		t.Errorf("Incorrect first emission %s", first.Emission)
+	}
+	for i, expected := range []string{"346625707606905146", "99035916459115756", "24758979114778939"} {
+		if first.ClaimerEmissions[i].String() != expected {
+			t.Errorf("Incorrect claimer %d emission %s", i, first.ClaimerEmissions[i])
+		}
+	}
+	if first.Unallocated.String() != "24758979114778939" {
+		t.Errorf("Incorrect unallocated emission %s", first.Unallocated)
+	}
+	if second.TotalSupply.String() != "1235063069705752367792" || second.Emission.String() != "495378196580531472" {
+		t.Errorf("Incorrect second projection: supply %s, emission %s", second.TotalSupply, second.Emission)
+	}
+
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/Seb369888/poolsea-go/deposit"
+	v100_rewards "github.com/Seb369888/poolsea-go/legacy/v1.0.0/rewards"
 	"github.com/Seb369888/poolsea-go/node"
 	"github.com/Seb369888/poolsea-go/rewards"
 	"github.com/Seb369888/poolsea-go/settings/protocol"
@@ -44,14 +45,14 @@ func TestNodeRewards(t *testing.T) {
 	}
 
 	// Get & check node claims enabled status
-	if claimsEnabled, err := rewards.GetNodeClaimsEnabled(rp, nil); err != nil {
+	if claimsEnabled, err := v100_rewards.GetNodeClaimsEnabled(rp, nil, nil); err != nil {
 		t.Error(err)
 	} else if !claimsEnabled {
 		t.Error("Incorrect node claims enabled status")
 	}
 
 	// Get & check initial node claim possible status
-	if nodeClaimPossible, err := rewards.GetNodeClaimPossible(rp, nodeAccount.Address, nil); err != nil {
+	if nodeClaimPossible, err := v100_rewards.GetNodeClaimPossible(rp, nodeAccount.Address, nil, nil); err != nil {
 		t.Error(err)
 	} else if nodeClaimPossible {
 		t.Error("Incorrect initial node claim possible status")
@@ -63,14 +64,14 @@ func TestNodeRewards(t *testing.T) {
 	}
 
 	// Get & check updated node claim possible status
-	if nodeClaimPossible, err := rewards.GetNodeClaimPossible(rp, nodeAccount.Address, nil); err != nil {
+	if nodeClaimPossible, err := v100_rewards.GetNodeClaimPossible(rp, nodeAccount.Address, nil, nil); err != nil {
 		t.Error(err)
 	} else if !nodeClaimPossible {
 		t.Error("Incorrect updated node claim possible status")
 	}
 
 	// Get & check initial node claim rewards percent
-	if rewardsPerc, err := rewards.GetNodeClaimRewardsPerc(rp, nodeAccount.Address, nil); err != nil {
+	if rewardsPerc, err := v100_rewards.GetNodeClaimRewardsPerc(rp, nodeAccount.Address, nil, nil); err != nil {
 		t.Error(err)
 	} else if rewardsPerc != 0 {
 		t.Errorf("Incorrect initial node claim rewards perc %f", rewardsPerc)
@@ -105,24 +106,24 @@ func TestNodeRewards(t *testing.T) {
 	}
 
 	// Get & check updated node claim rewards percent
-	if rewardsPerc, err := rewards.GetNodeClaimRewardsPerc(rp, nodeAccount.Address, nil); err != nil {
+	if rewardsPerc, err := v100_rewards.GetNodeClaimRewardsPerc(rp, nodeAccount.Address, nil, nil); err != nil {
 		t.Error(err)
 	} else if rewardsPerc != 1 {
 		t.Errorf("Incorrect updated node claim rewards perc %f", rewardsPerc)
 	}
 
 	// Get & check initial node claim rewards amount
-	if rewardsAmount, err := rewards.GetNodeClaimRewardsAmount(rp, nodeAccount.Address, nil); err != nil {
+	if rewardsAmount, err := v100_rewards.GetNodeClaimRewardsAmount(rp, nodeAccount.Address, nil, nil); err != nil {
 		t.Error(err)
 	} else if rewardsAmount.Cmp(big.NewInt(0)) != 0 {
 		t.Errorf("Incorrect initial node claim rewards amount %s", rewardsAmount.String())
 	}
 
 	// Get & check initial RPL rewards amount
-	if pendingRewards, err := rewards.GetPendingRewards(rp, nil); err != nil {
+	if pendingRewards, err := rewards.GetPendingRPLRewards(rp, nil); err != nil {
 		t.Error(err)
-	} else if pendingRewards != 0 {
-		t.Errorf("Incorrect initial pending rewards amount %f", pendingRewards)
+	} else if pendingRewards.Sign() != 0 {
+		t.Errorf("Incorrect initial pending rewards amount %s", pendingRewards.String())
 	}
 
 	// Start RPL inflation
@@ -138,17 +139,17 @@ func TestNodeRewards(t *testing.T) {
 	}
 
 	// Get & check updated node claim rewards amount
-	if rewardsAmount, err := rewards.GetNodeClaimRewardsAmount(rp, nodeAccount.Address, nil); err != nil {
+	if rewardsAmount, err := v100_rewards.GetNodeClaimRewardsAmount(rp, nodeAccount.Address, nil, nil); err != nil {
 		t.Error(err)
 	} else if rewardsAmount.Cmp(big.NewInt(0)) != 1 {
 		t.Errorf("Incorrect updated node claim rewards amount %s", rewardsAmount.String())
 	}
 
 	// Get & check updated RPL rewards amount
-	if pendingRewards, err := rewards.GetPendingRewards(rp, nil); err != nil {
+	if pendingRewards, err := rewards.GetPendingRPLRewards(rp, nil); err != nil {
 		t.Error(err)
-	} else if pendingRewards <= 0 {
-		t.Errorf("Incorrect updated pending rewards amount %f", pendingRewards)
+	} else if pendingRewards.Sign() <= 0 {
+		t.Errorf("Incorrect updated pending rewards amount %s", pendingRewards.String())
 	}
 
 	// Get & check initial node RPL balance
@@ -159,7 +160,7 @@ func TestNodeRewards(t *testing.T) {
 	}
 
 	// Claim node rewards
-	if _, err := rewards.ClaimNodeRewards(rp, nodeAccount.GetTransactor()); err != nil {
+	if _, err := v100_rewards.ClaimNodeRewards(rp, nodeAccount.GetTransactor(), nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -0,0 +1,123 @@
+package rocketpool
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+)
+
+const oldDiffAbi = `[
+	{"type":"function","name":"getBalance","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"deposit","stateMutability":"payable","inputs":[],"outputs":[]},
+	{"type":"function","name":"withdraw","stateMutability":"nonpayable","inputs":[{"name":"_amount","type":"uint256"}],"outputs":[]},
+	{"type":"event","name":"DepositReceived","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"amount","type":"uint256","indexed":false}]}
+]`
+
+const newDiffAbi = `[
+	{"type":"function","name":"getBalance","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"deposit","stateMutability":"payable","inputs":[{"name":"_referrer","type":"address"}],"outputs":[]},
+	{"type":"function","name":"getRate","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"event","name":"DepositReceived","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"amount","type":"uint256","indexed":false}]},
+	{"type":"event","name":"RateUpdated","anonymous":false,"inputs":[{"name":"rate","type":"uint256","indexed":false}]}
+]`
+
+func TestDiffABIs(t *testing.T) {
+
+	oldAbi, err := abi.JSON(strings.NewReader(oldDiffAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newAbi, err := abi.JSON(strings.NewReader(newDiffAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := rocketpool.DiffABIs(&oldAbi, &newAbi)
+
+	// Check the method and event changes
+	expected := []struct {
+		name string
+		kind rocketpool.AbiChangeKind
+	}{
+		{"deposit", rocketpool.AbiChangeChanged},
+		{"getRate", rocketpool.AbiChangeAdded},
+		{"withdraw", rocketpool.AbiChangeRemoved},
+	}
+	if len(diff.Methods) != len(expected) {
+		t.Fatalf("Incorrect method change count %d: %+v", len(diff.Methods), diff.Methods)
+	}
+	for i, e := range expected {
+		if diff.Methods[i].Name != e.name || diff.Methods[i].Kind != e.kind {
+			t.Errorf("Incorrect method change %d: expected %s %s, got %+v", i, e.name, e.kind, diff.Methods[i])
+		}
+	}
+	if len(diff.Events) != 1 || diff.Events[0].Name != "RateUpdated" || diff.Events[0].Kind != rocketpool.AbiChangeAdded {
+		t.Errorf("Incorrect event changes %+v", diff.Events)
+	}
+	if summary := diff.Summary(); summary != "methods: 1 added, 1 removed, 1 changed; events: 1 added" {
+		t.Errorf("Incorrect summary %s", summary)
+	}
+
+	// Check breaking changes, with and without a list of called methods
+	if breaking := diff.GetBreakingChanges(nil); len(breaking) != 2 {
+		t.Errorf("Incorrect breaking changes %+v", breaking)
+	}
+	if breaking := diff.GetBreakingChanges([]string{"getBalance", "withdraw"}); len(breaking) != 1 || breaking[0].Name != "withdraw" {
+		t.Errorf("Incorrect breaking changes for called methods %+v", breaking)
+	}
+
+	// Identical ABIs have no changes
+	if rocketpool.DiffABIs(&oldAbi, &oldAbi).HasChanges() {
+		t.Error("Identical ABIs have changes")
+	}
+
+}
+
+func TestCallRecorderBreakingChanges(t *testing.T) {
+
+	oldAbi, err := abi.JSON(strings.NewReader(oldDiffAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newAbi, err := abi.JSON(strings.NewReader(newDiffAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := rocketpool.DiffABIs(&oldAbi, &newAbi)
+
+	// Record calls to one contract; client requests and calls without a method are ignored
+	recorder := rocketpool.NewCallRecorder()
+	for _, op := range []rocketpool.Operation{
+		{Kind: rocketpool.OperationContractCall, Contract: "poolseaVault", Method: "getBalance"},
+		{Kind: rocketpool.OperationContractTransact, Contract: "poolseaVault", Method: "withdraw"},
+		{Kind: rocketpool.OperationContractCall, Contract: "poolseaVault", Method: "getBalance"},
+		{Kind: rocketpool.OperationContractCall, Contract: "poolseaDeposit"},
+	} {
+		_, finish := recorder.Begin(context.Background(), op)
+		finish(nil)
+	}
+
+	// Only the recorded methods are checked for breaking changes
+	calledMethods := recorder.GetCalledMethods("poolseaVault")
+	if strings.Join(calledMethods, ",") != "getBalance,withdraw" {
+		t.Errorf("Incorrect called methods %v", calledMethods)
+	}
+	if breaking := diff.GetBreakingChanges(calledMethods); len(breaking) != 1 || breaking[0].Name != "withdraw" {
+		t.Errorf("Incorrect breaking changes for recorded methods %+v", breaking)
+	}
+
+	// Contracts with no recorded calls are unknown, so every removed or changed method is breaking
+	for _, contractName := range []string{"poolseaDeposit", "poolseaMinipoolManager"} {
+		calledMethods := recorder.GetCalledMethods(contractName)
+		if calledMethods != nil {
+			t.Errorf("Called methods for %s should be unknown, got %v", contractName, calledMethods)
+		}
+		if breaking := diff.GetBreakingChanges(calledMethods); len(breaking) != 2 {
+			t.Errorf("Incorrect breaking changes for %s %+v", contractName, breaking)
+		}
+	}
+
+}
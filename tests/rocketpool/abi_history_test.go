@@ -0,0 +1,138 @@
+package rocketpool
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/fakeclient"
+)
+
+const (
+	abiHistoryV2 = `[{"type":"function","name":"getClaimIntervalsPassed","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}]`
+	abiHistoryV3 = `[{"type":"function","name":"getRewardIndex","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}]`
+)
+
+// Create a network where poolseaRewardsPool started as the v1.0.0 contract, was upgraded on block 200 and had its ABI replaced on block 400
+func newAbiHistoryTestNetwork(t *testing.T) (*rocketpool.RocketPool, *fakeclient.Client, *fakeclient.Storage) {
+	rp, client, storage := newUpgradeHistoryTestNetwork(t, 1000)
+	client.Logs = []types.Log{
+		upgradeEventLog(t, "ContractUpgraded", 200, "poolseaRewardsPool", upgradeHistoryAddresses[0], upgradeHistoryAddresses[1]),
+		upgradeEventLog(t, "ABIUpgraded", 400, "poolseaRewardsPool"),
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("contract.address"), []byte("poolseaRewardsPool.v1")), 0, upgradeHistoryAddresses[0])
+	for _, version := range []struct {
+		block uint64
+		abi   string
+	}{{0, abiHistoryV2}, {200, abiHistoryV2}, {400, abiHistoryV3}} {
+		address := upgradeHistoryAddresses[0]
+		if version.block >= 200 {
+			address = upgradeHistoryAddresses[1]
+		}
+		if err := storage.SetContract("poolseaRewardsPool", version.block, address, version.abi); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rp, client, storage
+}
+
+func TestGetABIAtBlock(t *testing.T) {
+
+	rp, client, _ := newAbiHistoryTestNetwork(t)
+	legacyAbi, err := rocketpool.DecodeAbi(rp.VersionManager.V1_0_0.GetEncodedABI("poolseaRewardsPool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The initial deployment is resolved from the legacy wrapper registered at the same address
+	abi, err := rp.GetABIAtBlock("poolseaRewardsPool", 150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if abi != legacyAbi {
+		t.Error("The legacy wrapper ABI was not used for the initial deployment")
+	}
+
+	// The upgraded contract is resolved from RocketStorage at the block, since no legacy wrapper matches its address
+	abi, err = rp.GetABIAtBlock("poolseaRewardsPool", 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := abi.Methods["getClaimIntervalsPassed"]; !exists {
+		t.Errorf("Incorrect ABI on block 300: %v", abi.Methods)
+	}
+	stringCalls := client.CallCount("getString")
+
+	// Other blocks in the same version are cached
+	if _, err := rp.GetABIAtBlock("poolseaRewardsPool", 399); err != nil {
+		t.Fatal(err)
+	}
+	if client.CallCount("getString") != stringCalls {
+		t.Error("A cached ABI version was loaded again")
+	}
+
+	// The ABI upgrade starts a new version, which is the current one
+	abi, err = rp.GetABIAtBlock("poolseaRewardsPool", 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := abi.Methods["getRewardIndex"]; !exists {
+		t.Errorf("Incorrect ABI on block 400: %v", abi.Methods)
+	}
+	for _, call := range client.Calls {
+		if call.Method == "getString" && call.BlockNumber != nil && call.BlockNumber.Uint64() >= 400 {
+			t.Errorf("The current ABI was loaded at block %s instead of the latest block", call.BlockNumber)
+		}
+	}
+
+}
+
+func TestGetABIFallback(t *testing.T) {
+
+	// The node has no state before block 250
+	rp, _, storage := newAbiHistoryTestNetwork(t)
+	storage.PrunedBefore = 250
+	legacyAbi, err := rocketpool.DecodeAbi(rp.VersionManager.V1_0_0.GetEncodedABI("poolseaRewardsPool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Regular lookups on blocks the node still has work as normal
+	abi, err := rp.GetABI("poolseaRewardsPool", &bind.CallOpts{BlockNumber: big.NewInt(300)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := abi.Methods["getClaimIntervalsPassed"]; !exists {
+		t.Errorf("Incorrect ABI on block 300: %v", abi.Methods)
+	}
+
+	// Lookups on pruned blocks fall back to the legacy wrappers
+	abi, err = rp.GetABI("poolseaRewardsPool", &bind.CallOpts{BlockNumber: big.NewInt(150)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if abi != legacyAbi {
+		t.Error("The legacy wrapper ABI was not used for a pruned block")
+	}
+	address, err := rp.GetAddress("poolseaRewardsPool", &bind.CallOpts{BlockNumber: big.NewInt(150)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *address != upgradeHistoryAddresses[0] {
+		t.Errorf("Incorrect address on a pruned block %s", address.Hex())
+	}
+
+	// Versions without a legacy wrapper can't be resolved on pruned blocks
+	if _, err := rp.GetABI("poolseaRewardsPool", &bind.CallOpts{BlockNumber: big.NewInt(220)}); err == nil {
+		t.Error("An ABI was resolved for a pruned block without a legacy wrapper")
+	}
+	if address, err := rp.GetAddress("poolseaRewardsPool", &bind.CallOpts{BlockNumber: big.NewInt(220)}); err != nil || *address != upgradeHistoryAddresses[1] {
+		t.Errorf("Incorrect address on a pruned block after the upgrade %v (%v)", address, err)
+	}
+
+}
@@ -0,0 +1,197 @@
+package rocketpool
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/fakeclient"
+)
+
+const instrumentationTestAbi = `[
+	{"type":"function","name":"getValue","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"setValue","stateMutability":"nonpayable","inputs":[{"name":"_value","type":"uint256"}],"outputs":[]}
+]`
+
+var instrumentationTestAddress = common.HexToAddress("0x3000000000000000000000000000000000000001")
+
+// An operation recorded by the test instrumentation, with the operation that was active in its context
+type recordedOperation struct {
+	op        rocketpool.Operation
+	parent    rocketpool.Operation
+	hasParent bool
+	err       error
+}
+
+// Records every operation, tracking the parent operation through the context like a tracer would
+type recordingInstrumentation struct {
+	operations []recordedOperation
+	lock       sync.Mutex
+}
+type operationContextKey struct{}
+
+func (r *recordingInstrumentation) Begin(ctx context.Context, op rocketpool.Operation) (context.Context, func(err error)) {
+	parent, hasParent := ctx.Value(operationContextKey{}).(rocketpool.Operation)
+	ctx = context.WithValue(ctx, operationContextKey{}, op)
+	return ctx, func(err error) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.operations = append(r.operations, recordedOperation{op: op, parent: parent, hasParent: hasParent, err: err})
+	}
+}
+
+// Get the recorded operation for a method
+func (r *recordingInstrumentation) find(t *testing.T, method string) recordedOperation {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, recorded := range r.operations {
+		if recorded.op.Method == method {
+			return recorded
+		}
+	}
+	t.Fatalf("Operation %s was not recorded", method)
+	return recordedOperation{}
+}
+
+// Create a contract manager on an instrumented fake client, with a test contract
+func newInstrumentationTestNetwork(t *testing.T) (*rocketpool.RocketPool, *rocketpool.Contract) {
+	client := fakeclient.NewClient(1000)
+	storage, err := client.AddStorage(upgradeHistoryStorageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("deploy.block")), 0, big.NewInt(100))
+	if err := client.AddContract(instrumentationTestAddress, instrumentationTestAbi, func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+		return []interface{}{big.NewInt(7)}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	instrumentedClient := rocketpool.NewInstrumentedClient(client)
+	rp, err := rocketpool.NewRocketPool(instrumentedClient, upgradeHistoryStorageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractAbi, err := abi.JSON(strings.NewReader(instrumentationTestAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := &rocketpool.Contract{
+		Contract: bind.NewBoundContract(instrumentationTestAddress, contractAbi, instrumentedClient, instrumentedClient, instrumentedClient),
+		Address:  &instrumentationTestAddress,
+		ABI:      &contractAbi,
+		Client:   instrumentedClient,
+		Name:     "poolseaTest",
+	}
+	return rp, contract
+}
+
+func TestInstrumentationDisabled(t *testing.T) {
+
+	rocketpool.SetInstrumentation(nil)
+	rp, contract := newInstrumentationTestNetwork(t)
+
+	// Operations pass the context straight through without allocating
+	ctx := context.Background()
+	op := rocketpool.Operation{Kind: rocketpool.OperationContractCall, Contract: "poolseaTest", Method: "getValue"}
+	if opCtx, _ := rocketpool.BeginOperation(ctx, op); opCtx != ctx {
+		t.Error("The context was replaced while instrumentation is disabled")
+	}
+	if allocs := testing.AllocsPerRun(100, func() {
+		_, finish := rocketpool.BeginOperation(ctx, op)
+		finish(nil)
+	}); allocs != 0 {
+		t.Errorf("Disabled instrumentation allocated %f times per operation", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() {
+		_, _ = rp.Client.BlockNumber(ctx)
+	}); allocs != 0 {
+		t.Errorf("The instrumented client allocated %f times per request while disabled", allocs)
+	}
+
+	// Calls still work
+	value := new(*big.Int)
+	if err := contract.Call(nil, value, "getValue"); err != nil {
+		t.Fatal(err)
+	}
+	if (*value).Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("Incorrect value %s", *value)
+	}
+
+}
+
+func TestInstrumentationContexts(t *testing.T) {
+
+	recorder := &recordingInstrumentation{}
+	rocketpool.SetInstrumentation(recorder)
+	t.Cleanup(func() { rocketpool.SetInstrumentation(nil) })
+	rp, contract := newInstrumentationTestNetwork(t)
+
+	// RocketStorage binding calls are reported as contract calls
+	if _, err := rp.RocketStorage.GetUint(nil, crypto.Keccak256Hash([]byte("deploy.block"))); err != nil {
+		t.Fatal(err)
+	}
+	storageCall := recorder.find(t, "getUint")
+	if storageCall.op.Kind != rocketpool.OperationContractCall || storageCall.op.Contract != "rocketStorage" {
+		t.Errorf("Incorrect RocketStorage operation %+v", storageCall.op)
+	}
+
+	// Contract calls are the parent of their client requests
+	value := new(*big.Int)
+	if err := contract.Call(nil, value, "getValue"); err != nil {
+		t.Fatal(err)
+	}
+	parents := []string{}
+	for _, recorded := range recorder.operations {
+		if recorded.op.Method == "eth_call" {
+			parents = append(parents, recorded.parent.Method)
+		}
+	}
+	if len(parents) != 2 || parents[0] != "getUint" || parents[1] != "getValue" {
+		t.Errorf("Incorrect eth_call parents %v", parents)
+	}
+
+	// Transactions pass their context through to the gas estimate and the send, without changing the caller's options
+	parentCtx := context.WithValue(context.Background(), operationContextKey{}, rocketpool.Operation{Method: "parent"})
+	opts := &bind.TransactOpts{
+		From:    common.HexToAddress("0x4000000000000000000000000000000000000001"),
+		Signer:  func(address common.Address, tx *types.Transaction) (*types.Transaction, error) { return tx, nil },
+		Context: parentCtx,
+	}
+	if _, err := contract.Transact(opts, "setValue", big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Context != parentCtx {
+		t.Error("The caller's transaction context was replaced")
+	}
+	for _, expected := range []struct {
+		method string
+		parent string
+	}{
+		{"setValue", "parent"},
+		{"eth_sendRawTransaction", "setValue"},
+		{"eth_estimateGas", ""},
+	} {
+		recorded := recorder.find(t, expected.method)
+		if !recorded.hasParent {
+			t.Errorf("%s has no parent operation", expected.method)
+			continue
+		}
+		if expected.parent != "" && recorded.parent.Method != expected.parent {
+			t.Errorf("Incorrect %s parent %s", expected.method, recorded.parent.Method)
+		}
+	}
+	if estimate := recorder.find(t, "eth_estimateGas"); estimate.parent.Kind != rocketpool.OperationEstimateGas {
+		t.Errorf("Incorrect gas estimate parent %+v", estimate.parent)
+	}
+
+}
@@ -0,0 +1,297 @@
+package rocketpool
+
+import (
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/fakeclient"
+)
+
+const upgradeHistoryAbi = `[
+	{"type":"event","name":"ContractUpgraded","anonymous":false,"inputs":[{"name":"name","type":"bytes32","indexed":true},{"name":"oldAddress","type":"address","indexed":true},{"name":"newAddress","type":"address","indexed":true},{"name":"time","type":"uint256","indexed":false}]},
+	{"type":"event","name":"ContractAdded","anonymous":false,"inputs":[{"name":"name","type":"bytes32","indexed":true},{"name":"newAddress","type":"address","indexed":true},{"name":"time","type":"uint256","indexed":false}]},
+	{"type":"event","name":"ABIUpgraded","anonymous":false,"inputs":[{"name":"name","type":"bytes32","indexed":true},{"name":"abi","type":"string","indexed":false},{"name":"time","type":"uint256","indexed":false}]}
+]`
+
+var (
+	upgradeHistoryStorageAddress = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	upgradeHistoryUpgradeAddress = common.HexToAddress("0x1000000000000000000000000000000000000002")
+	upgradeHistoryAddresses      = []common.Address{
+		common.HexToAddress("0x2000000000000000000000000000000000000001"),
+		common.HexToAddress("0x2000000000000000000000000000000000000002"),
+		common.HexToAddress("0x2000000000000000000000000000000000000003"),
+	}
+	upgradeHistoryAddedAddress = common.HexToAddress("0x2000000000000000000000000000000000000004")
+)
+
+// Create a contract manager backed by a fake client, with the upgrade contract registered at deploy block 100
+func newUpgradeHistoryTestNetwork(t *testing.T, latestBlock uint64) (*rocketpool.RocketPool, *fakeclient.Client, *fakeclient.Storage) {
+	client := fakeclient.NewClient(latestBlock)
+	storage, err := client.AddStorage(upgradeHistoryStorageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("deploy.block")), 0, big.NewInt(100))
+	if err := storage.SetContract("poolseaDAONodeTrustedUpgrade", 0, upgradeHistoryUpgradeAddress, upgradeHistoryAbi); err != nil {
+		t.Fatal(err)
+	}
+	rp, err := rocketpool.NewRocketPool(client, upgradeHistoryStorageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rp, client, storage
+}
+
+// Create a log for an upgrade contract event
+func upgradeEventLog(t *testing.T, eventName string, block uint64, contractName string, addresses ...common.Address) types.Log {
+	topics := []common.Hash{upgradeHistoryEventID(t, eventName), crypto.Keccak256Hash([]byte(contractName))}
+	for _, address := range addresses {
+		topics = append(topics, common.BytesToHash(address.Bytes()))
+	}
+	return types.Log{
+		Address:     upgradeHistoryUpgradeAddress,
+		Topics:      topics,
+		BlockNumber: block,
+	}
+}
+
+// Get the ID of an upgrade contract event
+func upgradeHistoryEventID(t *testing.T, eventName string) common.Hash {
+	parsed, err := abi.JSON(strings.NewReader(upgradeHistoryAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Events[eventName].ID
+}
+
+func TestUpgradeHistoryRanges(t *testing.T) {
+
+	// poolseaFoo was upgraded twice and poolseaBar was added later; poolseaBaz was never upgraded
+	rp, client, storage := newUpgradeHistoryTestNetwork(t, 1000)
+	client.Logs = []types.Log{
+		upgradeEventLog(t, "ContractUpgraded", 200, "poolseaFoo", upgradeHistoryAddresses[0], upgradeHistoryAddresses[1]),
+		upgradeEventLog(t, "ContractAdded", 300, "poolseaBar", upgradeHistoryAddedAddress),
+		upgradeEventLog(t, "ContractUpgraded", 500, "poolseaFoo", upgradeHistoryAddresses[1], upgradeHistoryAddresses[2]),
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("contract.address"), []byte("poolseaBaz")), 0, upgradeHistoryAddresses[0])
+
+	// Check the ranges of the upgraded contract
+	ranges, err := rp.UpgradeHistory.GetContractAddressRanges("poolseaFoo", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []rocketpool.ContractAddressRange{
+		{Address: upgradeHistoryAddresses[0], StartBlock: 100, EndBlock: 199},
+		{Address: upgradeHistoryAddresses[1], StartBlock: 200, EndBlock: 499},
+		{Address: upgradeHistoryAddresses[2], StartBlock: 500, IsCurrent: true},
+	}
+	if len(ranges) != len(expected) {
+		t.Fatalf("Incorrect range count %d: %+v", len(ranges), ranges)
+	}
+	for i, r := range expected {
+		if ranges[i] != r {
+			t.Errorf("Incorrect range %d: expected %+v, got %+v", i, r, ranges[i])
+		}
+	}
+
+	// Check the address lookups by block
+	for _, lookup := range []struct {
+		block   uint64
+		address common.Address
+	}{{150, upgradeHistoryAddresses[0]}, {199, upgradeHistoryAddresses[0]}, {200, upgradeHistoryAddresses[1]}, {499, upgradeHistoryAddresses[1]}, {500, upgradeHistoryAddresses[2]}, {900, upgradeHistoryAddresses[2]}} {
+		address, err := rp.GetContractAddressAtBlock("poolseaFoo", lookup.block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if address != lookup.address {
+			t.Errorf("Incorrect address on block %d: expected %s, got %s", lookup.block, lookup.address.Hex(), address.Hex())
+		}
+	}
+
+	// Check the added contract, and that it didn't exist before it was added
+	if address, err := rp.GetContractAddressAtBlock("poolseaBar", 300); err != nil || address != upgradeHistoryAddedAddress {
+		t.Errorf("Incorrect added contract address %s (%v)", address.Hex(), err)
+	}
+	if _, err := rp.GetContractAddressAtBlock("poolseaBar", 299); err == nil {
+		t.Error("Added contract was found before it was added")
+	}
+
+	// Contracts without events use their current address from the deploy block
+	ranges, err = rp.UpgradeHistory.GetContractAddressRanges("poolseaBaz", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 1 || ranges[0].Address != upgradeHistoryAddresses[0] || ranges[0].StartBlock != 100 || !ranges[0].IsCurrent {
+		t.Errorf("Incorrect ranges for a contract that was never upgraded: %+v", ranges)
+	}
+
+	// Addresses registered after the block in opts are dropped
+	opts := &bind.CallOpts{BlockNumber: big.NewInt(300)}
+	addresses, err := rp.UpgradeHistory.GetContractAddresses("poolseaFoo", nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addresses) != 2 || addresses[0] != upgradeHistoryAddresses[0] || addresses[1] != upgradeHistoryAddresses[1] {
+		t.Errorf("Incorrect addresses up to block 300: %v", addresses)
+	}
+	if _, err := rp.UpgradeHistory.GetContractAddresses("poolseaBar", nil, &bind.CallOpts{BlockNumber: big.NewInt(250)}); err == nil {
+		t.Error("Addresses were returned for a contract before it was added")
+	}
+
+	// Modifying the returned ranges doesn't modify the cache
+	ranges, _ = rp.UpgradeHistory.GetContractAddressRanges("poolseaFoo", nil, nil)
+	ranges[0].Address = common.Address{}
+	if ranges, _ = rp.UpgradeHistory.GetContractAddressRanges("poolseaFoo", nil, nil); ranges[0].Address != upgradeHistoryAddresses[0] {
+		t.Error("Returned ranges share memory with the cache")
+	}
+
+}
+
+func TestUpgradeHistoryCaching(t *testing.T) {
+
+	rp, client, _ := newUpgradeHistoryTestNetwork(t, 25099)
+	client.Logs = []types.Log{
+		upgradeEventLog(t, "ContractUpgraded", 200, "poolseaFoo", upgradeHistoryAddresses[0], upgradeHistoryAddresses[1]),
+	}
+
+	// The first lookup scans from the deploy block in bounded intervals
+	if _, err := rp.UpgradeHistory.GetContractAddressRanges("poolseaFoo", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.LogQueries) != 3 {
+		t.Fatalf("Incorrect query count %d", len(client.LogQueries))
+	}
+	for i, expected := range [][2]uint64{{100, 10099}, {10100, 20099}, {20100, 25099}} {
+		query := client.LogQueries[i]
+		if query.FromBlock == nil || query.ToBlock == nil || query.FromBlock.Uint64() != expected[0] || query.ToBlock.Uint64() != expected[1] {
+			t.Errorf("Incorrect query %d range: expected %v, got %v-%v", i, expected, query.FromBlock, query.ToBlock)
+		}
+	}
+
+	// Lookups without new blocks don't scan again
+	if _, err := rp.GetContractAddressAtBlock("poolseaFoo", 150); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.LogQueries) != 3 {
+		t.Errorf("Cached history was scanned again (%d queries)", len(client.LogQueries))
+	}
+
+	// New blocks are scanned incrementally, with the interval size provided by the caller
+	client.LatestBlock = 25300
+	client.Logs = append(client.Logs, upgradeEventLog(t, "ContractUpgraded", 25200, "poolseaFoo", upgradeHistoryAddresses[1], upgradeHistoryAddresses[2]))
+	ranges, err := rp.UpgradeHistory.GetContractAddressRanges("poolseaFoo", big.NewInt(100), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.LogQueries) != 6 {
+		t.Fatalf("Incorrect query count after new blocks %d", len(client.LogQueries))
+	}
+	if query := client.LogQueries[3]; query.FromBlock.Uint64() != 25100 || query.ToBlock.Uint64() != 25199 {
+		t.Errorf("Incorrect incremental query range %v-%v", query.FromBlock, query.ToBlock)
+	}
+	if len(ranges) != 3 || ranges[1].EndBlock != 25199 || ranges[1].IsCurrent || ranges[2].Address != upgradeHistoryAddresses[2] || !ranges[2].IsCurrent {
+		t.Errorf("Incorrect ranges after an incremental scan: %+v", ranges)
+	}
+
+}
+
+func TestUpgradeHistoryUpgradeContractDeployments(t *testing.T) {
+
+	// The upgrade contract was itself upgraded on block 300, so earlier upgrades were emitted by its previous deployment
+	previousUpgradeAddress := common.HexToAddress("0x1000000000000000000000000000000000000003")
+	nextUpgradeAddress := common.HexToAddress("0x1000000000000000000000000000000000000004")
+	otherAddress := common.HexToAddress("0x1000000000000000000000000000000000000009")
+	rp, client, _ := newUpgradeHistoryTestNetwork(t, 1000)
+	fooUpgraded := upgradeEventLog(t, "ContractUpgraded", 200, "poolseaFoo", upgradeHistoryAddresses[0], upgradeHistoryAddresses[1])
+	fooUpgraded.Address = previousUpgradeAddress
+	upgradeContractUpgraded := upgradeEventLog(t, "ContractUpgraded", 300, "poolseaDAONodeTrustedUpgrade", previousUpgradeAddress, upgradeHistoryUpgradeAddress)
+	upgradeContractUpgraded.Address = previousUpgradeAddress
+	otherUpgraded := upgradeEventLog(t, "ContractUpgraded", 400, "poolseaFoo", upgradeHistoryAddresses[1], upgradeHistoryAddedAddress)
+	otherUpgraded.Address = otherAddress
+	client.Logs = []types.Log{
+		fooUpgraded,
+		upgradeContractUpgraded,
+		otherUpgraded,
+		upgradeEventLog(t, "ContractUpgraded", 500, "poolseaFoo", upgradeHistoryAddresses[1], upgradeHistoryAddresses[2]),
+	}
+
+	// Events from both deployments are used, and events from other contracts are ignored
+	ranges, err := rp.UpgradeHistory.GetContractAddressRanges("poolseaFoo", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []rocketpool.ContractAddressRange{
+		{Address: upgradeHistoryAddresses[0], StartBlock: 100, EndBlock: 199},
+		{Address: upgradeHistoryAddresses[1], StartBlock: 200, EndBlock: 499},
+		{Address: upgradeHistoryAddresses[2], StartBlock: 500, IsCurrent: true},
+	}
+	if len(ranges) != len(expected) {
+		t.Fatalf("Incorrect range count %d: %+v", len(ranges), ranges)
+	}
+	for i, r := range expected {
+		if ranges[i] != r {
+			t.Errorf("Incorrect range %d: expected %+v, got %+v", i, r, ranges[i])
+		}
+	}
+
+	// Upgrades of the upgrade contract found by an incremental scan are followed
+	client.LatestBlock = 1300
+	nextUpgraded := upgradeEventLog(t, "ContractUpgraded", 1200, "poolseaFoo", upgradeHistoryAddresses[2], upgradeHistoryAddedAddress)
+	nextUpgraded.Address = nextUpgradeAddress
+	client.Logs = append(client.Logs,
+		upgradeEventLog(t, "ContractUpgraded", 1100, "poolseaDAONodeTrustedUpgrade", upgradeHistoryUpgradeAddress, nextUpgradeAddress),
+		nextUpgraded,
+	)
+	ranges, err = rp.UpgradeHistory.GetContractAddressRanges("poolseaFoo", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 4 || ranges[2].EndBlock != 1199 || ranges[3].Address != upgradeHistoryAddedAddress || ranges[3].StartBlock != 1200 || !ranges[3].IsCurrent {
+		t.Errorf("Incorrect ranges after the upgrade contract was upgraded: %+v", ranges)
+	}
+
+}
+
+func TestUpgradeHistoryConcurrentLookups(t *testing.T) {
+
+	rp, client, _ := newUpgradeHistoryTestNetwork(t, 1000)
+	client.Logs = []types.Log{
+		upgradeEventLog(t, "ContractUpgraded", 200, "poolseaFoo", upgradeHistoryAddresses[0], upgradeHistoryAddresses[1]),
+	}
+
+	// Lookups that scan at the same time only merge the events once
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = rp.UpgradeHistory.GetContractAddressRanges("poolseaFoo", nil, nil)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	ranges, err := rp.UpgradeHistory.GetContractAddressRanges("poolseaFoo", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 2 || ranges[1].Address != upgradeHistoryAddresses[1] || !ranges[1].IsCurrent {
+		t.Errorf("Incorrect ranges after concurrent lookups: %+v", ranges)
+	}
+
+}
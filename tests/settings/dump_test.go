@@ -0,0 +1,198 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings"
+	"github.com/Seb369888/poolsea-go/settings/protocol"
+	"github.com/Seb369888/poolsea-go/settings/setting"
+	"github.com/Seb369888/poolsea-go/settings/trustednode"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/fakeclient"
+)
+
+// Get a pointer to a bool
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+// Build a settings contract ABI with a getter for each of the provided settings, leaving out the excluded getters
+func settingsContractAbi(getters map[string]string, exclude ...string) string {
+	methods := []string{}
+	for getter, signature := range getters {
+		excluded := false
+		for _, name := range exclude {
+			excluded = excluded || name == getter
+		}
+		if !excluded {
+			methods = append(methods, signature)
+		}
+	}
+	return "[" + strings.Join(methods, ",") + "]"
+}
+
+func TestDiffSettings(t *testing.T) {
+
+	// Build two dumps that differ in three settings
+	from := &settings.SettingsDump{BlockNumber: 100}
+	from.Auction.LotDuration = big.NewInt(40320)
+	from.Network.MinimumNodeFee = big.NewInt(5e16)
+	from.Node.DepositEnabled = boolPtr(true)
+	from.Proposals.VoteTime = big.NewInt(86400)
+
+	to := &settings.SettingsDump{BlockNumber: 200}
+	to.Auction.LotDuration = big.NewInt(40320)
+	to.Network.MinimumNodeFee = big.NewInt(14e16)
+	to.Node.DepositEnabled = boolPtr(false)
+	to.Proposals.VoteTime = big.NewInt(172800)
+
+	// Round-trip the new dump through JSON, as a stored dump would be
+	data, err := json.Marshal(to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to = &settings.SettingsDump{}
+	if err := json.Unmarshal(data, to); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the changelog
+	changelog := settings.DiffSettings(from, to)
+	if changelog.FromBlock != 100 || changelog.ToBlock != 200 {
+		t.Errorf("Incorrect changelog blocks %d to %d", changelog.FromBlock, changelog.ToBlock)
+	}
+	if len(changelog.Changes) != 3 {
+		t.Fatalf("Incorrect change count %d: %+v", len(changelog.Changes), changelog.Changes)
+	}
+
+	nodeFee := changelog.Changes[0]
+	if nodeFee.Category != "network" || nodeFee.Name != "minimumNodeFee" || nodeFee.Path != protocol.MinimumNodeFeeSettingPath {
+		t.Errorf("Incorrect node fee change %+v", nodeFee)
+	}
+	if nodeFee.NewValue.Unit != rptypes.SettingUnitPercent || nodeFee.OldValue.Formatted != "5%" || nodeFee.NewValue.Formatted != "14%" {
+		t.Errorf("Incorrect node fee values %+v -> %+v", nodeFee.OldValue, nodeFee.NewValue)
+	}
+
+	depositEnabled := changelog.Changes[1]
+	if depositEnabled.Name != "depositEnabled" || depositEnabled.ContractName != protocol.NodeSettingsContractName || depositEnabled.NewValue.Raw != false {
+		t.Errorf("Incorrect deposit enabled change %+v", depositEnabled)
+	}
+
+	voteTime := changelog.Changes[2]
+	if voteTime.ContractName != trustednode.ProposalsSettingsContractName || voteTime.NewValue.Formatted != "172800 seconds (48h0m0s)" {
+		t.Errorf("Incorrect vote time change %+v", voteTime)
+	}
+
+	if !strings.Contains(changelog.String(), "network.minimumNodeFee: 5% -> 14%") {
+		t.Errorf("Incorrect changelog string:\n%s", changelog.String())
+	}
+
+	// Identical dumps have no changes
+	if changes := settings.DiffSettings(from, from).Changes; len(changes) != 0 {
+		t.Errorf("Identical dumps have %d changes", len(changes))
+	}
+
+}
+
+func TestGetSettingsDumpMissingGetters(t *testing.T) {
+
+	// Build the getters of each settings contract from the registry
+	getters := map[string]map[string]string{}
+	addGetter := func(contractName, getter, inputs, outputType string) {
+		if getters[contractName] == nil {
+			getters[contractName] = map[string]string{}
+		}
+		getters[contractName][getter] = fmt.Sprintf(`{"type":"function","name":"%s","stateMutability":"view","inputs":[%s],"outputs":[{"name":"","type":"%s"}]}`, getter, inputs, outputType)
+	}
+	for _, entry := range settings.Registry {
+		outputType := "uint256"
+		if entry.Type == setting.TypeBool {
+			outputType = "bool"
+		}
+		addGetter(entry.ContractName, entry.Getter, "", outputType)
+	}
+	addGetter(protocol.MinipoolSettingsContractName, "getLaunchBalance", "", "uint256")
+	addGetter(protocol.RewardsSettingsContractName, "getRewardsClaimerPerc", `{"name":"_contractName","type":"string"}`, "uint256")
+	addGetter(protocol.RewardsSettingsContractName, "getRewardsClaimersPercTotal", "", "uint256")
+
+	// Register the settings contracts; vacant minipools were added on block 500, and the node fee demand range getter always reverts
+	storageAddress := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	multicallAddress := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	client := fakeclient.NewClient(1000)
+	storage, err := client.AddStorage(storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("deploy.block")), 0, big.NewInt(100))
+	if err := client.AddMulticall(multicallAddress); err != nil {
+		t.Fatal(err)
+	}
+	contractIndex := 0
+	for contractName, contractGetters := range getters {
+		contractIndex++
+		address := common.BigToAddress(big.NewInt(int64(0x2000 + contractIndex)))
+		if err := client.AddContract(address, settingsContractAbi(contractGetters), func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+			if method == "getNodeFeeDemandRange" {
+				return nil, fmt.Errorf("execution reverted")
+			}
+			if strings.HasSuffix(method, "Enabled") {
+				return []interface{}{true}, nil
+			}
+			return []interface{}{big.NewInt(7)}, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.SetContract(contractName, 0, address, settingsContractAbi(contractGetters, "getVacantMinipoolsEnabled")); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.SetContract(contractName, 500, address, settingsContractAbi(contractGetters)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rp, err := rocketpool.NewRocketPool(client, storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Settings that can't be read are left empty and reported
+	oldDump, err := settings.GetSettingsDump(rp, multicallAddress, &bind.CallOpts{BlockNumber: big.NewInt(400)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(oldDump.UnreadFields, ",") != "node.vacantMinipoolsEnabled,network.nodeFeeDemandRange" {
+		t.Errorf("Incorrect unread fields %v", oldDump.UnreadFields)
+	}
+	if oldDump.Node.VacantMinipoolsEnabled != nil || oldDump.Network.NodeFeeDemandRange != nil {
+		t.Error("Unread settings were set")
+	}
+	if oldDump.Node.DepositEnabled == nil || !*oldDump.Node.DepositEnabled || oldDump.Network.MinimumNodeFee == nil || oldDump.Network.MinimumNodeFee.Int64() != 7 {
+		t.Errorf("Incorrect node settings %+v and network settings %+v", oldDump.Node, oldDump.Network)
+	}
+	newDump, err := settings.GetSettingsDump(rp, multicallAddress, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newDump.UnreadFields) != 1 || newDump.Node.VacantMinipoolsEnabled == nil {
+		t.Errorf("Incorrect unread fields %v", newDump.UnreadFields)
+	}
+
+	// Settings that weren't present in the old dump show up as changes
+	changelog := settings.DiffSettings(oldDump, newDump)
+	if len(changelog.Changes) != 1 {
+		t.Fatalf("Incorrect change count %d: %+v", len(changelog.Changes), changelog.Changes)
+	}
+	if change := changelog.Changes[0]; change.Name != "vacantMinipoolsEnabled" || change.OldValue.Raw != nil || change.OldValue.Formatted != "not present" || change.NewValue.Raw != true {
+		t.Errorf("Incorrect vacant minipools change %+v", change)
+	}
+
+}
@@ -0,0 +1,251 @@
+package settings
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/settings"
+	"github.com/Seb369888/poolsea-go/settings/protocol"
+	"github.com/Seb369888/poolsea-go/settings/trustednode"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/fakeclient"
+)
+
+// The state of the fake oracle DAO
+type fakeTrustedNodeDAO struct {
+	isMember         bool
+	memberCount      int64
+	minimumMembers   int64
+	lastProposalTime int64
+	cooldownTime     int64
+	executionError   error
+}
+
+var (
+	proposerAddress = common.HexToAddress("0x4000000000000000000000000000000000000001")
+	upgradedAddress = common.HexToAddress("0x2000000000000000000000000000000000000009")
+	newAddress      = common.HexToAddress("0x2000000000000000000000000000000000000010")
+)
+
+// Create a fake network with the oracle DAO contracts used to validate proposals
+func newProposalValidationNetwork(t *testing.T, state *fakeTrustedNodeDAO) (*rocketpool.RocketPool, *fakeclient.Client) {
+
+	storageAddress := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	client := fakeclient.NewClient(1000)
+	client.BlockTime = 10000
+	storage, err := client.AddStorage(storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Set(crypto.Keccak256Hash([]byte("deploy.block")), 0, big.NewInt(100))
+
+	contracts := []struct {
+		name    string
+		address common.Address
+		abi     string
+		handler fakeclient.Handler
+	}{
+		{"poolseaDAONodeTrusted", common.HexToAddress("0x2000000000000000000000000000000000000001"), `[
+			{"type":"function","name":"getMemberIsValid","stateMutability":"view","inputs":[{"name":"_nodeAddress","type":"address"}],"outputs":[{"name":"","type":"bool"}]},
+			{"type":"function","name":"getMemberCount","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+			{"type":"function","name":"getMemberMinRequired","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+			{"type":"function","name":"getMemberLastProposalTime","stateMutability":"view","inputs":[{"name":"_nodeAddress","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}
+		]`, func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+			switch method {
+			case "getMemberIsValid":
+				return []interface{}{state.isMember}, nil
+			case "getMemberCount":
+				return []interface{}{big.NewInt(state.memberCount)}, nil
+			case "getMemberMinRequired":
+				return []interface{}{big.NewInt(state.minimumMembers)}, nil
+			default:
+				return []interface{}{big.NewInt(state.lastProposalTime)}, nil
+			}
+		}},
+		{"poolseaDAONodeTrustedProposals", common.HexToAddress("0x2000000000000000000000000000000000000002"), `[
+			{"type":"function","name":"execute","stateMutability":"nonpayable","inputs":[{"name":"_proposalID","type":"uint256"}],"outputs":[]}
+		]`, nil},
+		{trustednode.ProposalsSettingsContractName, common.HexToAddress("0x2000000000000000000000000000000000000003"), `[
+			{"type":"function","name":"getCooldownTime","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}
+		]`, func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+			return []interface{}{big.NewInt(state.cooldownTime)}, nil
+		}},
+		{trustednode.MembersSettingsContractName, common.HexToAddress("0x2000000000000000000000000000000000000004"), `[
+			{"type":"function","name":"setSettingUint","stateMutability":"nonpayable","inputs":[{"name":"_settingPath","type":"string"},{"name":"_value","type":"uint256"}],"outputs":[]}
+		]`, func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+			return []interface{}{}, state.executionError
+		}},
+		{"poolseaDAONodeTrustedUpgrade", common.HexToAddress("0x2000000000000000000000000000000000000005"), `[
+			{"type":"function","name":"upgrade","stateMutability":"nonpayable","inputs":[{"name":"_type","type":"string"},{"name":"_name","type":"string"},{"name":"_contractAbi","type":"string"},{"name":"_contractAddress","type":"address"}],"outputs":[]}
+		]`, func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+			return []interface{}{}, state.executionError
+		}},
+	}
+	for _, contract := range contracts {
+		if err := storage.SetContract(contract.name, 0, contract.address, contract.abi); err != nil {
+			t.Fatal(err)
+		}
+		if contract.handler != nil {
+			if err := client.AddContract(contract.address, contract.abi, contract.handler); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// A contract that can be upgraded, and the code for its replacement
+	if err := storage.SetContract("poolseaNodeManager", 0, upgradedAddress, `[]`); err != nil {
+		t.Fatal(err)
+	}
+	client.SetCode(newAddress, []byte{0x60, 0x80})
+
+	rp, err := rocketpool.NewRocketPool(client, storageAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rp, client
+
+}
+
+// Check whether a validation has an error containing a string
+func hasValidationError(validation settings.ProposalValidation, substr string) bool {
+	for _, err := range validation.Errors {
+		if strings.Contains(err, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateProposer(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		state      fakeTrustedNodeDAO
+		canPropose bool
+		err        string
+		next       uint64
+	}{
+		{"valid proposer", fakeTrustedNodeDAO{isMember: true, memberCount: 3, minimumMembers: 3}, true, "", 0},
+		{"not a member", fakeTrustedNodeDAO{memberCount: 3, minimumMembers: 3}, false, "is not an oracle DAO member", 0},
+		{"too few members", fakeTrustedNodeDAO{isMember: true, memberCount: 2, minimumMembers: 3}, false, "has 2 members but needs 3", 0},
+		{"cooldown not elapsed", fakeTrustedNodeDAO{isMember: true, memberCount: 3, minimumMembers: 3, lastProposalTime: 9900, cooldownTime: 200}, false, "can propose again in 100 seconds", 10100},
+		{"cooldown just elapsed", fakeTrustedNodeDAO{isMember: true, memberCount: 3, minimumMembers: 3, lastProposalTime: 9800, cooldownTime: 200}, true, "", 10000},
+	}
+	for _, test := range tests {
+		state := test.state
+		rp, client := newProposalValidationNetwork(t, &state)
+		validation, err := settings.ValidateSettingProposal(rp, proposerAddress, trustednode.MembersSettingsContractName, trustednode.QuorumSettingPath, big.NewInt(6e17), nil)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if validation.CanPropose != test.canPropose {
+			t.Errorf("%s: incorrect can propose status %t: %v", test.name, validation.CanPropose, validation.Errors)
+		}
+		if test.err != "" && !hasValidationError(validation, test.err) {
+			t.Errorf("%s: expected error %q, got %v", test.name, test.err, validation.Errors)
+		}
+		if validation.BlockTime != client.BlockTime || validation.NextProposalTime != test.next {
+			t.Errorf("%s: incorrect times: block %d, next proposal %d", test.name, validation.BlockTime, validation.NextProposalTime)
+		}
+	}
+
+}
+
+func TestValidateSettingProposal(t *testing.T) {
+
+	state := fakeTrustedNodeDAO{isMember: true, memberCount: 3, minimumMembers: 3}
+	rp, client := newProposalValidationNetwork(t, &state)
+	validate := func(contractName, path string, value interface{}) settings.ProposalValidation {
+		validation, err := settings.ValidateSettingProposal(rp, proposerAddress, contractName, path, value, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return validation
+	}
+
+	// Valid proposals are simulated
+	if validation := validate(trustednode.MembersSettingsContractName, trustednode.QuorumSettingPath, big.NewInt(6e17)); !validation.CanPropose {
+		t.Errorf("Valid proposal rejected: %v", validation.Errors)
+	}
+	if client.CallCount("setSettingUint") != 1 {
+		t.Error("Proposal execution was not simulated")
+	}
+
+	// Settings on the wrong contract, unknown settings and protocol DAO settings are rejected
+	if validation := validate(trustednode.MinipoolSettingsContractName, trustednode.QuorumSettingPath, big.NewInt(6e17)); !hasValidationError(validation, "Setting members.quorum belongs to contract poolseaDAONodeTrustedSettingsMembers, not poolseaDAONodeTrustedSettingsMinipool") {
+		t.Errorf("Incorrect wrong contract errors %v", validation.Errors)
+	}
+	if validation := validate(trustednode.MembersSettingsContractName, "members.unknown", big.NewInt(1)); !hasValidationError(validation, "Unknown setting members.unknown on contract poolseaDAONodeTrustedSettingsMembers") {
+		t.Errorf("Incorrect unknown setting errors %v", validation.Errors)
+	}
+	if validation := validate(protocol.NetworkSettingsContractName, protocol.NodeConsensusThresholdSettingPath, big.NewInt(6e17)); !hasValidationError(validation, "belongs to the protocol DAO and cannot be proposed") {
+		t.Errorf("Incorrect protocol DAO setting errors %v", validation.Errors)
+	}
+
+	// Out of range values are rejected without simulating them
+	if validation := validate(trustednode.MembersSettingsContractName, trustednode.QuorumSettingPath, big.NewInt(5e17)); validation.CanPropose {
+		t.Error("Quorum below its minimum accepted")
+	}
+	if client.CallCount("setSettingUint") != 1 {
+		t.Error("Invalid proposal execution was simulated")
+	}
+
+	// Proposals that would fail on execution are rejected
+	state.executionError = errors.New("execution reverted")
+	if validation := validate(trustednode.MembersSettingsContractName, trustednode.QuorumSettingPath, big.NewInt(6e17)); validation.CanPropose || validation.SimulationError == "" {
+		t.Error("Failing proposal accepted")
+	}
+
+}
+
+func TestValidateUpgradeProposal(t *testing.T) {
+
+	state := fakeTrustedNodeDAO{isMember: true, memberCount: 3, minimumMembers: 3}
+	rp, client := newProposalValidationNetwork(t, &state)
+	contractAbi := `[{"type":"function","name":"getVersion","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]}]`
+	emptyAddress := common.HexToAddress("0x2000000000000000000000000000000000000011")
+
+	tests := []struct {
+		name         string
+		upgradeType  string
+		contractName string
+		contractAbi  string
+		address      common.Address
+		err          string
+	}{
+		{"valid upgrade", "upgradeContract", "poolseaNodeManager", contractAbi, newAddress, ""},
+		{"valid addition", "addContract", "poolseaNewContract", contractAbi, newAddress, ""},
+		{"valid ABI upgrade", "upgradeABI", "poolseaNodeManager", contractAbi, common.Address{}, ""},
+		{"unknown type", "replaceContract", "poolseaNodeManager", contractAbi, newAddress, "Unknown upgrade type replaceContract"},
+		{"invalid ABI", "upgradeContract", "poolseaNodeManager", "not json", newAddress, "Invalid contract ABI"},
+		{"upgrading a missing contract", "upgradeContract", "poolseaNewContract", contractAbi, newAddress, "Contract poolseaNewContract does not exist"},
+		{"adding an existing ABI", "addABI", "poolseaNodeManager", contractAbi, common.Address{}, "Contract poolseaNodeManager already exists"},
+		{"no code at the address", "upgradeContract", "poolseaNodeManager", contractAbi, emptyAddress, "There is no contract deployed at"},
+	}
+	for _, test := range tests {
+		calls := client.CallCount("upgrade")
+		validation, err := settings.ValidateUpgradeProposal(rp, proposerAddress, test.upgradeType, test.contractName, test.contractAbi, test.address, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if test.err == "" {
+			if !validation.CanPropose || client.CallCount("upgrade") != calls+1 {
+				t.Errorf("%s: valid upgrade rejected or not simulated: %v", test.name, validation.Errors)
+			}
+		} else {
+			if validation.CanPropose || !hasValidationError(validation, test.err) {
+				t.Errorf("%s: expected error %q, got %v", test.name, test.err, validation.Errors)
+			}
+			if client.CallCount("upgrade") != calls {
+				t.Errorf("%s: invalid upgrade was simulated", test.name)
+			}
+		}
+	}
+
+}
@@ -0,0 +1,138 @@
+package settings
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Seb369888/poolsea-go/dao"
+	"github.com/Seb369888/poolsea-go/settings"
+	"github.com/Seb369888/poolsea-go/settings/protocol"
+	"github.com/Seb369888/poolsea-go/settings/setting"
+	"github.com/Seb369888/poolsea-go/settings/trustednode"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+func TestRegistry(t *testing.T) {
+
+	// Check each setting is registered once, with a getter and a description
+	seen := map[string]bool{}
+	for _, entry := range settings.Registry {
+		key := entry.ContractName + "/" + entry.Path
+		if seen[key] {
+			t.Errorf("Setting %s is registered more than once", key)
+		}
+		seen[key] = true
+		if entry.Getter == "" || entry.Description == "" {
+			t.Errorf("Setting %s has no getter or description", key)
+		}
+	}
+
+	// Check units
+	units := []struct {
+		setting setting.Setting
+		unit    rptypes.SettingUnit
+	}{
+		{trustednode.QuorumSetting, rptypes.SettingUnitPercent},
+		{trustednode.RPLBondSetting, rptypes.SettingUnitRpl},
+		{trustednode.ScrubPenaltyEnabledSetting, rptypes.SettingUnitNone},
+		{protocol.LotDurationSetting, rptypes.SettingUnitBlocks},
+		{protocol.MinimumDepositSetting, rptypes.SettingUnitEth},
+		{protocol.MinipoolLaunchTimeoutSetting, rptypes.SettingUnitSeconds},
+	}
+	for _, test := range units {
+		if test.setting.Unit != test.unit {
+			t.Errorf("Setting %s has unit %s, expected %s", test.setting.Path, test.setting.Unit, test.unit)
+		}
+	}
+
+	// Every setting registers its unit with the proposal payload decoder
+	for _, entry := range settings.Registry {
+		if unit := dao.GetSettingUnit(entry.ContractName, entry.Path); unit != entry.Unit {
+			t.Errorf("Setting %s registered unit %s, expected %s", entry.Path, unit, entry.Unit)
+		}
+	}
+	custom := setting.TrustedNodeUint("poolseaDAONodeTrustedSettingsTest", "test.duration", "getTestDuration", "test duration", rptypes.SettingUnitSeconds, nil, nil)
+	if unit := dao.GetSettingUnit(custom.ContractName, custom.Path); unit != rptypes.SettingUnitSeconds {
+		t.Errorf("Incorrect unit %s registered for a new setting", unit)
+	}
+
+	// Check lookups
+	if entry, exists := settings.GetSetting(protocol.NetworkSettingsContractName, protocol.NodeConsensusThresholdSettingPath); !exists {
+		t.Error("Consensus threshold setting not found")
+	} else if entry.DAO != setting.DAOProtocol || entry.Getter != "getNodeConsensusThreshold" {
+		t.Errorf("Incorrect consensus threshold setting %+v", entry)
+	}
+	if _, exists := settings.GetSetting(protocol.NetworkSettingsContractName, trustednode.QuorumSettingPath); exists {
+		t.Error("Setting found under the wrong contract")
+	}
+	if len(settings.GetDAOSettings(setting.DAOProtocol))+len(settings.GetDAOSettings(setting.DAOTrustedNode)) != len(settings.Registry) {
+		t.Error("Incorrect DAO setting counts")
+	}
+
+}
+
+func TestSettingValidation(t *testing.T) {
+
+	quorum, exists := settings.GetSetting(trustednode.MembersSettingsContractName, trustednode.QuorumSettingPath)
+	if !exists {
+		t.Fatal("Quorum setting not found")
+	}
+	if err := quorum.Validate(big.NewInt(51e16)); err != nil {
+		t.Errorf("Valid quorum rejected: %s", err)
+	}
+	if err := quorum.Validate(big.NewInt(0)); err == nil {
+		t.Error("Zero quorum accepted")
+	}
+	if err := quorum.Validate(big.NewInt(95e16)); err == nil {
+		t.Error("Quorum above its maximum accepted")
+	}
+	if err := quorum.Validate(true); err == nil {
+		t.Error("Bool value accepted for uint setting")
+	}
+
+	scrubPenalty, exists := settings.GetSetting(trustednode.MinipoolSettingsContractName, trustednode.ScrubPenaltyEnabledPath)
+	if !exists {
+		t.Fatal("Scrub penalty setting not found")
+	}
+	if err := scrubPenalty.Validate(false); err != nil {
+		t.Errorf("Valid scrub penalty setting rejected: %s", err)
+	}
+	if err := scrubPenalty.Validate(big.NewInt(1)); err == nil {
+		t.Error("Uint value accepted for bool setting")
+	}
+
+}
+
+func TestSettingBounds(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		setting setting.Setting
+		value   *big.Int
+		valid   bool
+	}{
+		{"quorum below minimum", trustednode.QuorumSetting, big.NewInt(51e16 - 1), false},
+		{"quorum at minimum", trustednode.QuorumSetting, big.NewInt(51e16), true},
+		{"quorum at maximum", trustednode.QuorumSetting, big.NewInt(75e16), true},
+		{"quorum above maximum", trustednode.QuorumSetting, big.NewInt(75e16 + 1), false},
+		{"consensus threshold at 50%", protocol.NodeConsensusThresholdSetting, big.NewInt(5e17), false},
+		{"consensus threshold below minimum", protocol.NodeConsensusThresholdSetting, big.NewInt(51e16 - 1), false},
+		{"consensus threshold at minimum", protocol.NodeConsensusThresholdSetting, big.NewInt(51e16), true},
+		{"consensus threshold at 100%", protocol.NodeConsensusThresholdSetting, big.NewInt(1e18), true},
+		{"node fee below minimum", protocol.MinimumNodeFeeSetting, big.NewInt(5e16 - 1), false},
+		{"node fee at minimum", protocol.TargetNodeFeeSetting, big.NewInt(5e16), true},
+		{"node fee at maximum", protocol.TargetNodeFeeSetting, big.NewInt(2e17), true},
+		{"node fee above maximum", protocol.MaximumNodeFeeSetting, big.NewInt(2e17 + 1), false},
+		{"unbounded setting", trustednode.RPLBondSetting, big.NewInt(0), true},
+		{"negative value", trustednode.RPLBondSetting, big.NewInt(-1), false},
+	}
+	for _, test := range tests {
+		err := test.setting.Validate(test.value)
+		if test.valid && err != nil {
+			t.Errorf("%s: valid value %s rejected: %s", test.name, test.value, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: invalid value %s accepted", test.name, test.value)
+		}
+	}
+
+}
@@ -60,16 +60,24 @@ func CreateSlashedRPL(t *testing.T, rp *rocketpool.RocketPool, ownerAccount *acc
 	}
 
 	// Mark minipool as withdrawable with zero end balance
-	if _, err := minipool.SubmitMinipoolWithdrawable(rp, mp.Address, trustedNodeAccount.GetTransactor()); err != nil {
+	if _, err := minipool.SubmitMinipoolWithdrawable(rp, mp.GetAddress(), trustedNodeAccount.GetTransactor()); err != nil {
 		return err
 	}
-	if _, err := minipool.SubmitMinipoolWithdrawable(rp, mp.Address, trustedNodeAccount2.GetTransactor()); err != nil {
+	if _, err := minipool.SubmitMinipoolWithdrawable(rp, mp.GetAddress(), trustedNodeAccount2.GetTransactor()); err != nil {
 		return err
 	}
 
 	// Distribute balance and finalise pool to send slashed RPL to auction contract
-	if _, err := mp.DistributeBalanceAndFinalise(trustedNodeAccount.GetTransactor()); err != nil {
-		return err
+	if mpv3, ok := minipool.GetMinipoolAsV3(mp); ok {
+		if _, err := mpv3.DistributeBalance(false, trustedNodeAccount.GetTransactor()); err != nil {
+			return err
+		}
+	} else if mpv2, ok := minipool.GetMinipoolAsV2(mp); ok {
+		if _, err := mpv2.DistributeBalanceAndFinalise(trustedNodeAccount.GetTransactor()); err != nil {
+			return err
+		}
+	} else {
+		return fmt.Errorf("Minipool %s has unsupported version %d", mp.GetAddress().Hex(), mp.GetVersion())
 	}
 
 	// Return
@@ -0,0 +1,259 @@
+package fakeclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Handles a call to a fake contract method, returning its outputs
+type Handler func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error)
+
+// A contract call made through the fake client
+type Call struct {
+	To          common.Address
+	Method      string
+	BlockNumber *big.Int
+}
+
+// An in-memory execution client for testing code that talks to the chain without a node.
+// Contracts are registered with an ABI and a handler; logs are filtered from a fixed list.
+type Client struct {
+	LatestBlock  uint64
+	BlockTime    uint64
+	Logs         []types.Log
+	GasEstimate  uint64
+	Nonce        uint64
+	LogQueries   []ethereum.FilterQuery
+	Calls        []Call
+	Transactions []*types.Transaction
+
+	contracts map[common.Address]fakeContract
+	code      map[common.Address][]byte
+	lock      sync.Mutex
+}
+type fakeContract struct {
+	abi     abi.ABI
+	handler Handler
+}
+
+// Create a new fake client
+func NewClient(latestBlock uint64) *Client {
+	return &Client{
+		LatestBlock: latestBlock,
+		GasEstimate: 100000,
+		contracts:   make(map[common.Address]fakeContract),
+		code:        make(map[common.Address][]byte),
+	}
+}
+
+// Register a contract at an address
+func (c *Client) AddContract(address common.Address, contractAbi string, handler Handler) error {
+	parsed, err := abi.JSON(strings.NewReader(contractAbi))
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.contracts[address] = fakeContract{abi: parsed, handler: handler}
+	c.code[address] = []byte{0x60, 0x80}
+	return nil
+}
+
+// Set the code at an address
+func (c *Client) SetCode(address common.Address, code []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.code[address] = code
+}
+
+// Get the number of contract calls made to a method
+func (c *Client) CallCount(method string) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	count := 0
+	for _, call := range c.Calls {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+func (c *Client) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.code[contract], nil
+}
+
+func (c *Client) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if call.To == nil {
+		return nil, errors.New("fake client does not support contract creation")
+	}
+	c.lock.Lock()
+	contract, exists := c.contracts[*call.To]
+	c.lock.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("no contract at %s", call.To.Hex())
+	}
+	if len(call.Data) < 4 {
+		return nil, errors.New("call data is too short")
+	}
+	method, err := contract.abi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	c.Calls = append(c.Calls, Call{To: *call.To, Method: method.Name, BlockNumber: blockNumber})
+	c.lock.Unlock()
+	outputs, err := contract.handler(method.Name, args, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(outputs...)
+}
+
+func (c *Client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return c.HeaderByNumber(ctx, nil)
+}
+
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if number == nil {
+		number = new(big.Int).SetUint64(c.LatestBlock)
+	}
+	return &types.Header{Number: number, Time: c.BlockTime, BaseFee: big.NewInt(1e9)}, nil
+}
+
+func (c *Client) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return c.CodeAt(ctx, account, nil)
+}
+
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.Nonce, nil
+}
+
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1e9), nil
+}
+
+func (c *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1e9), nil
+}
+
+func (c *Client) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.GasEstimate, nil
+}
+
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.Transactions = append(c.Transactions, tx)
+	if tx.Nonce() >= c.Nonce {
+		c.Nonce = tx.Nonce() + 1
+	}
+	return nil
+}
+
+func (c *Client) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.LogQueries = append(c.LogQueries, query)
+	logs := []types.Log{}
+	for _, log := range c.Logs {
+		if query.FromBlock != nil && log.BlockNumber < query.FromBlock.Uint64() {
+			continue
+		}
+		if query.ToBlock != nil && log.BlockNumber > query.ToBlock.Uint64() {
+			continue
+		}
+		if len(query.Addresses) > 0 && !containsAddress(query.Addresses, log.Address) {
+			continue
+		}
+		if !matchesTopics(query.Topics, log.Topics) {
+			continue
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+func (c *Client) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("fake client does not support subscriptions")
+}
+
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.LatestBlock, nil
+}
+
+func (c *Client) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (c *Client) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	return nil, false, ethereum.NotFound
+}
+
+func (c *Client) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return c.PendingNonceAt(ctx, account)
+}
+
+func (c *Client) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) {
+	return nil, nil
+}
+
+// Check if an address is in a list
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if a log's topics match a topic filter
+func matchesTopics(filter [][]common.Hash, topics []common.Hash) bool {
+	for i, options := range filter {
+		if len(options) == 0 {
+			continue
+		}
+		if i >= len(topics) {
+			return false
+		}
+		matched := false
+		for _, option := range options {
+			if option == topics[i] {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
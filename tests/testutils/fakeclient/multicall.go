@@ -0,0 +1,44 @@
+package fakeclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/utils/multicall"
+)
+
+// A multicall result, as tryAggregate returns it
+type multicallResult struct {
+	Success    bool   `abi:"success"`
+	ReturnData []byte `abi:"returnData"`
+}
+
+// Register a fake multicall contract that runs each call against the client's other contracts
+func (c *Client) AddMulticall(address common.Address) error {
+	return c.AddContract(address, multicall.MulticallABI, func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+		if method != "tryAggregate" {
+			return nil, fmt.Errorf("fake multicall does not support %s", method)
+		}
+		requireSuccess := args[0].(bool)
+		calls := reflect.ValueOf(args[1])
+		results := make([]multicallResult, calls.Len())
+		for i := range results {
+			call := calls.Index(i)
+			target := call.FieldByName("Target").Interface().(common.Address)
+			returnData, err := c.CallContract(context.Background(), ethereum.CallMsg{To: &target, Data: call.FieldByName("CallData").Bytes()}, blockNumber)
+			if err != nil {
+				if requireSuccess {
+					return nil, fmt.Errorf("multicall %d failed: %w", i, err)
+				}
+				continue
+			}
+			results[i] = multicallResult{Success: true, ReturnData: returnData}
+		}
+		return []interface{}{results}, nil
+	})
+}
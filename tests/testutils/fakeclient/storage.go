@@ -0,0 +1,100 @@
+package fakeclient
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Seb369888/poolsea-go/contracts"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+)
+
+// A fake RocketStorage contract; every value can change at a block, so historical reads can be tested
+type Storage struct {
+	// Calls on blocks before this one fail, like a node without archive state
+	PrunedBefore uint64
+
+	values map[common.Hash][]storageValue
+}
+type storageValue struct {
+	block uint64
+	value interface{}
+}
+
+// Register a fake RocketStorage contract on the client
+func (c *Client) AddStorage(address common.Address) (*Storage, error) {
+	s := &Storage{values: make(map[common.Hash][]storageValue)}
+	if err := c.AddContract(address, contracts.RocketStorageABI, s.handle); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Set a value from a block onwards
+func (s *Storage) Set(key common.Hash, block uint64, value interface{}) {
+	s.values[key] = append(s.values[key], storageValue{block: block, value: value})
+}
+
+// Register a network contract from a block onwards
+func (s *Storage) SetContract(name string, block uint64, address common.Address, contractAbi string) error {
+	encodedAbi, err := rocketpool.EncodeAbiStr(contractAbi)
+	if err != nil {
+		return err
+	}
+	s.Set(crypto.Keccak256Hash([]byte("contract.address"), []byte(name)), block, address)
+	s.Set(crypto.Keccak256Hash([]byte("contract.abi"), []byte(name)), block, encodedAbi)
+	return nil
+}
+
+// Get the value of a key on a block (nil for the latest)
+func (s *Storage) get(key common.Hash, blockNumber *big.Int) (interface{}, bool) {
+	var value interface{}
+	found := false
+	for _, v := range s.values[key] {
+		if blockNumber != nil && v.block > blockNumber.Uint64() {
+			break
+		}
+		value = v.value
+		found = true
+	}
+	return value, found
+}
+
+// Handle a RocketStorage call
+func (s *Storage) handle(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+	if blockNumber != nil && blockNumber.Uint64() < s.PrunedBefore {
+		return nil, fmt.Errorf("missing trie node for block %s", blockNumber.String())
+	}
+	if len(args) != 1 {
+		return nil, fmt.Errorf("unsupported RocketStorage method %s", method)
+	}
+	key := common.Hash(args[0].([32]byte))
+	value, found := s.get(key, blockNumber)
+	switch method {
+	case "getAddress":
+		if !found {
+			value = common.Address{}
+		}
+	case "getString":
+		if !found {
+			value = ""
+		}
+	case "getUint", "getInt":
+		if !found {
+			value = big.NewInt(0)
+		}
+	case "getBool":
+		if !found {
+			value = false
+		}
+	case "getBytes32":
+		if !found {
+			value = [32]byte{}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported RocketStorage method %s", method)
+	}
+	return []interface{}{value}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/Seb369888/poolsea-go/types"
+	"github.com/Seb369888/poolsea-go/utils/beacon"
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/Seb369888/poolsea-go/tests"
@@ -12,14 +13,6 @@ import (
 // Deposit settings
 const depositAmount = 16000000000 // gwei
 
-// Deposit data
-type depositData struct {
-	PublicKey             []byte `ssz-size:"48"`
-	WithdrawalCredentials []byte `ssz-size:"32"`
-	Amount                uint64
-	Signature             []byte `ssz-size:"96"`
-}
-
 // Get the validator pubkey
 func GetValidatorPubkey(pubkey int) (types.ValidatorPubkey, error) {
 	if pubkey == 1 {
@@ -48,10 +41,10 @@ func GetValidatorSignature(pubkey int) (types.ValidatorSignature, error) {
 
 // Get the validator deposit depositDataRoot
 func GetDepositDataRoot(validatorPubkey types.ValidatorPubkey, withdrawalCredentials common.Hash, validatorSignature types.ValidatorSignature) (common.Hash, error) {
-	return ssz.HashTreeRoot(depositData{
-		PublicKey:             validatorPubkey.Bytes(),
-		WithdrawalCredentials: withdrawalCredentials[:],
+	return beacon.DepositData{
+		Pubkey:                validatorPubkey,
+		WithdrawalCredentials: withdrawalCredentials,
 		Amount:                depositAmount,
-		Signature:             validatorSignature.Bytes(),
-	})
+		Signature:             validatorSignature,
+	}.HashTreeRoot(), nil
 }
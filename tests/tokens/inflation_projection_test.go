@@ -0,0 +1,115 @@
+package tokens
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Seb369888/poolsea-go/tokens"
+	"github.com/Seb369888/poolsea-go/utils/eth"
+)
+
+func TestInflationProjection(t *testing.T) {
+
+	// 10% inflation per day, last minted at the start time
+	start := time.Unix(1600000000, 0)
+	schedule := tokens.InflationSchedule{
+		TotalSupply:  eth.EthToWei(1000),
+		IntervalRate: eth.EthToWei(1.1),
+		IntervalTime: 24 * time.Hour,
+		StartTime:    start,
+		CalcTime:     start,
+	}
+
+	// Check intervals passed and the pending amount
+	if passed := schedule.IntervalsPassed(start.Add(-time.Hour)); passed != 0 {
+		t.Errorf("Incorrect intervals passed before the start %d", passed)
+	}
+	if pending := schedule.PendingInflation(start.Add(23 * time.Hour)); pending.Sign() != 0 {
+		t.Errorf("Incorrect pending inflation within the first interval %s", pending)
+	}
+	at := start.Add(50 * time.Hour)
+	if passed := schedule.IntervalsPassed(at); passed != 2 {
+		t.Errorf("Incorrect intervals passed %d", passed)
+	}
+	if pending := schedule.PendingInflation(at); pending.Cmp(eth.EthToWei(210)) != 0 {
+		t.Errorf("Incorrect pending inflation %s", pending)
+	}
+
+	// Inflation that hasn't started is never pending
+	notStarted := schedule
+	notStarted.CalcTime = time.Time{}
+	if pending := notStarted.PendingInflation(at); pending.Sign() != 0 {
+		t.Errorf("Incorrect pending inflation before inflation started %s", pending)
+	}
+
+	// Check each interval compounds on the last and is split between the claimers
+	split := tokens.InflationSplit{
+		NodeOperatorPerc: eth.EthToWei(0.7),
+		TrustedNodePerc:  eth.EthToWei(0.2),
+		ProtocolDaoPerc:  eth.EthToWei(0.1),
+	}
+	intervals := schedule.Project(at, split)
+	if len(intervals) != 2 {
+		t.Fatalf("Incorrect interval count %d", len(intervals))
+	}
+	first, second := intervals[0], intervals[1]
+	if first.Minted.Cmp(eth.EthToWei(100)) != 0 || !first.EndTime.Equal(start.Add(24*time.Hour)) {
+		t.Errorf("Incorrect first interval: minted %s, ending %s", first.Minted, first.EndTime)
+	}
+	if first.NodeOperator.Cmp(eth.EthToWei(70)) != 0 || first.TrustedNode.Cmp(eth.EthToWei(20)) != 0 || first.ProtocolDao.Cmp(eth.EthToWei(10)) != 0 {
+		t.Errorf("Incorrect first interval split: %s, %s, %s", first.NodeOperator, first.TrustedNode, first.ProtocolDao)
+	}
+	if second.TotalSupply.Cmp(eth.EthToWei(1100)) != 0 || second.Minted.Cmp(eth.EthToWei(110)) != 0 {
+		t.Errorf("Incorrect second interval: supply %s, minted %s", second.TotalSupply, second.Minted)
+	}
+
+}
+
+func TestInflationProjectionRounding(t *testing.T) {
+
+	// A non-round rate and supply, where compounding the supply each interval would differ from the RPL token by some wei
+	start := time.Unix(1600000000, 0)
+	supply, _ := new(big.Int).SetString("1234567890123456789012", 10)
+	rate, _ := new(big.Int).SetString("1000133680617119386", 10)
+	schedule := tokens.InflationSchedule{
+		TotalSupply:  supply,
+		IntervalRate: rate,
+		IntervalTime: 24 * time.Hour,
+		StartTime:    start,
+		CalcTime:     start,
+	}
+	at := start.Add(3 * 24 * time.Hour)
+
+	// Check the pending amount matches the RPL token's calculation to the wei
+	if pending := schedule.PendingInflation(at); pending.String() != "495179582295578780" {
+		t.Errorf("Incorrect pending inflation %s", pending)
+	}
+	if supplyAt := schedule.SupplyAt(at); supplyAt.String() != "1235063069705752367792" {
+		t.Errorf("Incorrect supply %s", supplyAt)
+	}
+
+	// Check the projected intervals add up to the pending amount
+	split := tokens.InflationSplit{
+		NodeOperatorPerc: eth.EthToWei(0.7),
+		TrustedNodePerc:  eth.EthToWei(0.2),
+		ProtocolDaoPerc:  eth.EthToWei(0.1),
+	}
+	intervals := schedule.Project(at, split)
+	if len(intervals) != 3 {
+		t.Fatalf("Incorrect interval count %d", len(intervals))
+	}
+	for i, expected := range []string{"165037797427482031", "165059859782089644", "165081925086007105"} {
+		if intervals[i].Minted.String() != expected {
+			t.Errorf("Incorrect interval %d minted %s", i, intervals[i].Minted)
+		}
+	}
+	last := intervals[2]
+	if last.TotalSupply.String() != "1234897987780666360687" {
+		t.Errorf("Incorrect last interval supply %s", last.TotalSupply)
+	}
+	if last.NodeOperator.String() != "115557347560204973" || last.TrustedNode.String() != "33016385017201421" || last.ProtocolDao.String() != "16508192508600710" {
+		t.Errorf("Incorrect last interval split: %s, %s, %s", last.NodeOperator, last.TrustedNode, last.ProtocolDao)
+	}
+
+}
@@ -0,0 +1,65 @@
+package beacon
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	blsu "github.com/protolambda/bls12-381-util"
+
+	rptypes "github.com/Seb369888/poolsea-go/types"
+	"github.com/Seb369888/poolsea-go/utils/beacon"
+)
+
+func TestMinipoolDepositData(t *testing.T) {
+
+	// Create a signer for a test validator key
+	var secretKeyBytes [32]byte
+	secretKeyBytes[31] = 3
+	var secretKey blsu.SecretKey
+	if err := secretKey.Deserialize(&secretKeyBytes); err != nil {
+		t.Fatal(err)
+	}
+	pk, err := blsu.SkToPk(&secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey := rptypes.ValidatorPubkey(pk.Serialize())
+	signer := func(signingRoot common.Hash) (rptypes.ValidatorSignature, error) {
+		return rptypes.ValidatorSignature(blsu.Sign(&secretKey, signingRoot[:]).Serialize()), nil
+	}
+
+	// Check the withdrawal credentials
+	minipoolAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	expectedCredentials := common.HexToHash("0x0100000000000000000000001111111111111111111111111111111111111111")
+	if credentials := beacon.GetMinipoolWithdrawalCredentials(minipoolAddress); credentials != expectedCredentials {
+		t.Errorf("Incorrect withdrawal credentials %s", credentials.Hex())
+	}
+
+	// Sign the stake deposit and rebuild it from its signature
+	data, err := beacon.SignStakeDepositData(minipoolAddress, pubkey, signer, beacon.MainnetGenesisForkVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Amount != beacon.StakeDepositAmount {
+		t.Errorf("Incorrect stake deposit amount %d", data.Amount)
+	}
+	rebuilt, err := beacon.GetStakeDepositData(minipoolAddress, pubkey, data.Signature, beacon.MainnetGenesisForkVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt.HashTreeRoot() != data.HashTreeRoot() {
+		t.Error("Rebuilt deposit data has a different root")
+	}
+
+	// The signature is only valid for the amount, credentials and network it was made for
+	if _, err := beacon.NewDepositData(pubkey, expectedCredentials, beacon.PrelaunchDepositAmount, data.Signature, beacon.MainnetGenesisForkVersion); err == nil {
+		t.Error("Signature was accepted for a different amount")
+	}
+	if _, err := beacon.NewDepositData(pubkey, common.Hash{}, beacon.StakeDepositAmount, data.Signature, beacon.MainnetGenesisForkVersion); err == nil {
+		t.Error("Signature was accepted for different withdrawal credentials")
+	}
+	if _, err := beacon.GetStakeDepositData(minipoolAddress, pubkey, data.Signature, beacon.HoleskyGenesisForkVersion); err == nil {
+		t.Error("Signature was accepted for a different network")
+	}
+
+}
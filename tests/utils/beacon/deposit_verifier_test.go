@@ -0,0 +1,102 @@
+package beacon
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	blsu "github.com/protolambda/bls12-381-util"
+
+	rptypes "github.com/Seb369888/poolsea-go/types"
+	"github.com/Seb369888/poolsea-go/utils"
+	"github.com/Seb369888/poolsea-go/utils/beacon"
+)
+
+// Create a signed deposit for a test validator key
+func createDeposit(t *testing.T, key byte, withdrawalCredentials common.Hash, amount uint64) utils.DepositData {
+	var secretKeyBytes [32]byte
+	secretKeyBytes[31] = key
+	var secretKey blsu.SecretKey
+	if err := secretKey.Deserialize(&secretKeyBytes); err != nil {
+		t.Fatal(err)
+	}
+	pubkey, err := blsu.SkToPk(&secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := beacon.DepositMessage{
+		Pubkey:                rptypes.ValidatorPubkey(pubkey.Serialize()),
+		WithdrawalCredentials: withdrawalCredentials,
+		Amount:                amount,
+	}
+	signingRoot := beacon.GetDepositSigningRoot(message, beacon.MainnetGenesisForkVersion)
+	signature := blsu.Sign(&secretKey, signingRoot[:])
+
+	return utils.DepositData{
+		Pubkey:                message.Pubkey,
+		WithdrawalCredentials: message.WithdrawalCredentials,
+		Amount:                message.Amount,
+		Signature:             rptypes.ValidatorSignature(signature.Serialize()),
+	}
+}
+
+func TestDepositDomain(t *testing.T) {
+	expectedDomain := common.HexToHash("0x03000000f5a5fd42d16a20302798ef6ed309979b43003d2320d9f0e8ea9831a9")
+	if domain := beacon.GetDepositDomain(beacon.MainnetGenesisForkVersion); domain != expectedDomain {
+		t.Errorf("Incorrect mainnet deposit domain %s", domain.Hex())
+	}
+}
+
+func TestVerifyMinipoolDeposits(t *testing.T) {
+
+	minipoolAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	minipoolCredentials := common.HexToHash("0x0100000000000000000000001111111111111111111111111111111111111111")
+	foreignCredentials := common.HexToHash("0x0100000000000000000000002222222222222222222222222222222222222222")
+
+	// A valid deposit followed by a top-up from someone else
+	ownDeposit := createDeposit(t, 1, minipoolCredentials, 1e9)
+	foreignDeposit := createDeposit(t, 1, foreignCredentials, 1e9)
+	verification := beacon.VerifyMinipoolDeposits(minipoolAddress, ownDeposit.Pubkey, minipoolCredentials, []utils.DepositData{ownDeposit, foreignDeposit}, beacon.MainnetGenesisForkVersion)
+	if !verification.IsValid() {
+		t.Error("Valid deposit was not accepted")
+	}
+	if verification.IsFrontRun {
+		t.Error("Deposit was flagged as front-run")
+	}
+	if !verification.HasForeignDeposits {
+		t.Error("Foreign deposit was not flagged")
+	}
+	if !verification.Deposits[0].SignatureValid || !verification.Deposits[1].SignatureValid {
+		t.Errorf("Valid signature was rejected: %s %s", verification.Deposits[0].SignatureError, verification.Deposits[1].SignatureError)
+	}
+
+	// A front-running deposit
+	verification = beacon.VerifyMinipoolDeposits(minipoolAddress, ownDeposit.Pubkey, minipoolCredentials, []utils.DepositData{foreignDeposit, ownDeposit}, beacon.MainnetGenesisForkVersion)
+	if !verification.IsFrontRun {
+		t.Error("Front-running deposit was not flagged")
+	}
+	if verification.IsValid() {
+		t.Error("Front-run deposit was accepted")
+	}
+
+	// A front-running deposit with an invalid signature is ignored by the Beacon Chain
+	forgedDeposit := foreignDeposit
+	forgedDeposit.Signature = createDeposit(t, 2, foreignCredentials, 1e9).Signature
+	verification = beacon.VerifyMinipoolDeposits(minipoolAddress, ownDeposit.Pubkey, minipoolCredentials, []utils.DepositData{forgedDeposit, ownDeposit}, beacon.MainnetGenesisForkVersion)
+	if verification.IsFrontRun {
+		t.Error("Deposit with an invalid signature was flagged as front-running")
+	}
+	if !verification.HasInvalidSignatures {
+		t.Error("Invalid signature was not flagged")
+	}
+	if !verification.IsValid() {
+		t.Error("Valid deposit was not accepted")
+	}
+
+	// A deposit signed for a different network
+	verification = beacon.VerifyMinipoolDeposits(minipoolAddress, ownDeposit.Pubkey, minipoolCredentials, []utils.DepositData{ownDeposit}, beacon.PraterGenesisForkVersion)
+	if verification.IsValid() {
+		t.Error("Deposit for a different network was accepted")
+	}
+
+}
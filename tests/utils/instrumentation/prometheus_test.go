@@ -0,0 +1,180 @@
+package instrumentation
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	"github.com/Seb369888/poolsea-go/utils/instrumentation"
+	"github.com/Seb369888/poolsea-go/utils/multicall"
+
+	"github.com/Seb369888/poolsea-go/tests/testutils/fakeclient"
+)
+
+const prometheusTestCounters = `
+# HELP test_rpc_errors_total The number of contract calls and execution client requests that failed
+# TYPE test_rpc_errors_total counter
+test_rpc_errors_total{contract="poolseaTest",kind="call",method="getValue"} 1
+# HELP test_rpc_requests_total The number of contract calls and execution client requests
+# TYPE test_rpc_requests_total counter
+test_rpc_requests_total{contract="",kind="client",method="eth_blockNumber"} 1
+test_rpc_requests_total{contract="poolseaTest",kind="call",method="getValue"} 3
+`
+
+func TestPrometheusInstrumentation(t *testing.T) {
+
+	// The collector must describe every metric it collects
+	p := instrumentation.NewPrometheusInstrumentation("test")
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(p); err != nil {
+		t.Fatal(err)
+	}
+
+	// Record some calls, one of which fails
+	call := rocketpool.Operation{Kind: rocketpool.OperationContractCall, Contract: "poolseaTest", Method: "getValue"}
+	for _, err := range []error{nil, errors.New("reverted"), nil} {
+		_, finish := p.Begin(context.Background(), call)
+		finish(err)
+	}
+	_, finish := p.Begin(context.Background(), rocketpool.Operation{Kind: rocketpool.OperationClient, Method: "eth_blockNumber"})
+	finish(nil)
+
+	// Check the counters and latencies
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(prometheusTestCounters), "test_rpc_requests_total", "test_rpc_errors_total"); err != nil {
+		t.Error(err)
+	}
+	if count := testutil.CollectAndCount(p, "test_rpc_request_duration_seconds"); count != 2 {
+		t.Errorf("Incorrect latency series count %d", count)
+	}
+	if count, sum := getBatchSizes(t, registry); count != 0 || sum != 0 {
+		t.Errorf("Batch sizes were recorded for regular calls (%d, %f)", count, sum)
+	}
+
+}
+
+func TestMulticallBatchSizes(t *testing.T) {
+
+	p := instrumentation.NewPrometheusInstrumentation("test")
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(p); err != nil {
+		t.Fatal(err)
+	}
+	rocketpool.SetInstrumentation(p)
+	t.Cleanup(func() { rocketpool.SetInstrumentation(nil) })
+
+	// Set up the balance batcher and multicall contracts
+	client := fakeclient.NewClient(1000)
+	balancesAddress := common.HexToAddress("0x5000000000000000000000000000000000000001")
+	multicallAddress := common.HexToAddress("0x5000000000000000000000000000000000000002")
+	if err := client.AddContract(balancesAddress, multicall.BalancesABI, func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+		users := args[0].([]common.Address)
+		balances := make([]*big.Int, len(users))
+		for i := range balances {
+			balances[i] = big.NewInt(int64(i))
+		}
+		return []interface{}{balances}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AddContract(multicallAddress, multicall.MulticallABI, func(method string, args []interface{}, blockNumber *big.Int) ([]interface{}, error) {
+		results := make([]struct {
+			Success    bool   `abi:"success"`
+			ReturnData []byte `abi:"returnData"`
+		}, reflect.ValueOf(args[1]).Len())
+		return []interface{}{results}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Balances are loaded in batches of 1000
+	batcher, err := multicall.NewBalanceBatcher(client, balancesAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses := make([]common.Address, 2500)
+	balances, err := batcher.GetEthBalances(addresses, &bind.CallOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != len(addresses) {
+		t.Fatalf("Incorrect balance count %d", len(balances))
+	}
+
+	// Multicalls are one batch of every call added
+	caller, err := multicall.NewMultiCaller(client, multicallAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testAbi, err := abi.JSON(strings.NewReader(`[{"type":"function","name":"getValue","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := &rocketpool.Contract{Address: &balancesAddress, ABI: &testAbi, Name: "poolseaTest"}
+	for i := 0; i < 3; i++ {
+		if err := caller.AddCall(contract, new(*big.Int), "getValue"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := caller.Execute(false, &bind.CallOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the batch sizes: 1000, 1000 and 500 balances, then 3 calls
+	if count, sum := getBatchSizes(t, registry); count != 4 || sum != 2503 {
+		t.Errorf("Incorrect batch sizes: %d batches, %f calls", count, sum)
+	}
+	if count := getMulticallRequests(t, registry, "balanceBatcher", "balances"); count != 3 {
+		t.Errorf("Incorrect balance batch count %f", count)
+	}
+
+}
+
+// Get the number of multicall batches recorded and their total size
+func getBatchSizes(t *testing.T, registry *prometheus.Registry) (uint64, float64) {
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "test_rpc_multicall_batch_size" {
+			continue
+		}
+		histogram := family.GetMetric()[0].GetHistogram()
+		return histogram.GetSampleCount(), histogram.GetSampleSum()
+	}
+	t.Fatal("Batch size histogram was not collected")
+	return 0, 0
+}
+
+// Get the number of requests recorded for a multicall contract method
+func getMulticallRequests(t *testing.T, registry *prometheus.Registry, contract string, method string) float64 {
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "test_rpc_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["kind"] == string(rocketpool.OperationMulticall) && labels["contract"] == contract && labels["method"] == method {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
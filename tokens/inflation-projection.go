@@ -0,0 +1,144 @@
+package tokens
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+)
+
+// Fixed point base for the inflation rate and rewards percentages
+var inflationCalcBase = big.NewInt(1e18)
+
+// The state of RPL inflation needed to project it
+type InflationSchedule struct {
+	TotalSupply  *big.Int      `json:"totalSupply"`
+	IntervalRate *big.Int      `json:"intervalRate"` // Where 1e18 = no inflation
+	IntervalTime time.Duration `json:"intervalTime"`
+	StartTime    time.Time     `json:"startTime"`
+	CalcTime     time.Time     `json:"calcTime"` // The end of the last minted interval; zero if inflation hasn't started
+}
+
+// The shares of inflation paid to each group of claimers, where 1e18 = 100%
+type InflationSplit struct {
+	NodeOperatorPerc *big.Int `json:"nodeOperatorPerc"`
+	TrustedNodePerc  *big.Int `json:"trustedNodePerc"`
+	ProtocolDaoPerc  *big.Int `json:"protocolDaoPerc"`
+}
+
+// The RPL minted for one inflation interval and its split
+type InflationInterval struct {
+	Index        uint64    `json:"index"` // Counted from the last mint, starting at 1
+	StartTime    time.Time `json:"startTime"`
+	EndTime      time.Time `json:"endTime"`
+	TotalSupply  *big.Int  `json:"totalSupply"` // The supply at the start of the interval, including unminted inflation
+	Minted       *big.Int  `json:"minted"`
+	NodeOperator *big.Int  `json:"nodeOperator"`
+	TrustedNode  *big.Int  `json:"trustedNode"`
+	ProtocolDao  *big.Int  `json:"protocolDao"`
+}
+
+// Get the current RPL inflation schedule
+func GetInflationSchedule(rp *rocketpool.RocketPool, opts *bind.CallOpts) (InflationSchedule, error) {
+
+	// Data
+	var wg errgroup.Group
+	var schedule InflationSchedule
+	var intervalTime uint64
+	var startTime uint64
+	var calcTime uint64
+
+	// Load data
+	wg.Go(func() error {
+		var err error
+		schedule.TotalSupply, err = GetRPLTotalSupply(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		schedule.IntervalRate, err = GetRPLInflationIntervalRate(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		intervalTime, err = GetRPLInflationIntervalTime(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		startTime, err = GetRPLInflationIntervalStartTime(rp, opts)
+		return err
+	})
+	wg.Go(func() error {
+		var err error
+		calcTime, err = GetRPLInflationCalcTime(rp, opts)
+		return err
+	})
+
+	// Wait for data
+	if err := wg.Wait(); err != nil {
+		return InflationSchedule{}, fmt.Errorf("Could not get RPL inflation schedule: %w", err)
+	}
+	schedule.IntervalTime = time.Duration(intervalTime) * time.Second
+	schedule.StartTime = time.Unix(int64(startTime), 0)
+	if calcTime > 0 {
+		schedule.CalcTime = time.Unix(int64(calcTime), 0)
+	}
+	return schedule, nil
+
+}
+
+// Get the number of whole inflation intervals since the last mint at a time
+func (s InflationSchedule) IntervalsPassed(at time.Time) uint64 {
+	if s.CalcTime.IsZero() || s.IntervalTime <= 0 || !at.After(s.CalcTime) {
+		return 0
+	}
+	return uint64(at.Sub(s.CalcTime) / s.IntervalTime)
+}
+
+// Get the RPL that could be minted at a time, if nothing is minted before then
+func (s InflationSchedule) PendingInflation(at time.Time) *big.Int {
+	return CalculateRPLInflation(s.TotalSupply, s.IntervalRate, s.IntervalsPassed(at))
+}
+
+// Get the RPL supply at a time, including inflation that hasn't been minted yet
+func (s InflationSchedule) SupplyAt(at time.Time) *big.Int {
+	return new(big.Int).Add(s.TotalSupply, s.PendingInflation(at))
+}
+
+// Project each inflation interval from the last mint until a time, split between the claimers.
+// The RPL token mints every interval since the last mint at once, so each interval's amount is the increase in that total.
+func (s InflationSchedule) Project(until time.Time, split InflationSplit) []InflationInterval {
+	count := s.IntervalsPassed(until)
+	intervals := make([]InflationInterval, 0, count)
+	pending := big.NewInt(0)
+	for index := uint64(1); index <= count; index++ {
+		next := CalculateRPLInflation(s.TotalSupply, s.IntervalRate, index)
+		interval := InflationInterval{
+			Index:       index,
+			StartTime:   s.CalcTime.Add(time.Duration(index-1) * s.IntervalTime),
+			EndTime:     s.CalcTime.Add(time.Duration(index) * s.IntervalTime),
+			TotalSupply: new(big.Int).Add(s.TotalSupply, pending),
+			Minted:      new(big.Int).Sub(next, pending),
+		}
+		interval.NodeOperator = getInflationShare(interval.Minted, split.NodeOperatorPerc)
+		interval.TrustedNode = getInflationShare(interval.Minted, split.TrustedNodePerc)
+		interval.ProtocolDao = getInflationShare(interval.Minted, split.ProtocolDaoPerc)
+		intervals = append(intervals, interval)
+		pending = next
+	}
+	return intervals
+}
+
+// Get a share of minted RPL
+func getInflationShare(minted *big.Int, perc *big.Int) *big.Int {
+	if perc == nil {
+		return big.NewInt(0)
+	}
+	share := new(big.Int).Mul(minted, perc)
+	return share.Div(share, inflationCalcBase)
+}
@@ -157,6 +157,61 @@ func GetRPLInflationIntervalRate(rp *rocketpool.RocketPool, opts *bind.CallOpts)
 	return *rate, nil
 }
 
+// Get the length of an RPL inflation interval in seconds
+func GetRPLInflationIntervalTime(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
+	rocketTokenRPL, err := getRocketTokenRPL(rp, opts)
+	if err != nil {
+		return 0, err
+	}
+	intervalTime := new(*big.Int)
+	if err := rocketTokenRPL.Call(opts, intervalTime, "getInflationIntervalTime"); err != nil {
+		return 0, fmt.Errorf("Could not get RPL inflation interval time: %w", err)
+	}
+	return (*intervalTime).Uint64(), nil
+}
+
+// Get the time RPL inflation starts at
+func GetRPLInflationIntervalStartTime(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
+	rocketTokenRPL, err := getRocketTokenRPL(rp, opts)
+	if err != nil {
+		return 0, err
+	}
+	startTime := new(*big.Int)
+	if err := rocketTokenRPL.Call(opts, startTime, "getInflationIntervalStartTime"); err != nil {
+		return 0, fmt.Errorf("Could not get RPL inflation start time: %w", err)
+	}
+	return (*startTime).Uint64(), nil
+}
+
+// Get the time RPL inflation was last calculated at; this is the inflation start time if it has never been minted, or 0 if inflation hasn't started
+func GetRPLInflationCalcTime(rp *rocketpool.RocketPool, opts *bind.CallOpts) (uint64, error) {
+	rocketTokenRPL, err := getRocketTokenRPL(rp, opts)
+	if err != nil {
+		return 0, err
+	}
+	calcTime := new(*big.Int)
+	if err := rocketTokenRPL.Call(opts, calcTime, "getInflationCalcTime"); err != nil {
+		return 0, fmt.Errorf("Could not get RPL inflation calculation time: %w", err)
+	}
+	return (*calcTime).Uint64(), nil
+}
+
+// Calculate the RPL minted for a number of inflation intervals, as the RPL token does:
+// the interval rate is compounded over the intervals, rounding down each time, and then applied to the supply once
+func CalculateRPLInflation(supply *big.Int, intervalRate *big.Int, intervals uint64) *big.Int {
+	if intervals == 0 || intervalRate.Cmp(inflationCalcBase) <= 0 {
+		return big.NewInt(0)
+	}
+	rate := new(big.Int).Set(intervalRate)
+	for i := uint64(1); i < intervals; i++ {
+		rate.Mul(rate, intervalRate)
+		rate.Div(rate, inflationCalcBase)
+	}
+	minted := new(big.Int).Mul(supply, rate)
+	minted.Div(minted, inflationCalcBase)
+	return minted.Sub(minted, supply)
+}
+
 //
 // Contracts
 //
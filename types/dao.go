@@ -56,3 +56,16 @@ func (s *ProposalState) UnmarshalJSON(data []byte) error {
 	}
 	return err
 }
+
+// The unit a DAO setting value is expressed in
+type SettingUnit string
+
+const (
+	SettingUnitNone      SettingUnit = ""
+	SettingUnitPercent   SettingUnit = "percent"   // A fraction where 1e18 = 100%
+	SettingUnitEth       SettingUnit = "ETH"       // An amount of ETH in wei
+	SettingUnitRpl       SettingUnit = "RPL"       // An amount of RPL in wei
+	SettingUnitSeconds   SettingUnit = "seconds"   // A duration in seconds
+	SettingUnitBlocks    SettingUnit = "blocks"    // A number of blocks
+	SettingUnitTimestamp SettingUnit = "timestamp" // A unix timestamp in seconds
+)
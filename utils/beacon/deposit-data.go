@@ -0,0 +1,140 @@
+package beacon
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/minipool"
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+// Beacon deposit amounts made by v3 minipools (Gwei)
+const (
+	PrelaunchDepositAmount uint64 = 1e9  // Deposited by node.Deposit and node.DepositWithCredit when the minipool is created
+	StakeDepositAmount     uint64 = 31e9 // Deposited by the minipool's Stake() call
+)
+
+// A function that signs a deposit signing root with a validator key
+type DepositSigner func(signingRoot common.Hash) (rptypes.ValidatorSignature, error)
+
+// Arguments for node.Deposit and node.DepositWithCredit
+type MinipoolDepositArgs struct {
+	Salt                    *big.Int                   `json:"salt"`
+	ExpectedMinipoolAddress common.Address             `json:"expectedMinipoolAddress"`
+	WithdrawalCredentials   common.Hash                `json:"withdrawalCredentials"`
+	ValidatorPubkey         rptypes.ValidatorPubkey    `json:"validatorPubkey"`
+	ValidatorSignature      rptypes.ValidatorSignature `json:"validatorSignature"`
+	DepositDataRoot         common.Hash                `json:"depositDataRoot"`
+	DepositData             DepositData                `json:"depositData"`
+}
+
+// Arguments for node.CreateVacantMinipool
+type VacantMinipoolArgs struct {
+	Salt                    *big.Int                `json:"salt"`
+	ExpectedMinipoolAddress common.Address          `json:"expectedMinipoolAddress"`
+	WithdrawalCredentials   common.Hash             `json:"withdrawalCredentials"`
+	ValidatorPubkey         rptypes.ValidatorPubkey `json:"validatorPubkey"`
+}
+
+// Get the withdrawal credentials for a minipool: 0x01, 11 empty bytes, then the minipool address
+func GetMinipoolWithdrawalCredentials(minipoolAddress common.Address) common.Hash {
+	return minipool.CalculateMinipoolWithdrawalCredentials(minipoolAddress)
+}
+
+// Create deposit data from an existing signature, verifying the signature against the deposit message
+func NewDepositData(pubkey rptypes.ValidatorPubkey, withdrawalCredentials common.Hash, amount uint64, signature rptypes.ValidatorSignature, genesisForkVersion [4]byte) (DepositData, error) {
+	data := DepositData{
+		Pubkey:                pubkey,
+		WithdrawalCredentials: withdrawalCredentials,
+		Amount:                amount,
+		Signature:             signature,
+	}
+	if err := VerifyDepositSignature(data, genesisForkVersion); err != nil {
+		return DepositData{}, err
+	}
+	return data, nil
+}
+
+// Create deposit data by signing the deposit message with the provided signer
+func SignDepositData(pubkey rptypes.ValidatorPubkey, withdrawalCredentials common.Hash, amount uint64, signer DepositSigner, genesisForkVersion [4]byte) (DepositData, error) {
+	message := DepositMessage{
+		Pubkey:                pubkey,
+		WithdrawalCredentials: withdrawalCredentials,
+		Amount:                amount,
+	}
+	signature, err := signer(GetDepositSigningRoot(message, genesisForkVersion))
+	if err != nil {
+		return DepositData{}, fmt.Errorf("Could not sign deposit for validator %s: %w", pubkey.Hex(), err)
+	}
+	return NewDepositData(pubkey, withdrawalCredentials, amount, signature, genesisForkVersion)
+}
+
+// Get the arguments for a node deposit from an existing signature over the minipool's prelaunch deposit
+func GetMinipoolDepositArgs(rp *rocketpool.RocketPool, nodeAddress common.Address, salt *big.Int, pubkey rptypes.ValidatorPubkey, signature rptypes.ValidatorSignature, genesisForkVersion [4]byte, opts *bind.CallOpts) (MinipoolDepositArgs, error) {
+	return getMinipoolDepositArgs(rp, nodeAddress, salt, opts, func(withdrawalCredentials common.Hash) (DepositData, error) {
+		return NewDepositData(pubkey, withdrawalCredentials, PrelaunchDepositAmount, signature, genesisForkVersion)
+	})
+}
+
+// Get the arguments for a node deposit, signing the minipool's prelaunch deposit with the provided signer
+func SignMinipoolDepositArgs(rp *rocketpool.RocketPool, nodeAddress common.Address, salt *big.Int, pubkey rptypes.ValidatorPubkey, signer DepositSigner, genesisForkVersion [4]byte, opts *bind.CallOpts) (MinipoolDepositArgs, error) {
+	return getMinipoolDepositArgs(rp, nodeAddress, salt, opts, func(withdrawalCredentials common.Hash) (DepositData, error) {
+		return SignDepositData(pubkey, withdrawalCredentials, PrelaunchDepositAmount, signer, genesisForkVersion)
+	})
+}
+
+// Get the arguments for creating a vacant minipool for an existing validator
+func GetVacantMinipoolArgs(rp *rocketpool.RocketPool, nodeAddress common.Address, salt *big.Int, pubkey rptypes.ValidatorPubkey, opts *bind.CallOpts) (VacantMinipoolArgs, error) {
+	minipoolAddress, err := minipool.GetExpectedAddress(rp, nodeAddress, salt, opts)
+	if err != nil {
+		return VacantMinipoolArgs{}, err
+	}
+	return VacantMinipoolArgs{
+		Salt:                    salt,
+		ExpectedMinipoolAddress: minipoolAddress,
+		WithdrawalCredentials:   GetMinipoolWithdrawalCredentials(minipoolAddress),
+		ValidatorPubkey:         pubkey,
+	}, nil
+}
+
+// Get the signature and deposit data root for a minipool's Stake() call from an existing signature
+func GetStakeDepositData(minipoolAddress common.Address, pubkey rptypes.ValidatorPubkey, signature rptypes.ValidatorSignature, genesisForkVersion [4]byte) (DepositData, error) {
+	return NewDepositData(pubkey, GetMinipoolWithdrawalCredentials(minipoolAddress), StakeDepositAmount, signature, genesisForkVersion)
+}
+
+// Get the signature and deposit data root for a minipool's Stake() call, signing it with the provided signer
+func SignStakeDepositData(minipoolAddress common.Address, pubkey rptypes.ValidatorPubkey, signer DepositSigner, genesisForkVersion [4]byte) (DepositData, error) {
+	return SignDepositData(pubkey, GetMinipoolWithdrawalCredentials(minipoolAddress), StakeDepositAmount, signer, genesisForkVersion)
+}
+
+// Resolve the minipool address for a node deposit and build its deposit data
+func getMinipoolDepositArgs(rp *rocketpool.RocketPool, nodeAddress common.Address, salt *big.Int, opts *bind.CallOpts, createDepositData func(common.Hash) (DepositData, error)) (MinipoolDepositArgs, error) {
+
+	// Get the minipool address and withdrawal credentials
+	minipoolAddress, err := minipool.GetExpectedAddress(rp, nodeAddress, salt, opts)
+	if err != nil {
+		return MinipoolDepositArgs{}, err
+	}
+	withdrawalCredentials := GetMinipoolWithdrawalCredentials(minipoolAddress)
+
+	// Build the deposit data
+	data, err := createDepositData(withdrawalCredentials)
+	if err != nil {
+		return MinipoolDepositArgs{}, fmt.Errorf("Could not create deposit data for minipool %s: %w", minipoolAddress.Hex(), err)
+	}
+
+	return MinipoolDepositArgs{
+		Salt:                    salt,
+		ExpectedMinipoolAddress: minipoolAddress,
+		WithdrawalCredentials:   withdrawalCredentials,
+		ValidatorPubkey:         data.Pubkey,
+		ValidatorSignature:      data.Signature,
+		DepositDataRoot:         data.HashTreeRoot(),
+		DepositData:             data,
+	}, nil
+
+}
@@ -0,0 +1,134 @@
+package beacon
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+	rptypes "github.com/Seb369888/poolsea-go/types"
+	"github.com/Seb369888/poolsea-go/utils"
+	"github.com/Seb369888/poolsea-go/utils/state"
+)
+
+// The result of checking a single Beacon deposit for a minipool's pubkey
+type DepositCheck struct {
+	Deposit                    utils.DepositData `json:"deposit"`
+	WithdrawalCredentialsMatch bool              `json:"withdrawalCredentialsMatch"`
+	SignatureValid             bool              `json:"signatureValid"`
+	SignatureError             string            `json:"signatureError,omitempty"`
+}
+
+// The result of checking all of the Beacon deposits for a minipool's pubkey
+type MinipoolDepositVerification struct {
+	MinipoolAddress               common.Address          `json:"minipoolAddress"`
+	Pubkey                        rptypes.ValidatorPubkey `json:"pubkey"`
+	ExpectedWithdrawalCredentials common.Hash             `json:"expectedWithdrawalCredentials"`
+	Deposits                      []DepositCheck          `json:"deposits"`
+
+	// True if the first deposit with a valid signature has someone else's withdrawal credentials.
+	// The Beacon Chain locks the validator's credentials in on that deposit, so the minipool's funds would be lost.
+	IsFrontRun bool `json:"isFrontRun"`
+
+	// True if any deposit for the pubkey has withdrawal credentials other than the minipool's
+	HasForeignDeposits bool `json:"hasForeignDeposits"`
+
+	// True if any deposit for the pubkey has an invalid signature; these are ignored by the Beacon Chain
+	HasInvalidSignatures bool `json:"hasInvalidSignatures"`
+}
+
+// Check if the minipool has a valid deposit that wasn't front-run
+func (v MinipoolDepositVerification) IsValid() bool {
+	if v.IsFrontRun {
+		return false
+	}
+	for _, deposit := range v.Deposits {
+		if deposit.SignatureValid && deposit.WithdrawalCredentialsMatch {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify the Beacon deposits for all of a node's minipools
+func VerifyNodeMinipoolDeposits(rp *rocketpool.RocketPool, contracts *state.NetworkContracts, nodeAddress common.Address, genesisForkVersion [4]byte, startBlock *big.Int, intervalSize *big.Int) ([]MinipoolDepositVerification, error) {
+
+	// Get the node's minipools
+	minipools, err := state.GetNodeNativeMinipoolDetails(rp, contracts, nodeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("Could not get minipool details for node %s: %w", nodeAddress.Hex(), err)
+	}
+
+	// Get the deposits for their pubkeys
+	pubkeys := make(map[rptypes.ValidatorPubkey]bool, len(minipools))
+	for _, mpd := range minipools {
+		pubkeys[mpd.Pubkey] = true
+	}
+	opts := &bind.CallOpts{
+		BlockNumber: contracts.ElBlockNumber,
+	}
+	deposits, err := utils.GetDeposits(rp, pubkeys, startBlock, intervalSize, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not get Beacon deposits for node %s: %w", nodeAddress.Hex(), err)
+	}
+
+	// Verify them
+	verifications := make([]MinipoolDepositVerification, len(minipools))
+	for i, mpd := range minipools {
+		verifications[i] = VerifyMinipoolDeposits(mpd.MinipoolAddress, mpd.Pubkey, mpd.WithdrawalCredentials, deposits[mpd.Pubkey], genesisForkVersion)
+	}
+	return verifications, nil
+
+}
+
+// Verify the Beacon deposits for a minipool's pubkey.
+// The deposits must be in the order they were made, as returned by utils.GetDeposits.
+func VerifyMinipoolDeposits(minipoolAddress common.Address, pubkey rptypes.ValidatorPubkey, expectedWithdrawalCredentials common.Hash, deposits []utils.DepositData, genesisForkVersion [4]byte) MinipoolDepositVerification {
+
+	verification := MinipoolDepositVerification{
+		MinipoolAddress:               minipoolAddress,
+		Pubkey:                        pubkey,
+		ExpectedWithdrawalCredentials: expectedWithdrawalCredentials,
+		Deposits:                      make([]DepositCheck, len(deposits)),
+	}
+
+	foundFirstValidDeposit := false
+	for i, deposit := range deposits {
+		check := DepositCheck{
+			Deposit:                    deposit,
+			WithdrawalCredentialsMatch: deposit.WithdrawalCredentials == expectedWithdrawalCredentials,
+		}
+
+		// Check the signature
+		err := VerifyDepositSignature(DepositData{
+			Pubkey:                deposit.Pubkey,
+			WithdrawalCredentials: deposit.WithdrawalCredentials,
+			Amount:                deposit.Amount,
+			Signature:             deposit.Signature,
+		}, genesisForkVersion)
+		if err == nil {
+			check.SignatureValid = true
+		} else {
+			check.SignatureError = err.Error()
+			verification.HasInvalidSignatures = true
+		}
+
+		// Check for foreign deposits
+		if !check.WithdrawalCredentialsMatch {
+			verification.HasForeignDeposits = true
+		}
+
+		// The first deposit with a valid signature determines the validator's withdrawal credentials
+		if check.SignatureValid && !foundFirstValidDeposit {
+			foundFirstValidDeposit = true
+			verification.IsFrontRun = !check.WithdrawalCredentialsMatch
+		}
+
+		verification.Deposits[i] = check
+	}
+
+	return verification
+
+}
@@ -0,0 +1,62 @@
+package beacon
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	blsu "github.com/protolambda/bls12-381-util"
+
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+// The domain type for deposits
+var DomainDeposit = [4]byte{0x03, 0x00, 0x00, 0x00}
+
+// Genesis fork versions for the supported networks
+var (
+	MainnetGenesisForkVersion = [4]byte{0x00, 0x00, 0x00, 0x00}
+	PraterGenesisForkVersion  = [4]byte{0x00, 0x00, 0x10, 0x20}
+	HoleskyGenesisForkVersion = [4]byte{0x01, 0x01, 0x70, 0x00}
+)
+
+// Get the signing domain for deposits.
+// Deposits are valid across forks, so they always use the genesis fork version and an empty genesis validators root.
+func GetDepositDomain(genesisForkVersion [4]byte) common.Hash {
+	var forkVersion common.Hash
+	copy(forkVersion[:], genesisForkVersion[:])
+	forkDataRoot := hashPair(forkVersion, common.Hash{})
+
+	var domain common.Hash
+	copy(domain[:4], DomainDeposit[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return domain
+}
+
+// Get the root that a validator key signs for a deposit
+func GetDepositSigningRoot(message DepositMessage, genesisForkVersion [4]byte) common.Hash {
+	return hashPair(message.HashTreeRoot(), GetDepositDomain(genesisForkVersion))
+}
+
+// Verify the BLS signature of a deposit against the deposit domain for the given genesis fork version
+func VerifyDepositSignature(data DepositData, genesisForkVersion [4]byte) error {
+
+	// Deserialize the key and signature
+	pubkeyBytes := [rptypes.ValidatorPubkeyLength]byte(data.Pubkey)
+	var pubkey blsu.Pubkey
+	if err := pubkey.Deserialize(&pubkeyBytes); err != nil {
+		return fmt.Errorf("Invalid validator pubkey %s: %w", data.Pubkey.Hex(), err)
+	}
+	signatureBytes := [rptypes.ValidatorSignatureLength]byte(data.Signature)
+	var signature blsu.Signature
+	if err := signature.Deserialize(&signatureBytes); err != nil {
+		return fmt.Errorf("Invalid deposit signature for validator %s: %w", data.Pubkey.Hex(), err)
+	}
+
+	// Verify the signature
+	signingRoot := GetDepositSigningRoot(data.GetMessage(), genesisForkVersion)
+	if !blsu.Verify(&pubkey, signingRoot[:], &signature) {
+		return fmt.Errorf("Deposit signature for validator %s does not match its deposit message", data.Pubkey.Hex())
+	}
+	return nil
+
+}
@@ -0,0 +1,84 @@
+package beacon
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	rptypes "github.com/Seb369888/poolsea-go/types"
+)
+
+// The SSZ DepositMessage container, which is what a validator key signs when depositing
+type DepositMessage struct {
+	Pubkey                rptypes.ValidatorPubkey `json:"pubkey"`
+	WithdrawalCredentials common.Hash             `json:"withdrawalCredentials"`
+	Amount                uint64                  `json:"amount"` // Gwei
+}
+
+// The SSZ DepositData container, which is what the deposit contract receives
+type DepositData struct {
+	Pubkey                rptypes.ValidatorPubkey    `json:"pubkey"`
+	WithdrawalCredentials common.Hash                `json:"withdrawalCredentials"`
+	Amount                uint64                     `json:"amount"` // Gwei
+	Signature             rptypes.ValidatorSignature `json:"signature"`
+}
+
+// Get the hash tree root of the deposit message
+func (m DepositMessage) HashTreeRoot() common.Hash {
+	return hashPair(
+		hashPair(pubkeyRoot(m.Pubkey), m.WithdrawalCredentials),
+		hashPair(uint64Chunk(m.Amount), common.Hash{}),
+	)
+}
+
+// Get the hash tree root of the deposit data; this is the depositDataRoot the deposit contract expects
+func (d DepositData) HashTreeRoot() common.Hash {
+	return hashPair(
+		hashPair(pubkeyRoot(d.Pubkey), d.WithdrawalCredentials),
+		hashPair(uint64Chunk(d.Amount), signatureRoot(d.Signature)),
+	)
+}
+
+// Get the message portion of the deposit data
+func (d DepositData) GetMessage() DepositMessage {
+	return DepositMessage{
+		Pubkey:                d.Pubkey,
+		WithdrawalCredentials: d.WithdrawalCredentials,
+		Amount:                d.Amount,
+	}
+}
+
+// Get the hash tree root of a 48-byte pubkey
+func pubkeyRoot(pubkey rptypes.ValidatorPubkey) common.Hash {
+	var first, second common.Hash
+	copy(first[:], pubkey[:32])
+	copy(second[:], pubkey[32:])
+	return hashPair(first, second)
+}
+
+// Get the hash tree root of a 96-byte signature
+func signatureRoot(signature rptypes.ValidatorSignature) common.Hash {
+	var first, second, third common.Hash
+	copy(first[:], signature[:32])
+	copy(second[:], signature[32:64])
+	copy(third[:], signature[64:])
+	return hashPair(hashPair(first, second), hashPair(third, common.Hash{}))
+}
+
+// Serialize a uint64 into a little-endian chunk
+func uint64Chunk(value uint64) common.Hash {
+	var chunk common.Hash
+	binary.LittleEndian.PutUint64(chunk[:8], value)
+	return chunk
+}
+
+// Hash two chunks together
+func hashPair(left common.Hash, right common.Hash) common.Hash {
+	hasher := sha256.New()
+	hasher.Write(left[:])
+	hasher.Write(right[:])
+	var root common.Hash
+	copy(root[:], hasher.Sum(nil))
+	return root
+}
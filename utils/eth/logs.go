@@ -19,30 +19,13 @@ type FilterQuery struct {
 	Topics    [][]common.Hash
 }
 
+// Filter the logs of every address a network contract was registered at, up to the block in opts (or the latest block if not set)
 func FilterContractLogs(rp *rocketpool.RocketPool, contractName string, q FilterQuery, intervalSize *big.Int, opts *bind.CallOpts) ([]types.Log, error) {
-	rocketDaoNodeTrustedUpgrade, err := rp.GetContract("poolseaDAONodeTrustedUpgrade", opts)
-	if err != nil {
-		return nil, err
-	}
 	// Get all the addresses this contract has ever been deployed at
-	addresses := make([]common.Address, 0)
-	// Construct a filter to query ContractUpgraded event
-	addressFilter := []common.Address{*rocketDaoNodeTrustedUpgrade.Address}
-	topicFilter := [][]common.Hash{{rocketDaoNodeTrustedUpgrade.ABI.Events["ContractUpgraded"].ID}, {crypto.Keccak256Hash([]byte(contractName))}}
-	logs, err := GetLogs(rp, addressFilter, topicFilter, intervalSize, nil, nil, nil)
-	if err != nil {
-		return nil, err
-	}
-	// Iterate the logs and store every past contract address
-	for _, log := range logs {
-		addresses = append(addresses, common.HexToAddress(log.Topics[2].Hex()))
-	}
-	// Append current address
-	currentAddress, err := rp.GetAddress(contractName, opts)
+	addresses, err := rp.UpgradeHistory.GetContractAddresses(contractName, intervalSize, opts)
 	if err != nil {
 		return nil, err
 	}
-	addresses = append(addresses, *currentAddress)
 	// Perform the desired getLogs call and return results
 	return GetLogs(rp, addresses, q.Topics, intervalSize, q.FromBlock, q.ToBlock, q.BlockHash)
 }
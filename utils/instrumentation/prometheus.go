@@ -0,0 +1,87 @@
+package instrumentation
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+)
+
+// Records contract calls and execution client requests as Prometheus metrics
+type PrometheusInstrumentation struct {
+	requests  *prometheus.CounterVec
+	errors    *prometheus.CounterVec
+	latency   *prometheus.HistogramVec
+	batchSize prometheus.Histogram
+}
+
+// Create a new Prometheus instrumentation; it must be registered with a Prometheus registry to be exported
+func NewPrometheusInstrumentation(namespace string) *PrometheusInstrumentation {
+	labels := []string{"kind", "contract", "method"}
+	return &PrometheusInstrumentation{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "requests_total",
+			Help:      "The number of contract calls and execution client requests",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "errors_total",
+			Help:      "The number of contract calls and execution client requests that failed",
+		}, labels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "request_duration_seconds",
+			Help:      "The duration of contract calls and execution client requests",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		batchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "multicall_batch_size",
+			Help:      "The number of calls in each multicall batch",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		}),
+	}
+}
+
+// Begin an instrumented operation
+func (p *PrometheusInstrumentation) Begin(ctx context.Context, op rocketpool.Operation) (context.Context, func(err error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		labels := prometheus.Labels{
+			"kind":     string(op.Kind),
+			"contract": op.Contract,
+			"method":   op.Method,
+		}
+		p.requests.With(labels).Inc()
+		p.latency.With(labels).Observe(time.Since(start).Seconds())
+		if err != nil {
+			p.errors.With(labels).Inc()
+		}
+		if op.Kind == rocketpool.OperationMulticall {
+			p.batchSize.Observe(float64(op.BatchSize))
+		}
+	}
+}
+
+// Describe the metrics, implementing prometheus.Collector
+func (p *PrometheusInstrumentation) Describe(ch chan<- *prometheus.Desc) {
+	p.requests.Describe(ch)
+	p.errors.Describe(ch)
+	p.latency.Describe(ch)
+	p.batchSize.Describe(ch)
+}
+
+// Collect the metrics, implementing prometheus.Collector
+func (p *PrometheusInstrumentation) Collect(ch chan<- prometheus.Metric) {
+	p.requests.Collect(ch)
+	p.errors.Collect(ch)
+	p.latency.Collect(ch)
+	p.batchSize.Collect(ch)
+}
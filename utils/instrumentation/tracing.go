@@ -0,0 +1,49 @@
+package instrumentation
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Seb369888/poolsea-go/rocketpool"
+)
+
+// Records contract calls and execution client requests as OpenTelemetry spans
+type TracingInstrumentation struct {
+	tracer trace.Tracer
+}
+
+// Create a new tracing instrumentation using the provided tracer
+func NewTracingInstrumentation(tracer trace.Tracer) *TracingInstrumentation {
+	return &TracingInstrumentation{
+		tracer: tracer,
+	}
+}
+
+// Begin an instrumented operation
+func (t *TracingInstrumentation) Begin(ctx context.Context, op rocketpool.Operation) (context.Context, func(err error)) {
+	attributes := []attribute.KeyValue{
+		attribute.String("poolsea.kind", string(op.Kind)),
+		attribute.String("poolsea.method", op.Method),
+	}
+	name := op.Method
+	if op.Contract != "" {
+		name = fmt.Sprintf("%s.%s", op.Contract, op.Method)
+		attributes = append(attributes, attribute.String("poolsea.contract", op.Contract))
+	}
+	if op.Kind == rocketpool.OperationMulticall {
+		attributes = append(attributes, attribute.Int("poolsea.batch_size", op.BatchSize))
+	}
+
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
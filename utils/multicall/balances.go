@@ -64,7 +64,9 @@ func (b *BalanceBatcher) GetEthBalances(addresses []common.Address, opts *bind.C
 				return fmt.Errorf("error creating calldata for balances: %w", err)
 			}
 
-			response, err := b.Client.CallContract(context.Background(), ethereum.CallMsg{To: &b.ContractAddress, Data: callData}, opts.BlockNumber)
+			ctx, finish := rocketpool.BeginOperation(context.Background(), rocketpool.Operation{Kind: rocketpool.OperationMulticall, Contract: "balanceBatcher", Method: "balances", BatchSize: len(subAddresses)})
+			response, err := b.Client.CallContract(ctx, ethereum.CallMsg{To: &b.ContractAddress, Data: callData}, opts.BlockNumber)
+			finish(err)
 			if err != nil {
 				return fmt.Errorf("error calling balances: %w", err)
 			}
@@ -86,7 +86,9 @@ func (caller *MultiCaller) Execute(requireSuccess bool, opts *bind.CallOpts) ([]
 		return nil, err
 	}
 
-	resp, err := caller.Client.CallContract(context.Background(), ethereum.CallMsg{To: &caller.ContractAddress, Data: callData}, opts.BlockNumber)
+	ctx, finish := rocketpool.BeginOperation(context.Background(), rocketpool.Operation{Kind: rocketpool.OperationMulticall, Contract: "multicall", Method: "tryAggregate", BatchSize: len(caller.calls)})
+	resp, err := caller.Client.CallContract(ctx, ethereum.CallMsg{To: &caller.ContractAddress, Data: callData}, opts.BlockNumber)
+	finish(err)
 	if err != nil {
 		return nil, err
 	}
@@ -187,6 +187,7 @@ func NewNetworkContracts(rp *rocketpool.RocketPool, multicallerAddress common.Ad
 			Address:  &wrappers[i].address,
 			ABI:      abi,
 			Client:   rp.Client,
+			Name:     wrapper.name,
 		}
 
 		// Set the contract in the main wrapper object
@@ -43,7 +43,7 @@ type NativeMinipoolDetails struct {
 	PreviousDelegate                  common.Address
 	EffectiveDelegate                 common.Address
 	PenaltyCount                      *big.Int
-	PenaltyRate                       *big.Int
+	PenaltyRate                       *big.Int // The stored penalty rate, which may be above the network's MinipoolMaxPenaltyRate
 	NodeAddress                       common.Address
 	Version                           uint8
 	Balance                           *big.Int // Contract balance
@@ -214,6 +214,53 @@ func CalculateCompleteMinipoolShares(rp *rocketpool.RocketPool, contracts *Netwo
 	return nil
 }
 
+// Calculate the node and user shares of the Beacon balance and of the total balance of each minipool.
+// This produces the same results as CalculateCompleteMinipoolShares, but uses the offline share math instead of calling the minipool contracts.
+// The max penalty rate is the network's MinipoolMaxPenaltyRate.
+func CalculateCompleteMinipoolSharesOffline(minipoolDetails []*NativeMinipoolDetails, beaconBalances []*big.Int, maxPenaltyRate *big.Int) {
+	for i, details := range minipoolDetails {
+		shareDetails := details.GetShareDetails(maxPenaltyRate)
+
+		// Calculate the Beacon shares
+		beaconBalance := big.NewInt(0).Set(beaconBalances[i])
+		if beaconBalance.Cmp(zero) > 0 {
+			details.NodeShareOfBeaconBalance = minipool.CalculateNodeShareOffline(shareDetails, beaconBalance)
+			details.UserShareOfBeaconBalance = minipool.CalculateUserShareOffline(shareDetails, beaconBalance)
+		} else {
+			details.NodeShareOfBeaconBalance = big.NewInt(0)
+			details.UserShareOfBeaconBalance = big.NewInt(0)
+		}
+
+		// Calculate the total balance
+		totalBalance := big.NewInt(0).Set(beaconBalances[i])      // Total balance = beacon balance
+		totalBalance.Add(totalBalance, details.Balance)           // Add contract balance
+		totalBalance.Sub(totalBalance, details.NodeRefundBalance) // Remove node refund
+
+		// Calculate the node and user shares
+		if totalBalance.Cmp(zero) > 0 {
+			details.NodeShareOfBalanceIncludingBeacon = minipool.CalculateNodeShareOffline(shareDetails, totalBalance)
+			details.UserShareOfBalanceIncludingBeacon = minipool.CalculateUserShareOffline(shareDetails, totalBalance)
+		} else {
+			details.NodeShareOfBalanceIncludingBeacon = big.NewInt(0)
+			details.UserShareOfBalanceIncludingBeacon = big.NewInt(0)
+		}
+	}
+}
+
+// Get the details used by the offline share calculations, capping the penalty rate at the network's MinipoolMaxPenaltyRate
+func (details *NativeMinipoolDetails) GetShareDetails(maxPenaltyRate *big.Int) minipool.ShareDetails {
+	return minipool.ShareDetails{
+		Version:            details.Version,
+		Status:             details.Status,
+		DepositType:        details.DepositType,
+		NodeFee:            details.NodeFee,
+		NodeDepositBalance: details.NodeDepositBalance,
+		UserDepositBalance: details.UserDepositBalance,
+		PenaltyRate:        minipool.CapPenaltyRate(details.PenaltyRate, maxPenaltyRate),
+		UserDistributed:    details.UserDistributed,
+	}
+}
+
 // Get all minipool addresses using the multicaller
 func getNodeMinipoolAddressesFast(rp *rocketpool.RocketPool, contracts *NetworkContracts, nodeAddress common.Address, opts *bind.CallOpts) ([]common.Address, error) {
 	// Get minipool count
@@ -11,6 +11,7 @@ import (
 	"github.com/Seb369888/poolsea-go/utils/multicall"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -54,6 +55,7 @@ type NetworkDetails struct {
 	SubmitBalancesEnabled             bool
 	SubmitPricesEnabled               bool
 	MinipoolLaunchTimeout             *big.Int
+	MinipoolMaxPenaltyRate            *big.Int
 
 	// Atlas
 	PromotionScrubPeriod      time.Duration
@@ -123,6 +125,7 @@ func NewNetworkDetails(rp *rocketpool.RocketPool, contracts *NetworkContracts, i
 	contracts.Multicaller.AddCall(contracts.RocketDAOProtocolSettingsNetwork, &details.SubmitBalancesEnabled, "getSubmitBalancesEnabled")
 	contracts.Multicaller.AddCall(contracts.RocketDAOProtocolSettingsNetwork, &details.SubmitPricesEnabled, "getSubmitPricesEnabled")
 	contracts.Multicaller.AddCall(contracts.RocketDAOProtocolSettingsMinipool, &minipoolLaunchTimeout, "getLaunchTimeout")
+	contracts.Multicaller.AddCall(contracts.RocketStorage, &details.MinipoolMaxPenaltyRate, "getUint", crypto.Keccak256Hash([]byte("minipool.penalty.rate.max")))
 
 	if isAtlasDeployed {
 		contracts.Multicaller.AddCall(contracts.RocketDAONodeTrustedSettingsMinipool, &promotionScrubPeriodSeconds, "getPromotionScrubPeriod")